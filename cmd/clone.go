@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone <url> [dir]",
+	Short: "Clone a repository for use with worktrees",
+	Long: `Clone a repository using the "bare clone + worktrees" layout.
+The repository is stored in <dir>/.bare with a fetch refspec for remote-tracking branches,
+and the default branch is checked out in <dir>/<branch>. New worktrees are created next to it.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url := args[0]
+		dir := cloneDirFromURL(url)
+		if len(args) > 1 {
+			dir = args[1]
+		}
+		if dir == "" {
+			return fmt.Errorf("cannot derive a directory name from '%s'. Pass [dir] explicitly", url)
+		}
+
+		fmt.Printf("Cloning '%s' into '%s'...\n", url, dir)
+		// Cloning talks to the remote, so let git ask for credentials
+		result, err := worktree.CloneBare(worktree.WithInteractive(cmd.Context()), url, dir)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Successfully cloned repository into '%s'\n", result.Repository.Root)
+		if result.WorktreePath == "" {
+			fmt.Println("The repository is empty; create the first worktree with 'yosegi new' once it has commits")
+			return nil
+		}
+		fmt.Printf("✅ Created worktree '%s' at '%s'\n", result.Branch, result.WorktreePath)
+		return nil
+	},
+}
+
+// cloneDirFromURL derives the directory name from a repository URL the way
+// 'git clone' does, e.g. "git@github.com:user/repo.git" becomes "repo"
+func cloneDirFromURL(url string) string {
+	name := strings.TrimRight(url, "/")
+	name = strings.TrimSuffix(name, ".git")
+	if idx := strings.LastIndexAny(name, "/:"); idx != -1 {
+		name = name[idx+1:]
+	}
+	if name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
+
+func init() {
+	rootCmd.AddCommand(cloneCmd)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestCloneCommand(t *testing.T) {
+	if cloneCmd.Use != "clone <url> [dir]" {
+		t.Errorf("Expected clone command use to be 'clone <url> [dir]', got '%s'", cloneCmd.Use)
+	}
+
+	if cloneCmd.Short == "" {
+		t.Errorf("Clone command should have short description")
+	}
+
+	if cloneCmd.Long == "" {
+		t.Errorf("Clone command should have long description")
+	}
+
+	if cloneCmd.RunE == nil {
+		t.Errorf("Clone command should have RunE function")
+	}
+}
+
+func TestCloneCommandArgs(t *testing.T) {
+	for _, args := range [][]string{{"url"}, {"url", "dir"}} {
+		if err := cloneCmd.Args(cloneCmd, args); err != nil {
+			t.Errorf("Expected %d args to be accepted, got: %v", len(args), err)
+		}
+	}
+
+	for _, args := range [][]string{{}, {"url", "dir", "extra"}} {
+		if err := cloneCmd.Args(cloneCmd, args); err == nil {
+			t.Errorf("Expected %d args to be rejected", len(args))
+		}
+	}
+}
+
+func TestCloneDirFromURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://github.com/user/repo.git", expected: "repo"},
+		{url: "https://github.com/user/repo/", expected: "repo"},
+		{url: "git@github.com:user/repo.git", expected: "repo"},
+		{url: "host:repo.git", expected: "repo"},
+		{url: "/srv/git/project", expected: "project"},
+		{url: "..", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := cloneDirFromURL(tt.url); got != tt.expected {
+				t.Errorf("cloneDirFromURL(%q) = %q, want %q", tt.url, got, tt.expected)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/yagi2/yosegi/internal/config"
@@ -39,9 +41,19 @@ var configShowCmd = &cobra.Command{
 		fmt.Println("Current Configuration:")
 		fmt.Printf("  Default Worktree Path: %s\n", cfg.DefaultWorktreePath)
 		fmt.Printf("  Auto Create Branch: %t\n", cfg.Git.AutoCreateBranch)
+		fmt.Printf("  Trash On Remove: %t\n", cfg.Git.TrashOnRemove)
+		fmt.Printf("  Command Timeout: %s\n", cfg.Git.CommandTimeout)
 		fmt.Printf("  Show Icons: %t\n", cfg.UI.ShowIcons)
 		fmt.Printf("  Confirm Delete: %t\n", cfg.UI.ConfirmDelete)
 		fmt.Printf("  Max Path Length: %d\n", cfg.UI.MaxPathLength)
+		fmt.Printf("  Sort: %s\n", cfg.UI.Sort)
+		fmt.Printf("  Group By Prefix: %t\n", cfg.UI.GroupByPrefix)
+		if cfg.Editor != "" {
+			fmt.Printf("  Editor: %s\n", cfg.Editor)
+		}
+		if cfg.Multiplexer != "" {
+			fmt.Printf("  Multiplexer: %s\n", cfg.Multiplexer)
+		}
 
 		if len(cfg.Aliases) > 0 {
 			fmt.Println("  Aliases:")
@@ -50,6 +62,18 @@ var configShowCmd = &cobra.Command{
 			}
 		}
 
+		if len(cfg.KeyBindings) > 0 {
+			fmt.Println("  Key Bindings:")
+			actions := make([]string, 0, len(cfg.KeyBindings))
+			for action := range cfg.KeyBindings {
+				actions = append(actions, action)
+			}
+			sort.Strings(actions)
+			for _, action := range actions {
+				fmt.Printf("    %s -> %s\n", action, strings.Join(cfg.KeyBindings[action], ", "))
+			}
+		}
+
 		return nil
 	},
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -426,3 +427,33 @@ func BenchmarkConfigShow(b *testing.B) {
 		}
 	}
 }
+
+func TestConfigShowKeyBindingsSorted(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".config", "yosegi")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := "keybindings:\n  quit: [x]\n  create: [n]\n  delete: [r]\n  move: [v]\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err := configShowCmd.RunE(configShowCmd, nil)
+	_ = w.Close()
+	os.Stdout = originalStdout
+	if err != nil {
+		t.Fatalf("Config show failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	expected := "  Key Bindings:\n    create -> n\n    delete -> r\n    move -> v\n    quit -> x\n"
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("Expected the key bindings in action order, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var (
+	execFilter   string
+	execParallel int
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [--filter <glob>] [--parallel N] -- <command> [args...]",
+	Short: "Run a command in every worktree",
+	Long: `Run a command in the directory of each worktree shown by 'yosegi list'.
+Output lines are prefixed with the worktree's branch, and a pass/fail summary is printed
+at the end. --filter limits the worktrees to those whose branch or directory name matches
+the glob. Run the command through a shell (sh -c '...') to use pipes or variables.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.ArgsLenAtDash() != 0 || len(args) == 0 {
+			return fmt.Errorf("usage: yosegi exec [--filter <glob>] [--parallel N] -- <command> [args...]")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if execParallel < 1 {
+			return fmt.Errorf("--parallel must be at least 1")
+		}
+
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		worktrees, err := manager.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+
+		var selected []worktree.Worktree
+		for _, wt := range listedWorktrees(worktrees) {
+			if execFilter == "" || matchesAny(wt, []string{execFilter}) {
+				selected = append(selected, wt)
+			}
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("no worktrees match")
+		}
+
+		results := runInWorktrees(ctx, selected, args, execParallel, os.Stdout)
+		if err := printExecSummary(os.Stdout, results); err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("command failed in %d of %d worktrees", failed, len(results))
+		}
+		return nil
+	},
+}
+
+// execResult is the outcome of running the command in one worktree
+type execResult struct {
+	Worktree worktree.Worktree
+	ExitCode int
+	Duration time.Duration
+	Err      error
+}
+
+// runInWorktrees runs command in each worktree, at most parallel at a time,
+// and returns the results in the order of worktrees
+func runInWorktrees(ctx context.Context, worktrees []worktree.Worktree, command []string, parallel int, out io.Writer) []execResult {
+	results := make([]execResult, len(worktrees))
+	var mu sync.Mutex // keeps lines from different worktrees whole
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallel)
+
+	for i, wt := range worktrees {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			w := &prefixWriter{out: out, mu: &mu, prefix: "[" + execLabel(wt) + "] "}
+			results[i] = runInWorktree(ctx, wt, command, w)
+			w.Flush()
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// runInWorktree runs command in a single worktree, writing its output to w
+func runInWorktree(ctx context.Context, wt worktree.Worktree, command []string, w io.Writer) execResult {
+	result := execResult{Worktree: wt}
+	if ctx.Err() != nil {
+		result.ExitCode = -1
+		result.Err = ctx.Err()
+		return result
+	}
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Dir = wt.Path
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	start := time.Now()
+	err := cmd.Run()
+	result.Duration = time.Since(start)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+		result.Err = err
+	default:
+		result.ExitCode = -1
+		result.Err = err
+		_, _ = fmt.Fprintf(w, "%v\n", err)
+	}
+	return result
+}
+
+// printExecSummary writes a table with the outcome in each worktree
+func printExecSummary(out io.Writer, results []execResult) error {
+	_, _ = fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "BRANCH\tRESULT\tEXIT\tTIME\tPATH")
+	for _, result := range results {
+		status := "✅ pass"
+		if result.Err != nil {
+			status = "❌ fail"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", execLabel(result.Worktree), status, result.ExitCode, result.Duration.Round(time.Millisecond), result.Worktree.Path)
+	}
+	return w.Flush()
+}
+
+// execLabel names a worktree in output, falling back for detached worktrees
+func execLabel(wt worktree.Worktree) string {
+	if wt.Branch == "" || wt.Branch == "(detached)" {
+		return "(detached " + wt.Path + ")"
+	}
+	return wt.Branch
+}
+
+// prefixWriter writes each complete line to out with a prefix, holding back
+// a trailing partial line until it is completed or flushed
+type prefixWriter struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.writeLine(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush writes a trailing partial line, ending it with a newline
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = io.WriteString(w.out, w.prefix)
+	_, _ = w.out.Write(line)
+}
+
+func init() {
+	execCmd.Flags().StringVarP(&execFilter, "filter", "f", "", "Only run in worktrees whose branch or directory name matches this glob")
+	execCmd.Flags().IntVarP(&execParallel, "parallel", "j", 1, "Number of worktrees to run in at the same time")
+	rootCmd.AddCommand(execCmd)
+}
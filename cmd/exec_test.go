@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+func TestExecCommand(t *testing.T) {
+	if execCmd.Short == "" || execCmd.Long == "" {
+		t.Errorf("Exec command should have descriptions")
+	}
+	if execCmd.Flags().Lookup("filter") == nil || execCmd.Flags().Lookup("parallel") == nil {
+		t.Error("Exec command should have --filter and --parallel flags")
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		valid bool
+	}{
+		{name: "Command after separator", args: []string{"--", "go", "test", "./..."}, valid: true},
+		{name: "Missing separator", args: []string{"make"}},
+		{name: "Missing command", args: []string{"--"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := *execCmd
+			cmd.ResetFlags()
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("Failed to parse arguments: %v", err)
+			}
+
+			err := cmd.Args(&cmd, cmd.Flags().Args())
+			if tt.valid && err != nil {
+				t.Errorf("Expected arguments to be accepted, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Expected arguments to be rejected")
+			}
+		})
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	wt := worktree.Worktree{Path: "/work/repo-feature-login", Branch: "feature/login"}
+
+	tests := []struct {
+		patterns []string
+		expected bool
+	}{
+		{patterns: []string{"feature/*"}, expected: true},
+		{patterns: []string{"*-login"}, expected: true},
+		{patterns: []string{"fix/*", "feature/log*"}, expected: true},
+		{patterns: []string{"feature"}, expected: false},
+		{patterns: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesAny(wt, tt.patterns); got != tt.expected {
+			t.Errorf("matchesAny(%v) = %v, expected %v", tt.patterns, got, tt.expected)
+		}
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &prefixWriter{out: &out, mu: &sync.Mutex{}, prefix: "[main] "}
+
+	_, _ = w.Write([]byte("one\ntw"))
+	_, _ = w.Write([]byte("o\nthree"))
+	w.Flush()
+
+	expected := "[main] one\n[main] two\n[main] three\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestRunInWorktrees(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	worktrees := []worktree.Worktree{
+		{Path: t.TempDir(), Branch: "main"},
+		{Path: t.TempDir(), Branch: "feature"},
+		{Path: t.TempDir()},
+	}
+	command := []string{"sh", "-c", `echo "in $(basename "$PWD")"; [ "$(basename "$PWD")" != "002" ]`}
+
+	var out bytes.Buffer
+	results := runInWorktrees(context.Background(), worktrees, command, 2, &out)
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	for i, result := range results {
+		if result.Worktree.Path != worktrees[i].Path {
+			t.Errorf("Expected results in worktree order, got %s at %d", result.Worktree.Path, i)
+		}
+	}
+	if results[0].Err != nil || results[2].Err != nil {
+		t.Errorf("Expected worktrees 1 and 3 to pass, got %v and %v", results[0].Err, results[2].Err)
+	}
+	if results[1].Err == nil || results[1].ExitCode != 1 {
+		t.Errorf("Expected worktree 2 to fail with exit code 1, got %+v", results[1])
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	sort.Strings(lines)
+	expected := []string{"[(detached " + worktrees[2].Path + ")] in 003", "[feature] in 002", "[main] in 001"}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+
+	var summary bytes.Buffer
+	if err := printExecSummary(&summary, results); err != nil {
+		t.Fatalf("printExecSummary failed: %v", err)
+	}
+	if !strings.Contains(summary.String(), "BRANCH") || strings.Count(summary.String(), "pass") != 2 || strings.Count(summary.String(), "fail") != 1 {
+		t.Errorf("Unexpected summary:\n%s", summary.String())
+	}
+}
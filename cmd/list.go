@@ -1,50 +1,79 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"github.com/yagi2/yosegi/internal/config"
-	"github.com/yagi2/yosegi/internal/git"
+	"github.com/yagi2/yosegi/internal/history"
 	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 var (
-	printMode bool
+	printMode    bool
+	previousMode bool
 )
 
 var listCmd = &cobra.Command{
-	Use:     "list",
-	Short:   "List all git worktrees",
-	Long:    "Display an interactive list of all git worktrees in the repository.",
+	Use:   "list",
+	Short: "List all git worktrees",
+	Long: `Display an interactive list of all git worktrees in the repository.
+
+Worktrees chosen here or with --print are remembered in $XDG_STATE_HOME/yosegi/history.json
+(~/.local/state/yosegi/history.json by default) to rank them by frecency, and
+'list --print --previous' prints the previously chosen worktree without asking.`,
 	Aliases: []string{"ls", "l"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		manager, err := git.NewManager()
+		if previousMode && !printMode {
+			return fmt.Errorf("--previous can only be used with --print")
+		}
+
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to initialize git manager: %w", err)
 		}
 
-		worktrees, err := manager.List()
+		worktrees, err := manager.List(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to list worktrees: %w", err)
 		}
 
+		worktrees = listedWorktrees(worktrees)
+
 		// Check if --print flag is used
 		if printMode {
 			if len(worktrees) == 0 {
 				return fmt.Errorf("no worktrees found")
 			}
 
+			if previousMode {
+				previous, err := previousWorktree(manager, worktrees)
+				if err != nil {
+					return err
+				}
+				recordChoice(manager, *previous)
+				fmt.Println(previous.Path)
+				return nil
+			}
+
 			// Use smart selector that adapts to TTY capabilities
+			worktrees = ui.SortWorktrees(worktrees, worktreeSorting(ctx, manager, worktrees))
 			selectedWorktree, err := ui.SmartSelectWorktree(worktrees)
 			if err != nil {
 				return err
 			}
 
 			// Print the selected worktree path to stdout
+			recordChoice(manager, *selectedWorktree)
 			fmt.Println(selectedWorktree.Path)
 			return nil
 		}
@@ -68,173 +97,306 @@ var listCmd = &cobra.Command{
 			return fmt.Errorf("no suitable worktree found")
 		}
 
-		// Interactive mode
-		model := ui.NewSelector(worktrees, "Git Worktrees", "print path", true)
-		program := tea.NewProgram(model)
+		// Interactive mode: the list stays open while worktrees are created,
+		// removed and moved from it, and ends when one is selected
+		model := ui.NewSelector(worktrees, "Git Worktrees", "print path", true).
+			WithCreate().
+			WithMove().
+			WithPreview(worktreePreview(ctx, manager)).
+			WithSorting(worktreeSorting(ctx, manager, worktrees)).
+			WithActions(worktreeActions(ctx, manager)).
+			WithReload(reloadWorktrees(ctx, manager)).
+			WithSessions(worktreeSessions(ctx))
+		app := ui.NewApp(ctx, model, listHandlers(manager))
+		program := tea.NewProgram(app)
 
 		finalModel, err := program.Run()
+		if final, ok := finalModel.(ui.AppModel); ok {
+			// An operation interrupted by ctrl+c is cancelled and waited for
+			final.Close()
+		}
 		if err != nil {
 			return fmt.Errorf("failed to run interactive interface: %w", err)
 		}
 
-		result := finalModel.(ui.SelectorModel).GetResult()
-		switch result.Action {
-		case "select":
+		result := finalModel.(ui.AppModel).GetResult()
+		if result.Action == "select" {
 			// Print the selected worktree path to stdout
+			recordChoice(manager, result.Worktree)
 			fmt.Println(result.Worktree.Path)
-
-		case "create":
-			// Call existing new command
-			return newCmd.RunE(cmd, []string{})
-
-		case "delete":
-			// Call existing remove command with pre-selected worktree
-			return runRemoveWithSelectedWorktree(result.Worktree)
-
-		case "quit":
-			// Do nothing, just exit
 		}
 
 		return nil
 	},
 }
 
-// runRemoveWithSelectedWorktree runs remove command with a pre-selected worktree
-func runRemoveWithSelectedWorktree(selectedWorktree git.Worktree) error {
-	if selectedWorktree.IsCurrent {
-		return fmt.Errorf("cannot remove current worktree")
+// listedWorktrees returns the worktrees that list shows: the bare repository
+// is dropped, since it is not something to switch to, and so are worktrees
+// matching git.exclude_patterns. The current worktree is always kept.
+func listedWorktrees(worktrees []worktree.Worktree) []worktree.Worktree {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.Config{}
 	}
 
-	manager, err := git.NewManager()
+	var listed []worktree.Worktree
+	for _, wt := range worktree.WithoutBare(worktrees) {
+		if !wt.IsCurrent && matchesAny(wt, cfg.Git.ExcludePatterns) {
+			continue
+		}
+		listed = append(listed, wt)
+	}
+	return listed
+}
+
+// worktreePreview returns the loader for the selector's preview pane. Changes
+// are shown against the remote's default branch, or the main worktree's branch
+// when the remote has none.
+func worktreePreview(ctx context.Context, manager worktree.Manager) ui.PreviewFunc {
+	base, err := manager.DefaultBranch(ctx, defaultRemote())
 	if err != nil {
-		return fmt.Errorf("failed to initialize git manager: %w", err)
+		base = ""
+		if worktrees, err := manager.List(ctx); err == nil {
+			for _, wt := range worktrees {
+				if wt.Path == manager.MainWorktreePath() && !wt.Bare && wt.Branch != "(detached)" {
+					base = wt.Branch
+				}
+			}
+		}
 	}
+	return ui.GitPreview(ctx, manager, base)
+}
 
-	// Confirm worktree removal
-	if !confirmWorktreeRemoval(selectedWorktree.Path) {
-		fmt.Println("Removal cancelled")
-		return nil
+// worktreeActions returns what the selector's actions menu runs with
+func worktreeActions(ctx context.Context, manager worktree.Manager) ui.ActionOptions {
+	return ui.ActionOptions{Context: ctx, Manager: manager, Remote: defaultRemote(), Editor: editorSetting()}
+}
+
+// reloadWorktrees returns how the list is refreshed after an operation: the
+// worktrees as list shows them
+func reloadWorktrees(ctx context.Context, manager worktree.Manager) func() ([]worktree.Worktree, error) {
+	return func() ([]worktree.Worktree, error) {
+		worktrees, err := manager.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return listedWorktrees(worktrees), nil
 	}
+}
 
-	// Remove the worktree
-	if err := removeWorktree(manager, selectedWorktree.Path); err != nil {
-		return err
+// listHandlers runs the list's create, delete and move keys inside the app,
+// with the same steps as the new, remove and move commands
+func listHandlers(manager worktree.Manager) ui.AppHandlers {
+	return ui.AppHandlers{
+		Create: func(ctx context.Context, s ui.Session) error {
+			cfg, err := config.Load()
+			if err != nil {
+				cfg = &config.Config{}
+			}
+			_, err = runNew(ctx, s, manager, cfg, "", "")
+			return err
+		},
+		Remove: func(ctx context.Context, s ui.Session, wt worktree.Worktree) error {
+			return runRemoveWithSelectedWorktree(ctx, s, manager, wt)
+		},
+		Move: func(ctx context.Context, s ui.Session, wt worktree.Worktree) error {
+			return runMoveWithSelectedWorktree(ctx, s, manager, wt, "")
+		},
 	}
+}
 
-	// Handle branch deletion if applicable
-	return handleBranchDeletion(manager, selectedWorktree.Branch)
+// defaultRemote returns git.default_remote, or origin when it is not set
+func defaultRemote() string {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+	if cfg.Git.DefaultRemote == "" {
+		return "origin"
+	}
+	return cfg.Git.DefaultRemote
 }
 
-// confirmWorktreeRemoval shows confirmation dialog for worktree removal
-func confirmWorktreeRemoval(path string) bool {
-	confirmModel := ui.NewConfirm(
-		"Confirm Removal",
-		fmt.Sprintf("Remove worktree at %s?", path),
-	)
-	program := tea.NewProgram(confirmModel)
+// worktreeSorting returns the list order from ui.sort and ui.group_by_prefix,
+// with the commit dates and frecency scores the orders need. An unknown order
+// is reported and git's order used instead.
+func worktreeSorting(ctx context.Context, manager worktree.Manager, worktrees []worktree.Worktree) ui.SortOptions {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.Config{}
+	}
 
-	finalModel, err := program.Run()
+	order, err := ui.ParseSortOrder(cfg.UI.Sort)
 	if err != nil {
-		return false
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: ui.sort: %v\n", err)
 	}
 
-	result := finalModel.(ui.ConfirmModel).GetResult()
-	return !result.Cancelled && result.Confirmed
-}
+	commits := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		commits[i] = wt.Commit
+	}
+	// Without dates, sorting by last commit keeps git's order
+	times, _ := manager.CommitTimes(ctx, commits)
 
-// removeWorktree removes the specified worktree
-func removeWorktree(manager git.Manager, path string) error {
-	fmt.Printf("Removing worktree at '%s'...\n", path)
-	if err := manager.Remove(path, false); err != nil {
-		return fmt.Errorf("failed to remove worktree: %w", err)
+	// Without a history, sorting by frecency keeps git's order
+	var scores map[string]float64
+	if h, err := loadHistory(); err == nil {
+		scores = h.Scores(time.Now())
 	}
-	fmt.Printf("✅ Successfully removed worktree at '%s'\n", path)
-	return nil
+
+	return ui.SortOptions{Order: order, Group: cfg.UI.GroupByPrefix, CommitTimes: times, Frecency: scores}
 }
 
-// handleBranchDeletion handles the branch deletion logic
-func handleBranchDeletion(manager git.Manager, branch string) error {
-	// Skip branch deletion for special branches
-	if branch == "(detached)" || branch == "(bare)" {
-		return nil
+// loadHistory reads the record of chosen worktrees from the state directory
+func loadHistory() (*history.History, error) {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return nil, err
 	}
+	return history.Load(path)
+}
 
-	cfg, err := config.Load()
+// recordChoice remembers that wt was chosen. The history only affects
+// ordering, so failing to update it is reported but not fatal.
+func recordChoice(manager worktree.Manager, wt worktree.Worktree) {
+	h, err := loadHistory()
+	if err == nil {
+		h.Record(manager.CommonDir(), wt.Path, time.Now())
+		err = h.Save()
+	}
 	if err != nil {
-		cfg = &config.Config{}
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to record worktree history: %v\n", err)
 	}
+}
 
-	deleteBranch, err := shouldDeleteBranch(manager, branch, cfg.Git.DeleteBranchOnWorktreeRemove)
+// previousWorktree returns the most recently chosen of worktrees other than
+// the current one
+func previousWorktree(manager worktree.Manager, worktrees []worktree.Worktree) (*worktree.Worktree, error) {
+	h, err := loadHistory()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if deleteBranch {
-		return deleteBranchWithConfirmation(manager, branch)
+	for _, entry := range h.Recent(manager.CommonDir()) {
+		for _, wt := range worktrees {
+			if wt.Path == entry.Path && !wt.IsCurrent {
+				return &wt, nil
+			}
+		}
 	}
-	return nil
+	return nil, fmt.Errorf("no previously chosen worktree")
 }
 
-// shouldDeleteBranch determines if the branch should be deleted
-func shouldDeleteBranch(manager git.Manager, branch string, autoDelete bool) (bool, error) {
-	hasUnpushed, unpushedCount, err := manager.HasUnpushedCommits(branch)
-	if err == nil && hasUnpushed {
-		return confirmUnpushedBranchDeletion(branch, unpushedCount), nil
+// matchesAny reports whether a glob pattern matches the worktree's branch or
+// directory name
+func matchesAny(wt worktree.Worktree, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, wt.Branch); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, filepath.Base(wt.Path)); ok {
+			return true
+		}
 	}
+	return false
+}
 
-	if !autoDelete {
-		return confirmBranchDeletion(branch), nil
+// runRemoveWithSelectedWorktree removes a worktree chosen in the list, asking
+// first, and then offers to delete its branch
+func runRemoveWithSelectedWorktree(ctx context.Context, s ui.Session, manager worktree.Manager, selectedWorktree worktree.Worktree) error {
+	if selectedWorktree.IsCurrent {
+		return fmt.Errorf("cannot remove current worktree")
+	}
+
+	if selectedWorktree.Bare {
+		return fmt.Errorf("cannot remove the bare repository")
+	}
+
+	if err := ensureUnlocked(selectedWorktree); err != nil {
+		return err
 	}
 
-	return autoDelete, nil
+	// Confirm worktree removal
+	if !s.Confirm("Confirm Removal", fmt.Sprintf("Remove worktree at %s?", selectedWorktree.Path)) {
+		s.Report("Removal cancelled")
+		return nil
+	}
+
+	// Snapshot the worktree so the removal can be undone
+	var snapshot *worktree.TrashEntry
+	if shouldTrashOnRemove() {
+		var err error
+		if snapshot, err = trashWorktree(ctx, s, manager, selectedWorktree); err != nil {
+			return err
+		}
+	}
+
+	// Remove the worktree
+	if err := removeWorktree(ctx, s, manager, selectedWorktree.Path); err != nil {
+		dropSnapshot(ctx, s, manager, snapshot)
+		return err
+	}
+	killSession(ctx, s, selectedWorktree)
+
+	// Handle branch deletion if applicable
+	return handleBranchDeletion(ctx, s, manager, selectedWorktree.Branch)
 }
 
-// confirmUnpushedBranchDeletion shows warning for unpushed commits
-func confirmUnpushedBranchDeletion(branch string, unpushedCount int) bool {
-	warningModel := ui.NewConfirm(
-		"Branch Deletion Warning",
-		fmt.Sprintf("Branch '%s' has %d unpushed commits. Delete branch anyway?", branch, unpushedCount),
-	)
-	program := tea.NewProgram(warningModel)
+// removeWorktree removes the specified worktree
+func removeWorktree(ctx context.Context, s ui.Session, manager worktree.Manager, path string) error {
+	s.Report(fmt.Sprintf("Removing worktree at '%s'...", path))
+	if err := removeWithRecovery(ctx, s, manager, path, false); err != nil {
+		return fmt.Errorf("failed to remove worktree: %w", err)
+	}
+	s.Report(fmt.Sprintf("✅ Successfully removed worktree at '%s'", path))
+	return nil
+}
 
-	finalModel, err := program.Run()
+// handleBranchDeletion handles the branch deletion logic
+func handleBranchDeletion(ctx context.Context, s ui.Session, manager worktree.Manager, branch string) error {
+	// Skip branch deletion for special branches
+	if branch == "(detached)" || branch == "(bare)" {
+		return nil
+	}
+
+	cfg, err := config.Load()
 	if err != nil {
-		return false
+		cfg = &config.Config{}
 	}
 
-	result := finalModel.(ui.ConfirmModel).GetResult()
-	return !result.Cancelled && result.Confirmed
+	if shouldDeleteBranch(ctx, s, manager, branch, cfg.Git.DeleteBranchOnWorktreeRemove) {
+		deleteBranchWithConfirmation(ctx, s, manager, branch)
+	}
+	return nil
 }
 
-// confirmBranchDeletion asks user if they want to delete the branch
-func confirmBranchDeletion(branch string) bool {
-	confirmModel := ui.NewConfirm(
-		"Delete Branch",
-		fmt.Sprintf("Also delete the local branch '%s'?", branch),
-	)
-	program := tea.NewProgram(confirmModel)
+// shouldDeleteBranch determines if the branch should be deleted, warning
+// about unpushed commits and asking unless configured to delete it
+func shouldDeleteBranch(ctx context.Context, s ui.Session, manager worktree.Manager, branch string, autoDelete bool) bool {
+	hasUnpushed, unpushedCount, err := manager.HasUnpushedCommits(ctx, branch)
+	if err == nil && hasUnpushed {
+		return s.Confirm("Branch Deletion Warning", fmt.Sprintf("Branch '%s' has %d unpushed commits. Delete branch anyway?", branch, unpushedCount))
+	}
 
-	finalModel, err := program.Run()
-	if err != nil {
-		return false
+	if !autoDelete {
+		return s.Confirm("Delete Branch", fmt.Sprintf("Also delete the local branch '%s'?", branch))
 	}
 
-	result := finalModel.(ui.ConfirmModel).GetResult()
-	return !result.Cancelled && result.Confirmed
+	return autoDelete
 }
 
-// deleteBranchWithConfirmation deletes the branch and shows result
-func deleteBranchWithConfirmation(manager git.Manager, branch string) error {
-	fmt.Printf("Deleting branch '%s'...\n", branch)
-	hasUnpushed, _, _ := manager.HasUnpushedCommits(branch)
+// deleteBranchWithConfirmation deletes the branch and reports the result.
+// Failing to delete it does not fail the removal.
+func deleteBranchWithConfirmation(ctx context.Context, s ui.Session, manager worktree.Manager, branch string) {
+	s.Report(fmt.Sprintf("Deleting branch '%s'...", branch))
+	hasUnpushed, _, _ := manager.HasUnpushedCommits(ctx, branch)
 
-	if err := manager.DeleteBranch(branch, hasUnpushed); err != nil {
-		fmt.Printf("⚠️  Warning: Failed to delete branch: %v\n", err)
-		return nil // Don't fail the whole operation
+	if err := deleteBranchWithRecovery(ctx, s, manager, branch, hasUnpushed); err != nil {
+		s.Report(fmt.Sprintf("⚠️  Warning: Failed to delete branch: %v", err))
+		return
 	}
 
-	fmt.Printf("✅ Successfully deleted branch '%s'\n", branch)
-	return nil
+	s.Report(fmt.Sprintf("✅ Successfully deleted branch '%s'", branch))
 }
 
 func init() {
@@ -242,4 +404,5 @@ func init() {
 
 	// Add flags
 	listCmd.Flags().BoolVarP(&printMode, "print", "p", false, "Show interactive selector on stderr and print selected path to stdout (for use in scripts)")
+	listCmd.Flags().BoolVar(&previousMode, "previous", false, "With --print, print the previously chosen worktree without showing the selector")
 }
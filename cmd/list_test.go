@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
-	"github.com/yagi2/yosegi/internal/git"
+	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
 )
 
 func TestListCommand(t *testing.T) {
@@ -66,7 +69,7 @@ func TestListCommandStructure(t *testing.T) {
 	}
 
 	expectedLong := "Display an interactive list of all git worktrees in the repository."
-	if listCmd.Long != expectedLong {
+	if !strings.HasPrefix(listCmd.Long, expectedLong) {
 		t.Errorf("Expected long description '%s', got '%s'", expectedLong, listCmd.Long)
 	}
 }
@@ -398,13 +401,13 @@ func TestRunRemoveWithSelectedWorktree(t *testing.T) {
 
 	// Test only the basic validation logic
 	t.Run("Cannot remove current worktree", func(t *testing.T) {
-		worktree := git.Worktree{
+		wt := worktree.Worktree{
 			Path:      "/current/path",
 			Branch:    "main",
 			IsCurrent: true,
 		}
 
-		err := runRemoveWithSelectedWorktree(worktree)
+		err := runRemoveWithSelectedWorktree(context.Background(), terminal, nil, wt)
 		if err == nil {
 			t.Error("Expected error for current worktree removal")
 		}
@@ -417,13 +420,13 @@ func TestRunRemoveWithSelectedWorktree(t *testing.T) {
 
 func TestRunRemoveWithSelectedWorktreeCurrentCheck(t *testing.T) {
 	// Test specifically for current worktree check
-	currentWorktree := git.Worktree{
+	currentWorktree := worktree.Worktree{
 		Path:      "/current",
 		Branch:    "main",
 		IsCurrent: true,
 	}
 
-	err := runRemoveWithSelectedWorktree(currentWorktree)
+	err := runRemoveWithSelectedWorktree(context.Background(), terminal, nil, currentWorktree)
 
 	if err == nil {
 		t.Error("Expected error when trying to remove current worktree")
@@ -435,3 +438,115 @@ func TestRunRemoveWithSelectedWorktreeCurrentCheck(t *testing.T) {
 		t.Errorf("Expected error message '%s', got '%s'", expectedMsg, err.Error())
 	}
 }
+
+func TestWorktreeSorting(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("show").Return("aaa 1700000000\nbbb 1600000000\n")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+	worktrees := []worktree.Worktree{
+		{Path: "/repo", Branch: "main", Commit: "aaa"},
+		{Path: "/repo/feature", Branch: "feature", Commit: "bbb"},
+	}
+
+	opts := worktreeSorting(context.Background(), manager, worktrees)
+	if !runner.Called("show", "--no-patch", "--format=%H %ct", "--end-of-options", "aaa", "bbb") {
+		t.Errorf("Expected the commit dates to be read in one call, got %+v", runner.Calls())
+	}
+	if opts.CommitTimes["aaa"].Unix() != 1700000000 || opts.CommitTimes["bbb"].Unix() != 1600000000 {
+		t.Errorf("Unexpected commit dates: %v", opts.CommitTimes)
+	}
+
+	// Without dates, the options still apply and commit order keeps git's order
+	failing := worktreetest.NewRunner()
+	failing.On("show").Fail("fatal: bad object")
+	opts = worktreeSorting(context.Background(), worktree.NewManagerWithRunner("/repo", "/repo/.git", failing), worktrees)
+	opts.Order = ui.SortCommit
+	if sorted := ui.SortWorktrees(worktrees, opts); sorted[0].Branch != "main" {
+		t.Errorf("Expected git's order without commit dates, got %+v", sorted)
+	}
+}
+
+func TestRecordChoiceAndPreviousWorktree(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", worktreetest.NewRunner())
+	worktrees := []worktree.Worktree{
+		{Path: "/repo", Branch: "main"},
+		{Path: "/repo/feature", Branch: "feature", IsCurrent: true},
+		{Path: "/repo/fix", Branch: "fix"},
+	}
+
+	if _, err := previousWorktree(manager, worktrees); err == nil {
+		t.Error("Expected no previous worktree without a history")
+	}
+
+	recordChoice(manager, worktrees[2])
+	recordChoice(manager, worktrees[0])
+	recordChoice(manager, worktrees[1])
+
+	// The current worktree is skipped, so this returns to the one before it
+	previous, err := previousWorktree(manager, worktrees)
+	if err != nil || previous.Path != "/repo" {
+		t.Errorf("Expected /repo, got %+v, %v", previous, err)
+	}
+
+	// Worktrees that no longer exist are skipped too
+	previous, err = previousWorktree(manager, worktrees[1:])
+	if err != nil || previous.Path != "/repo/fix" {
+		t.Errorf("Expected /repo/fix, got %+v, %v", previous, err)
+	}
+
+	// Worktrees chosen more often rank first
+	recordChoice(manager, worktrees[2])
+	opts := worktreeSorting(context.Background(), manager, worktrees)
+	opts.Order = ui.SortFrecency
+	if sorted := ui.SortWorktrees(worktrees, opts); sorted[0].Path != "/repo/fix" {
+		t.Errorf("Expected /repo/fix ranked first, got %v", opts.Frecency)
+	}
+}
+
+func TestListPreviousRequiresPrint(t *testing.T) {
+	previousMode, printMode = true, false
+	defer func() { previousMode = false }()
+
+	if err := listCmd.RunE(listCmd, nil); err == nil || !strings.Contains(err.Error(), "--print") {
+		t.Errorf("Expected --previous without --print to fail, got %v", err)
+	}
+}
+
+func TestWorktreeActionsAndReload(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "list").Return("worktree /repo\nbare\n\nworktree /repo/feature\nHEAD aaa\nbranch refs/heads/feature\n\n")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+	opts := worktreeActions(context.Background(), manager)
+	if opts.Remote != "origin" || opts.Manager != manager {
+		t.Errorf("Expected the manager and origin, got %+v", opts)
+	}
+
+	// Reloading lists the worktrees as list shows them, without the bare entry
+	worktrees, err := reloadWorktrees(context.Background(), manager)()
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if len(worktrees) != 1 || worktrees[0].Path != "/repo/feature" {
+		t.Errorf("Expected only the feature worktree, got %+v", worktrees)
+	}
+}
+
+func TestListHandlers(t *testing.T) {
+	handlers := listHandlers(nil)
+	if handlers.Create == nil || handlers.Remove == nil || handlers.Move == nil {
+		t.Fatalf("Expected every list operation to be handled, got %+v", handlers)
+	}
+
+	// The handlers run the same checks as the commands
+	err := handlers.Remove(context.Background(), terminal, worktree.Worktree{Path: "/repo", Branch: "main", IsCurrent: true})
+	if err == nil || !strings.Contains(err.Error(), "cannot remove current worktree") {
+		t.Errorf("Expected removing the current worktree to be refused, got %v", err)
+	}
+	err = handlers.Move(context.Background(), terminal, worktree.Worktree{Path: "/repo", Branch: "main", IsCurrent: true})
+	if err == nil || !strings.Contains(err.Error(), "cannot move current worktree") {
+		t.Errorf("Expected moving the current worktree to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var (
+	lockReason string
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock [worktree]",
+	Short: "Lock a git worktree",
+	Long:  "Lock a git worktree so it cannot be moved, removed, or pruned until it is unlocked.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		selected, err := selectLockTarget(ctx, manager, args, false)
+		if err != nil || selected == nil {
+			return err
+		}
+
+		if err := manager.Lock(ctx, selected.Path, lockReason); err != nil {
+			return fmt.Errorf("failed to lock worktree: %w", err)
+		}
+
+		fmt.Printf("🔒 Locked worktree at '%s'\n", selected.Path)
+		return nil
+	},
+}
+
+var unlockCmd = &cobra.Command{
+	Use:   "unlock [worktree]",
+	Short: "Unlock a git worktree",
+	Long:  "Unlock a previously locked git worktree.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		selected, err := selectLockTarget(ctx, manager, args, true)
+		if err != nil || selected == nil {
+			return err
+		}
+
+		if err := manager.Unlock(ctx, selected.Path); err != nil {
+			return fmt.Errorf("failed to unlock worktree: %w", err)
+		}
+
+		fmt.Printf("✅ Unlocked worktree at '%s'\n", selected.Path)
+		return nil
+	},
+}
+
+// selectLockTarget resolves the worktree to lock or unlock from args or interactively.
+// It returns nil without an error when the selection is cancelled.
+func selectLockTarget(ctx context.Context, manager worktree.Manager, args []string, locked bool) (*worktree.Worktree, error) {
+	worktrees, err := manager.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	candidates := filterLockCandidates(worktrees, manager.MainWorktreePath(), locked)
+	if len(args) > 0 {
+		return findWorktreeByPath(candidates, args[0])
+	}
+
+	if len(candidates) == 0 {
+		if locked {
+			fmt.Println("No locked worktrees found")
+		} else {
+			fmt.Println("No lockable worktrees found (the main worktree cannot be locked)")
+		}
+		return nil, nil
+	}
+
+	title := "Lock Worktree"
+	action := "lock"
+	if locked {
+		title = "Unlock Worktree"
+		action = "unlock"
+	}
+
+	model := ui.NewSelector(candidates, title, action, false)
+	program := tea.NewProgram(model)
+
+	finalModel, err := program.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run interactive interface: %w", err)
+	}
+
+	result := finalModel.(ui.SelectorModel).GetResult()
+	if result.Action != "select" {
+		return nil, nil
+	}
+	return &result.Worktree, nil
+}
+
+// ensureUnlocked returns an error for locked worktrees, which must be unlocked before removal
+func ensureUnlocked(wt worktree.Worktree) error {
+	if !wt.Locked {
+		return nil
+	}
+	if wt.LockReason != "" {
+		return fmt.Errorf("worktree at '%s' is locked (%s). Run 'yosegi unlock' first", wt.Path, wt.LockReason)
+	}
+	return fmt.Errorf("worktree at '%s' is locked. Run 'yosegi unlock' first", wt.Path)
+}
+
+// filterLockCandidates returns linked worktrees whose lock state matches
+// locked, leaving out the main worktree at mainPath, which git cannot lock
+func filterLockCandidates(worktrees []worktree.Worktree, mainPath string, locked bool) []worktree.Worktree {
+	var candidates []worktree.Worktree
+	for _, wt := range worktrees {
+		if wt.Path == mainPath || wt.Bare {
+			continue
+		}
+		if wt.Locked == locked {
+			candidates = append(candidates, wt)
+		}
+	}
+	return candidates
+}
+
+func init() {
+	lockCmd.Flags().StringVarP(&lockReason, "reason", "r", "", "Reason for locking the worktree")
+
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(unlockCmd)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+func TestLockCommands(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		use  string
+	}{
+		{name: "lock", use: lockCmd.Use},
+		{name: "unlock", use: unlockCmd.Use},
+	} {
+		expected := tc.name + " [worktree]"
+		if tc.use != expected {
+			t.Errorf("Expected %s command use to be '%s', got '%s'", tc.name, expected, tc.use)
+		}
+	}
+
+	if lockCmd.Short == "" || unlockCmd.Short == "" {
+		t.Errorf("Lock commands should have short descriptions")
+	}
+
+	if lockCmd.RunE == nil || unlockCmd.RunE == nil {
+		t.Errorf("Lock commands should have RunE functions")
+	}
+}
+
+func TestLockCommandsRegistered(t *testing.T) {
+	names := map[string]bool{}
+	for _, cmd := range rootCmd.Commands() {
+		names[cmd.Name()] = true
+	}
+
+	for _, expected := range []string{"lock", "unlock"} {
+		if !names[expected] {
+			t.Errorf("Expected '%s' command to be registered", expected)
+		}
+	}
+}
+
+func TestLockCommandReasonFlag(t *testing.T) {
+	reasonFlag := lockCmd.Flags().Lookup("reason")
+	if reasonFlag == nil {
+		t.Fatal("reason flag should exist")
+	}
+	if reasonFlag.Shorthand != "r" {
+		t.Errorf("Expected reason flag shorthand to be 'r', got '%s'", reasonFlag.Shorthand)
+	}
+	if reasonFlag.DefValue != "" {
+		t.Errorf("Expected reason flag default to be empty, got '%s'", reasonFlag.DefValue)
+	}
+}
+
+func TestFilterLockCandidates(t *testing.T) {
+	worktrees := []worktree.Worktree{
+		{Path: "/repo", Branch: "main"},
+		{Path: "/repo-a", Branch: "a"},
+		{Path: "/repo-b", Branch: "b", Locked: true},
+	}
+
+	unlocked := filterLockCandidates(worktrees, "/repo", false)
+	if len(unlocked) != 1 || unlocked[0].Branch != "a" {
+		t.Errorf("Expected only 'a' to be lockable, got %+v", unlocked)
+	}
+
+	locked := filterLockCandidates(worktrees, "/repo", true)
+	if len(locked) != 1 || locked[0].Branch != "b" {
+		t.Errorf("Expected only 'b' to be unlockable, got %+v", locked)
+	}
+}
+
+func TestFilterLockCandidatesBareLayout(t *testing.T) {
+	// Without the bare entry, the first worktree is a linked one
+	worktrees := worktree.WithoutBare([]worktree.Worktree{
+		{Path: "/repo/.bare", Branch: "(bare)", Bare: true},
+		{Path: "/repo/main", Branch: "main"},
+		{Path: "/repo/feature", Branch: "feature"},
+	})
+
+	if unlocked := filterLockCandidates(worktrees, "/repo/.bare", false); len(unlocked) != 2 {
+		t.Errorf("Expected both linked worktrees to be lockable, got %+v", unlocked)
+	}
+}
+
+func TestEnsureUnlocked(t *testing.T) {
+	if err := ensureUnlocked(worktree.Worktree{Path: "/repo-a"}); err != nil {
+		t.Errorf("Expected unlocked worktree to pass, got: %v", err)
+	}
+
+	err := ensureUnlocked(worktree.Worktree{Path: "/repo-b", Locked: true, LockReason: "agent running"})
+	if err == nil || !strings.Contains(err.Error(), "agent running") {
+		t.Errorf("Expected lock reason in error, got: %v", err)
+	}
+
+	err = runRemoveWithSelectedWorktree(context.Background(), terminal, nil, worktree.Worktree{Path: "/repo-c", Locked: true})
+	if err == nil || !strings.Contains(err.Error(), "is locked") {
+		t.Errorf("Expected locked worktree removal to be refused, got: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var (
+	pruneDryRun  bool
+	repairDryRun bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Clean up stale worktree entries",
+	Long: `Remove administrative data for worktrees whose directories no longer exist.
+Use --dry-run to only show which entries are stale and why. Locked worktrees are never pruned.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		entries, err := manager.Prune(ctx, pruneDryRun)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No stale worktrees found")
+		} else if pruneDryRun {
+			fmt.Println("Stale worktree entries:")
+			for _, entry := range entries {
+				fmt.Printf("  %s: %s\n", entry.Name, entry.Reason)
+			}
+		} else {
+			for _, entry := range entries {
+				fmt.Printf("Pruned %s: %s\n", entry.Name, entry.Reason)
+			}
+			fmt.Printf("✅ Pruned %d stale worktree(s)\n", len(entries))
+		}
+
+		// Locked worktrees are skipped by git, so point them out explicitly
+		problems, err := manager.Diagnose(ctx)
+		if err != nil {
+			return nil // The prune itself succeeded
+		}
+		for _, problem := range problems {
+			if problem.Worktree.Locked {
+				fmt.Printf("⚠️  Skipped locked worktree at '%s': %s\n", problem.Worktree.Path, problem.Problem)
+			}
+		}
+		return nil
+	},
+}
+
+var repairCmd = &cobra.Command{
+	Use:   "repair [path...]",
+	Short: "Repair worktree administrative files",
+	Long: `Report worktrees whose directories are missing or moved, or whose .git file points to a
+non-existent gitdir, and fix them with 'git worktree repair'.
+Pass the new locations of worktrees that were moved by hand as arguments.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		problems, err := manager.Diagnose(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to inspect worktrees: %w", err)
+		}
+
+		if len(problems) == 0 {
+			fmt.Println("No broken worktrees found")
+		} else {
+			fmt.Println("Broken worktrees:")
+			for _, problem := range problems {
+				fmt.Printf("  %s (%s): %s\n", problem.Worktree.Path, problem.Worktree.Branch, problem.Problem)
+			}
+		}
+
+		if repairDryRun {
+			return nil
+		}
+		if len(problems) == 0 && len(args) == 0 {
+			return nil
+		}
+
+		entries, err := manager.Repair(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, entry := range entries {
+			if entry.Failed {
+				failed++
+				fmt.Printf("❌ %s: %s\n", entry.Path, entry.Reason)
+			} else {
+				fmt.Printf("✅ Repaired %s: %s\n", entry.Path, entry.Reason)
+			}
+		}
+
+		if len(entries) == 0 && len(problems) > 0 {
+			fmt.Println("Nothing could be repaired automatically. Pass the new path of moved worktrees, or run 'yosegi prune' for deleted ones")
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d worktree(s) could not be repaired", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	pruneCmd.Flags().BoolVarP(&pruneDryRun, "dry-run", "n", false, "Only report stale worktrees without removing them")
+	repairCmd.Flags().BoolVarP(&repairDryRun, "dry-run", "n", false, "Only report broken worktrees without repairing them")
+
+	rootCmd.AddCommand(pruneCmd)
+	rootCmd.AddCommand(repairCmd)
+}
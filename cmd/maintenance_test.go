@@ -0,0 +1,64 @@
+package cmd
+
+import "testing"
+
+func TestMaintenanceCommands(t *testing.T) {
+	if pruneCmd.Use != "prune" {
+		t.Errorf("Expected prune command use to be 'prune', got '%s'", pruneCmd.Use)
+	}
+
+	if repairCmd.Use != "repair [path...]" {
+		t.Errorf("Expected repair command use to be 'repair [path...]', got '%s'", repairCmd.Use)
+	}
+
+	for _, c := range []struct {
+		name  string
+		short string
+		long  string
+	}{
+		{name: "prune", short: pruneCmd.Short, long: pruneCmd.Long},
+		{name: "repair", short: repairCmd.Short, long: repairCmd.Long},
+	} {
+		if c.short == "" {
+			t.Errorf("%s command should have short description", c.name)
+		}
+		if c.long == "" {
+			t.Errorf("%s command should have long description", c.name)
+		}
+	}
+
+	if pruneCmd.RunE == nil || repairCmd.RunE == nil {
+		t.Errorf("Maintenance commands should have RunE functions")
+	}
+}
+
+func TestMaintenanceCommandsRegistered(t *testing.T) {
+	names := map[string]bool{}
+	for _, cmd := range rootCmd.Commands() {
+		names[cmd.Name()] = true
+	}
+
+	for _, expected := range []string{"prune", "repair"} {
+		if !names[expected] {
+			t.Errorf("Expected '%s' command to be registered", expected)
+		}
+	}
+}
+
+func TestMaintenanceDryRunFlags(t *testing.T) {
+	pruneFlag := pruneCmd.Flags().Lookup("dry-run")
+	if pruneFlag == nil {
+		t.Fatal("prune should have dry-run flag")
+	}
+	if pruneFlag.Shorthand != "n" || pruneFlag.DefValue != "false" {
+		t.Errorf("Unexpected prune dry-run flag: shorthand '%s', default '%s'", pruneFlag.Shorthand, pruneFlag.DefValue)
+	}
+
+	repairFlag := repairCmd.Flags().Lookup("dry-run")
+	if repairFlag == nil {
+		t.Fatal("repair should have dry-run flag")
+	}
+	if repairFlag.Shorthand != "n" || repairFlag.DefValue != "false" {
+		t.Errorf("Unexpected repair dry-run flag: shorthand '%s', default '%s'", repairFlag.Shorthand, repairFlag.DefValue)
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var moveCmd = &cobra.Command{
+	Use:     "move [worktree] [new-path]",
+	Short:   "Move a git worktree to a new path",
+	Long:    "Interactively select a git worktree and move or rename its directory.",
+	Aliases: []string{"mv"},
+	Args:    cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		worktrees, err := manager.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+
+		movableWorktrees := filterMovableWorktrees(worktrees, manager.MainWorktreePath())
+		if len(movableWorktrees) == 0 {
+			fmt.Println("No movable worktrees found (cannot move the main or current worktree)")
+			return nil
+		}
+
+		var selected worktree.Worktree
+		if len(args) > 0 {
+			wt, err := findWorktreeByPath(movableWorktrees, args[0])
+			if err != nil {
+				return err
+			}
+			selected = *wt
+		} else {
+			model := ui.NewSelector(movableWorktrees, "Move Worktree", "move", false)
+			program := tea.NewProgram(model)
+
+			finalModel, err := program.Run()
+			if err != nil {
+				return fmt.Errorf("failed to run interactive interface: %w", err)
+			}
+
+			result := finalModel.(ui.SelectorModel).GetResult()
+			if result.Action != "select" {
+				return nil
+			}
+			selected = result.Worktree
+		}
+
+		newPath := ""
+		if len(args) > 1 {
+			newPath = args[1]
+		}
+
+		return runMoveWithSelectedWorktree(ctx, terminal, manager, selected, newPath)
+	},
+}
+
+// runMoveWithSelectedWorktree moves a worktree, prompting for the destination if it is empty
+func runMoveWithSelectedWorktree(ctx context.Context, s ui.Session, manager worktree.Manager, selectedWorktree worktree.Worktree, newPath string) error {
+	if selectedWorktree.IsCurrent {
+		return fmt.Errorf("cannot move current worktree")
+	}
+
+	if newPath == "" {
+		result, err := s.Form(ui.NewInput(
+			"Move Worktree",
+			[]string{"New worktree path (e.g., ../feature-renamed)"},
+			[]string{selectedWorktree.Path},
+		))
+		if err != nil {
+			return err
+		}
+		if !result.Submitted {
+			s.Report("Cancelled")
+			return nil
+		}
+		newPath = strings.TrimSpace(result.Values[0])
+	}
+
+	if newPath == "" {
+		return fmt.Errorf("new worktree path is required")
+	}
+
+	// The destination is read against the working directory, as the source is
+	if absPath, err := filepath.Abs(newPath); err == nil {
+		newPath = absPath
+	}
+	if newPath == selectedWorktree.Path {
+		s.Report("Worktree path unchanged")
+		return nil
+	}
+
+	s.Report(fmt.Sprintf("Moving worktree '%s' to '%s'...", selectedWorktree.Path, newPath))
+	if err := manager.Move(ctx, selectedWorktree.Path, newPath, worktree.MoveOptions{}); err != nil {
+		return fmt.Errorf("failed to move worktree: %w", withRecoveryHint(err))
+	}
+
+	s.Report(fmt.Sprintf("✅ Successfully moved worktree to '%s'", newPath))
+	return nil
+}
+
+// filterMovableWorktrees drops the main worktree at mainPath, which git
+// refuses to move, bare entries and the current worktree
+func filterMovableWorktrees(worktrees []worktree.Worktree, mainPath string) []worktree.Worktree {
+	var movable []worktree.Worktree
+	for _, wt := range worktrees {
+		if wt.Path == mainPath || wt.IsCurrent || wt.Bare {
+			continue
+		}
+		movable = append(movable, wt)
+	}
+	return movable
+}
+
+// findWorktreeByPath finds the worktree whose path matches the given (possibly relative) path
+func findWorktreeByPath(worktrees []worktree.Worktree, path string) (*worktree.Worktree, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	for i := range worktrees {
+		if worktrees[i].Path == path || filepath.Clean(worktrees[i].Path) == absPath {
+			return &worktrees[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no worktree found at '%s'", path)
+}
+
+func init() {
+	rootCmd.AddCommand(moveCmd)
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
+)
+
+func TestMoveCommand(t *testing.T) {
+	if moveCmd.Use != "move [worktree] [new-path]" {
+		t.Errorf("Expected move command use to be 'move [worktree] [new-path]', got '%s'", moveCmd.Use)
+	}
+
+	if moveCmd.Short == "" {
+		t.Errorf("Move command should have short description")
+	}
+
+	if moveCmd.Long == "" {
+		t.Errorf("Move command should have long description")
+	}
+
+	if moveCmd.RunE == nil {
+		t.Errorf("Move command should have RunE function")
+	}
+
+	if len(moveCmd.Aliases) != 1 || moveCmd.Aliases[0] != "mv" {
+		t.Errorf("Expected alias 'mv', got %v", moveCmd.Aliases)
+	}
+}
+
+func TestMoveCommandRegistered(t *testing.T) {
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == "move" {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		t.Error("Move command should be registered with root command")
+	}
+}
+
+func TestMoveCommandArgs(t *testing.T) {
+	for _, args := range [][]string{{}, {"a"}, {"a", "b"}} {
+		if err := moveCmd.Args(moveCmd, args); err != nil {
+			t.Errorf("Expected %d args to be accepted, got: %v", len(args), err)
+		}
+	}
+
+	if err := moveCmd.Args(moveCmd, []string{"a", "b", "c"}); err == nil {
+		t.Error("Expected three args to be rejected")
+	}
+}
+
+func TestFilterMovableWorktrees(t *testing.T) {
+	worktrees := []worktree.Worktree{
+		{Path: "/repo", Branch: "main"},
+		{Path: "/repo-feature", Branch: "feature"},
+		{Path: "/repo-current", Branch: "current", IsCurrent: true},
+		{Path: "/repo-fix", Branch: "fix"},
+	}
+
+	movable := filterMovableWorktrees(worktrees, "/repo")
+	if len(movable) != 2 {
+		t.Fatalf("Expected 2 movable worktrees, got %d", len(movable))
+	}
+	if movable[0].Branch != "feature" || movable[1].Branch != "fix" {
+		t.Errorf("Unexpected movable worktrees: %+v", movable)
+	}
+}
+
+func TestFilterMovableWorktreesBareLayout(t *testing.T) {
+	// In a bare layout the first listed entry is the bare repository, and
+	// the first worktree after it is a linked one that can be moved
+	worktrees := []worktree.Worktree{
+		{Path: "/repo/.bare", Branch: "(bare)", Bare: true},
+		{Path: "/repo/main", Branch: "main"},
+		{Path: "/repo/feature", Branch: "feature"},
+	}
+
+	movable := filterMovableWorktrees(worktree.WithoutBare(worktrees), "/repo/.bare")
+	if len(movable) != 2 || movable[0].Branch != "main" {
+		t.Errorf("Expected both linked worktrees to be movable, got %+v", movable)
+	}
+}
+
+func TestFindWorktreeByPath(t *testing.T) {
+	worktrees := []worktree.Worktree{
+		{Path: "/tmp/repo-feature", Branch: "feature"},
+	}
+
+	wt, err := findWorktreeByPath(worktrees, "/tmp/repo-feature/")
+	if err != nil {
+		t.Fatalf("Expected worktree to be found: %v", err)
+	}
+	if wt.Branch != "feature" {
+		t.Errorf("Expected branch 'feature', got '%s'", wt.Branch)
+	}
+
+	_, err = findWorktreeByPath(worktrees, "/tmp/missing")
+	if err == nil || !strings.Contains(err.Error(), "no worktree found") {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+}
+
+func TestRunMoveWithSelectedWorktreeCurrentCheck(t *testing.T) {
+	currentWorktree := worktree.Worktree{
+		Path:      "/current",
+		Branch:    "main",
+		IsCurrent: true,
+	}
+
+	err := runMoveWithSelectedWorktree(context.Background(), terminal, nil, currentWorktree, "/elsewhere")
+	if err == nil {
+		t.Fatal("Expected error when trying to move current worktree")
+	}
+
+	expectedMsg := "cannot move current worktree"
+	if err.Error() != expectedMsg {
+		t.Errorf("Expected error message '%s', got '%s'", expectedMsg, err.Error())
+	}
+}
+
+func TestRunMoveResolvesDestinationFromWorkingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "move")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+	// Both arguments of 'yosegi move ./a ./b' are read against the same base
+	wt := worktree.Worktree{Path: filepath.Join(dir, "a"), Branch: "a"}
+	if err := runMoveWithSelectedWorktree(context.Background(), terminal, manager, wt, "./b"); err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if !runner.Called("worktree", "move", wt.Path, filepath.Join(dir, "b")) {
+		t.Errorf("Expected the destination next to the source, got %+v", runner.Calls())
+	}
+}
@@ -1,21 +1,22 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/yagi2/yosegi/internal/config"
-	"github.com/yagi2/yosegi/internal/git"
 	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 var (
 	createBranch    bool
 	createBranchSet bool // Track if the flag was explicitly set
 	worktreePath    string
+	openCreated     bool
 )
 
 var newCmd = &cobra.Command{
@@ -34,106 +35,117 @@ var newCmd = &cobra.Command{
 			}
 		}
 
-		manager, err := git.NewManager()
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to initialize git manager: %w", err)
 		}
 
-		var branch string
-		var path string
-
-		// If branch is provided as argument, use it
+		branch := ""
 		if len(args) > 0 {
 			branch = args[0]
 		}
+		created, err := runNew(ctx, terminal, manager, cfg, branch, worktreePath)
+		if err != nil || created == nil || !openCreated {
+			return err
+		}
+		return openInEditor(*created)
+	},
+}
 
-		// Interactive mode for missing parameters
-		if branch == "" || worktreePath == "" {
-			var model ui.InputModel
-
-			// If both branch and path are missing, use the enhanced worktree input with auto-generation
-			if branch == "" && worktreePath == "" {
-				model = ui.NewWorktreeInput("Create New Worktree", cfg.DefaultWorktreePath)
-			} else {
-				// Fallback to regular input for partial inputs
-				prompts := []string{}
-				defaults := []string{}
-
-				if branch == "" {
-					prompts = append(prompts, "Branch name (e.g., feature/new-feature)")
-					defaults = append(defaults, "")
-				}
-
-				if worktreePath == "" {
-					prompts = append(prompts, "Worktree directory path (e.g., ../feature-branch)")
-					if branch != "" {
-						defaults = append(defaults, filepath.Join(cfg.DefaultWorktreePath, branch))
-					} else {
-						defaults = append(defaults, "")
-					}
-				}
-
-				model = ui.NewInput("Create New Worktree", prompts, defaults)
-			}
-
-			program := tea.NewProgram(model)
+// runNew creates a worktree for branch at path, asking for whichever of them
+// is empty, and returns it. It returns nil without an error when the form is
+// cancelled.
+func runNew(ctx context.Context, s ui.Session, manager worktree.Manager, cfg *config.Config, branch, worktreePath string) (*worktree.Worktree, error) {
+	var path string
 
-			finalModel, err := program.Run()
-			if err != nil {
-				return fmt.Errorf("failed to run interactive interface: %w", err)
-			}
+	// Interactive mode for missing parameters
+	if branch == "" || worktreePath == "" {
+		var model ui.InputModel
 
-			result := finalModel.(ui.InputModel).GetResult()
-			if !result.Submitted {
-				fmt.Println("Cancelled")
-				return nil
-			}
-
-			values := result.Values
-			idx := 0
+		// If both branch and path are missing, use the enhanced worktree input with auto-generation
+		if branch == "" && worktreePath == "" {
+			model = ui.NewWorktreeInput("Create New Worktree", cfg.DefaultWorktreePath)
+		} else {
+			// Fallback to regular input for partial inputs
+			prompts := []string{}
+			defaults := []string{}
 
 			if branch == "" {
-				branch = strings.TrimSpace(values[idx])
-				idx++
+				prompts = append(prompts, "Branch name (e.g., feature/new-feature)")
+				defaults = append(defaults, "")
 			}
 
 			if worktreePath == "" {
-				path = strings.TrimSpace(values[idx])
+				prompts = append(prompts, "Worktree directory path (e.g., ../feature-branch)")
+				if branch != "" {
+					defaults = append(defaults, filepath.Join(cfg.DefaultWorktreePath, branch))
+				} else {
+					defaults = append(defaults, "")
+				}
 			}
-		} else {
-			path = worktreePath
+
+			model = ui.NewInput("Create New Worktree", prompts, defaults)
 		}
 
-		// Validate inputs
-		if branch == "" {
-			return fmt.Errorf("branch name is required")
+		result, err := s.Form(model)
+		if err != nil {
+			return nil, err
 		}
-		if path == "" {
-			return fmt.Errorf("worktree path is required")
+		if !result.Submitted {
+			s.Report("Cancelled")
+			return nil, nil
 		}
 
-		// Create the worktree
-		// Use config auto_create_branch if createBranch flag is not explicitly set
-		if !createBranchSet && cfg.Git.AutoCreateBranch {
-			createBranch = true
+		values := result.Values
+		idx := 0
+
+		if branch == "" {
+			branch = strings.TrimSpace(values[idx])
+			idx++
 		}
 
-		fmt.Printf("Creating worktree '%s' at '%s'...\n", branch, path)
-		err = manager.Add(path, branch, createBranch)
-		if err != nil {
-			return fmt.Errorf("failed to create worktree: %w", err)
+		if worktreePath == "" {
+			path = strings.TrimSpace(values[idx])
 		}
+	} else {
+		path = worktreePath
+	}
+
+	// Validate inputs
+	if branch == "" {
+		return nil, fmt.Errorf("branch name is required")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("worktree path is required")
+	}
+	// git takes a relative path from the repository root, like the ../ default
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(manager.MainWorktreePath(), path)
+	}
 
-		fmt.Printf("✅ Successfully created worktree '%s' at '%s'\n", branch, path)
+	// Create the worktree
+	// Use config auto_create_branch if createBranch flag is not explicitly set
+	create := createBranch
+	if !createBranchSet && cfg.Git.AutoCreateBranch {
+		create = true
+	}
 
-		return nil
-	},
+	s.Report(fmt.Sprintf("Creating worktree '%s' at '%s'...", branch, path))
+	if err := addWithRecovery(ctx, s, manager, path, branch, create); err != nil {
+		return nil, fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	s.Report(fmt.Sprintf("✅ Successfully created worktree '%s' at '%s'", branch, path))
+
+	return &worktree.Worktree{Path: path, Branch: branch}, nil
 }
 
 func init() {
 	flags := newCmd.Flags()
 	flags.BoolVarP(&createBranch, "create-branch", "b", false, "Create a new branch")
 	flags.StringVarP(&worktreePath, "path", "p", "", "Path for the new worktree")
+	flags.BoolVarP(&openCreated, "open", "o", false, "Open the new worktree in your editor")
 
 	// Mark that create-branch flag was explicitly set
 	newCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
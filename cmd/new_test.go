@@ -2,10 +2,15 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/internal/config"
+	"github.com/yagi2/yosegi/pkg/worktree"
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
 )
 
 func TestNewCommand(t *testing.T) {
@@ -128,6 +133,46 @@ func TestNewCommandFlags(t *testing.T) {
 			t.Error("path flag should have usage text")
 		}
 	}
+
+	// Test open flag
+	if openFlag := newCmd.Flags().Lookup("open"); openFlag == nil || openFlag.Shorthand != "o" || openFlag.DefValue != "false" {
+		t.Errorf("Expected an open flag on -o, off by default, got %+v", openFlag)
+	}
+}
+
+func TestRunNewReturnsCreatedWorktree(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "add").Return("")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+	path := filepath.Join(t.TempDir(), "feature")
+
+	created, err := runNew(context.Background(), terminal, manager, &config.Config{Git: config.GitConfig{AutoCreateBranch: true}}, "feature", path)
+	if err != nil {
+		t.Fatalf("runNew failed: %v", err)
+	}
+	if created == nil || created.Path != path || created.Branch != "feature" {
+		t.Errorf("Expected the new worktree to be returned for --open, got %+v", created)
+	}
+	if !runner.Called("worktree", "add") {
+		t.Errorf("Expected the worktree to be added, got %+v", runner.Calls())
+	}
+}
+
+func TestRunNewTakesRelativePathFromRepositoryRoot(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "add").Return("")
+	manager := worktree.NewManagerWithRunner("/repo/.bare", "/repo/.bare", runner)
+
+	created, err := runNew(context.Background(), terminal, manager, &config.Config{Git: config.GitConfig{AutoCreateBranch: true}}, "feature", "../feature")
+	if err != nil {
+		t.Fatalf("runNew failed: %v", err)
+	}
+	if !runner.Called("worktree", "add", "-b", "feature", "/repo/feature") {
+		t.Errorf("Expected the worktree to be added next to the repository, got %+v", runner.Calls())
+	}
+	if created == nil || created.Path != "/repo/feature" {
+		t.Errorf("Expected --open to get the path git created, got %+v", created)
+	}
 }
 
 func TestNewCommandFlagValues(t *testing.T) {
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/internal/config"
+	"github.com/yagi2/yosegi/internal/launch"
+	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open [query]",
+	Short: "Open a worktree in your editor",
+	Long: `Open a worktree in the command set by 'editor' in the configuration, such as
+"code {{.Path}}", or in $VISUAL or $EDITOR when it is not set.
+
+The query picks the worktree by path, or by branch or directory name, first exactly and
+then by substring. Several matches, or no query, are narrowed down interactively.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		selected, err := resolveWorktree(ctx, manager, args, "Open Worktree", "open")
+		if err != nil || selected == nil {
+			return err
+		}
+
+		recordChoice(manager, *selected)
+		return openInEditor(*selected)
+	},
+}
+
+var shellCmd = &cobra.Command{
+	Use:   "shell [query]",
+	Short: "Start a shell in a worktree",
+	Long: `Start $SHELL, or /bin/sh, in a worktree; exit the shell to return.
+The query picks the worktree as in 'yosegi open'.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		selected, err := resolveWorktree(ctx, manager, args, "Open Shell", "open shell")
+		if err != nil || selected == nil {
+			return err
+		}
+
+		recordChoice(manager, *selected)
+		fmt.Printf("🐚 Starting a shell in '%s'; exit it to return\n", selected.Path)
+		return runShell(*selected)
+	},
+}
+
+// resolveWorktree finds the worktree meant by the query in args among those
+// list shows, asking the user to choose when it matches several or there is
+// no query. It returns nil without an error when the selection is cancelled.
+func resolveWorktree(ctx context.Context, manager worktree.Manager, args []string, title, action string) (*worktree.Worktree, error) {
+	worktrees, err := manager.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	candidates := listedWorktrees(worktrees)
+	if len(args) > 0 {
+		// Naming a worktree by path or branch finds it even when it is excluded
+		if wt := exactWorktree(worktree.WithoutBare(worktrees), args[0]); wt != nil {
+			return wt, nil
+		}
+
+		candidates = matchWorktrees(candidates, args[0])
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("no worktree matches '%s'", args[0])
+		}
+		if len(candidates) == 1 {
+			return &candidates[0], nil
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no worktrees found")
+	}
+
+	candidates = ui.SortWorktrees(candidates, worktreeSorting(ctx, manager, candidates))
+	model := ui.NewSelector(candidates, title, action, false)
+	program := tea.NewProgram(model)
+
+	finalModel, err := program.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run interactive interface: %w", err)
+	}
+
+	result := finalModel.(ui.SelectorModel).GetResult()
+	if result.Action != "select" {
+		return nil, nil
+	}
+	return &result.Worktree, nil
+}
+
+// matchWorktrees returns the worktrees query refers to: the one at that
+// path, else those whose branch or directory name is query, else those whose
+// branch or directory name contains it, ignoring case
+func matchWorktrees(worktrees []worktree.Worktree, query string) []worktree.Worktree {
+	if wt, err := findWorktreeByPath(worktrees, query); err == nil {
+		return []worktree.Worktree{*wt}
+	}
+
+	var exact, partial []worktree.Worktree
+	lower := strings.ToLower(query)
+	for _, wt := range worktrees {
+		name := filepath.Base(wt.Path)
+		switch {
+		case wt.Branch == query || name == query:
+			exact = append(exact, wt)
+		case strings.Contains(strings.ToLower(wt.Branch), lower) || strings.Contains(strings.ToLower(name), lower):
+			partial = append(partial, wt)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return partial
+}
+
+// exactWorktree returns the worktree at the path query, or on the branch
+// query, and nil when there is none
+func exactWorktree(worktrees []worktree.Worktree, query string) *worktree.Worktree {
+	if wt, err := findWorktreeByPath(worktrees, query); err == nil {
+		return wt
+	}
+	for i := range worktrees {
+		if worktrees[i].Branch == query {
+			return &worktrees[i]
+		}
+	}
+	return nil
+}
+
+// editorSetting returns the editor from the configuration, empty when unset
+func editorSetting() string {
+	cfg, err := config.Load()
+	if err != nil {
+		return ""
+	}
+	return cfg.Editor
+}
+
+// openInEditor opens wt in the configured editor and waits for it, which for
+// terminal editors is until they are closed
+func openInEditor(wt worktree.Worktree) error {
+	cmd, err := launch.Editor(editorSetting(), wt)
+	if err != nil {
+		return err
+	}
+
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+	return nil
+}
+
+// runShell runs the user's shell in wt until it exits. Its exit status is
+// that of the last command run in it, so it is not reported as a failure.
+func runShell(wt worktree.Worktree) error {
+	cmd := launch.Shell(wt)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("shell failed: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+	rootCmd.AddCommand(shellCmd)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
+)
+
+func TestMatchWorktrees(t *testing.T) {
+	worktrees := []worktree.Worktree{
+		{Path: "/repo", Branch: "main"},
+		{Path: "/work/login", Branch: "feature/login"},
+		{Path: "/work/login-v2", Branch: "feature/login-v2"},
+		{Path: "/work/fix", Branch: "fix/Logout"},
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "/work/login", expected: []string{"/work/login"}},
+		{query: "feature/login", expected: []string{"/work/login"}},
+		{query: "login-v2", expected: []string{"/work/login-v2"}},
+		{query: "log", expected: []string{"/work/login", "/work/login-v2", "/work/fix"}},
+		{query: "LOGOUT", expected: []string{"/work/fix"}},
+		{query: "release", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var paths []string
+			for _, wt := range matchWorktrees(worktrees, tt.query) {
+				paths = append(paths, wt.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, paths)
+			}
+		})
+	}
+}
+
+func TestResolveWorktree(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "list").Return("worktree /repo\nHEAD aaa\nbranch refs/heads/main\n\nworktree /work/login\nHEAD bbb\nbranch refs/heads/feature/login\n\n")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+	// A query with one match needs no selection
+	selected, err := resolveWorktree(context.Background(), manager, []string{"login"}, "Open Worktree", "open")
+	if err != nil || selected == nil || selected.Path != "/work/login" {
+		t.Errorf("Expected /work/login, got %+v, %v", selected, err)
+	}
+
+	_, err = resolveWorktree(context.Background(), manager, []string{"release"}, "Open Worktree", "open")
+	if err == nil || !strings.Contains(err.Error(), "no worktree matches 'release'") {
+		t.Errorf("Expected no match, got %v", err)
+	}
+}
+
+func TestResolveExcludedWorktree(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".config", "yosegi")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := "git:\n  exclude_patterns: [\"archive/*\"]\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "list").Return("worktree /repo\nHEAD aaa\nbranch refs/heads/main\n\nworktree /work/old-login\nHEAD bbb\nbranch refs/heads/archive/login\n\n")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+	// An excluded worktree is found by its branch or path, not by a substring
+	for _, query := range []string{"archive/login", "/work/old-login"} {
+		selected, err := resolveWorktree(context.Background(), manager, []string{query}, "Open Worktree", "open")
+		if err != nil || selected == nil || selected.Path != "/work/old-login" {
+			t.Errorf("Expected %q to find /work/old-login, got %+v, %v", query, selected, err)
+		}
+	}
+	_, err := resolveWorktree(context.Background(), manager, []string{"old-log"}, "Open Worktree", "open")
+	if err == nil || !strings.Contains(err.Error(), "no worktree matches") {
+		t.Errorf("Expected the excluded worktree not to match a substring, got %v", err)
+	}
+}
+
+func TestOpenInEditor(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	wt := worktree.Worktree{Path: t.TempDir(), Branch: "feature"}
+
+	t.Setenv("VISUAL", "true")
+	if err := openInEditor(wt); err != nil {
+		t.Errorf("Expected the editor to run, got %v", err)
+	}
+
+	t.Setenv("VISUAL", "false")
+	if err := openInEditor(wt); err == nil || !strings.Contains(err.Error(), "editor failed") {
+		t.Errorf("Expected the editor's failure, got %v", err)
+	}
+}
+
+func TestRunShell(t *testing.T) {
+	wt := worktree.Worktree{Path: t.TempDir()}
+
+	// The shell's exit status is its last command's, not a failure
+	t.Setenv("SHELL", "false")
+	if err := runShell(wt); err != nil {
+		t.Errorf("Expected the exit status to be ignored, got %v", err)
+	}
+
+	t.Setenv("SHELL", "/nonexistent/shell")
+	if err := runShell(wt); err == nil || !strings.Contains(err.Error(), "shell failed") {
+		t.Errorf("Expected a shell that cannot start to fail, got %v", err)
+	}
+}
+
+func TestOpenCommandsRegistered(t *testing.T) {
+	for _, name := range []string{"open", "shell"} {
+		cmd, _, err := rootCmd.Find([]string{name})
+		if err != nil || cmd.Name() != name {
+			t.Errorf("Expected the %s command to be registered, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// removeWithRecovery removes a worktree, offering to force the removal when
+// git refuses because the worktree has uncommitted changes
+func removeWithRecovery(ctx context.Context, s ui.Session, manager worktree.Manager, path string, force bool) error {
+	err := manager.Remove(ctx, path, worktree.RemoveOptions{Force: force})
+	if !force && errors.Is(err, worktree.ErrDirtyWorktree) &&
+		s.Confirm("Worktree Has Changes", fmt.Sprintf("Worktree at '%s' has uncommitted changes. Force remove and discard them?", path)) {
+		err = manager.Remove(ctx, path, worktree.RemoveOptions{Force: true})
+	}
+	return withRecoveryHint(err)
+}
+
+// deleteBranchWithRecovery deletes a branch, offering to force the deletion
+// when git refuses because the branch is not fully merged
+func deleteBranchWithRecovery(ctx context.Context, s ui.Session, manager worktree.Manager, branch string, force bool) error {
+	err := manager.DeleteBranch(ctx, branch, force)
+	if !force && errors.Is(err, worktree.ErrBranchNotMerged) &&
+		s.Confirm("Branch Not Merged", fmt.Sprintf("Branch '%s' is not fully merged. Delete it anyway?", branch)) {
+		err = manager.DeleteBranch(ctx, branch, true)
+	}
+	return err
+}
+
+// addWithRecovery creates a worktree, offering to check out an existing
+// branch instead of creating it, or to create a branch that does not exist
+func addWithRecovery(ctx context.Context, s ui.Session, manager worktree.Manager, path, branch string, createBranch bool) error {
+	err := manager.Create(ctx, worktree.CreateOptions{Path: path, Branch: branch, CreateBranch: createBranch})
+	switch {
+	case createBranch && errors.Is(err, worktree.ErrBranchExists):
+		if s.Confirm("Branch Exists", fmt.Sprintf("Branch '%s' already exists. Check it out in the new worktree?", branch)) {
+			err = manager.Create(ctx, worktree.CreateOptions{Path: path, Branch: branch})
+		}
+	case !createBranch && errors.Is(err, worktree.ErrBranchNotFound):
+		if s.Confirm("Branch Not Found", fmt.Sprintf("Branch '%s' does not exist. Create it?", branch)) {
+			err = manager.Create(ctx, worktree.CreateOptions{Path: path, Branch: branch, CreateBranch: true})
+		}
+	}
+	return withRecoveryHint(err)
+}
+
+// recoveryHint suggests how to get past a typed git error, or returns "" when
+// there is nothing to suggest
+func recoveryHint(err error) string {
+	switch {
+	case errors.Is(err, worktree.ErrLocked):
+		return "Run 'yosegi unlock' first"
+	case errors.Is(err, worktree.ErrMissing):
+		return "Run 'yosegi prune' to clean up stale entries, or 'yosegi repair' if it was moved"
+	case errors.Is(err, worktree.ErrDirtyWorktree):
+		return "Commit or stash the changes, or pass --force to discard them"
+	case errors.Is(err, worktree.ErrBranchExists):
+		return "Omit --create-branch to check out the existing branch"
+	case errors.Is(err, worktree.ErrBranchNotFound):
+		return "Pass --create-branch to create it"
+	}
+	return ""
+}
+
+// withRecoveryHint appends the recovery hint for err, keeping it matchable
+// with errors.Is
+func withRecoveryHint(err error) error {
+	if hint := recoveryHint(err); hint != "" {
+		return fmt.Errorf("%w. %s", err, hint)
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
+)
+
+// answerRecovery replaces the recovery prompt with a fixed answer for the test
+func answerRecovery(t *testing.T, answer bool) *int {
+	t.Helper()
+
+	asked := 0
+	original := confirmDialog
+	confirmDialog = func(title, message string) bool {
+		asked++
+		return answer
+	}
+	t.Cleanup(func() { confirmDialog = original })
+	return &asked
+}
+
+func TestRemoveWithRecovery(t *testing.T) {
+	tests := []struct {
+		name        string
+		answer      bool
+		expectForce bool
+		expectError bool
+	}{
+		{name: "Force after confirmation", answer: true, expectForce: true},
+		{name: "Declined", answer: false, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asked := answerRecovery(t, tt.answer)
+
+			runner := worktreetest.NewRunner()
+			runner.On("worktree", "list").Return("")
+			runner.On("worktree", "remove", "/tmp/wt").Fail("fatal: '/tmp/wt' contains modified or untracked files, use --force to delete it")
+			runner.On("worktree", "remove", "--force", "/tmp/wt")
+			manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+			err := removeWithRecovery(context.Background(), terminal, manager, "/tmp/wt", false)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("removeWithRecovery() error = %v, expectError %v", err, tt.expectError)
+			}
+			if *asked != 1 {
+				t.Errorf("Expected one prompt, got %d", *asked)
+			}
+			if runner.Called("worktree", "remove", "--force") != tt.expectForce {
+				t.Errorf("Forced removal = %v, want %v", !tt.expectForce, tt.expectForce)
+			}
+			if tt.expectError && (!errors.Is(err, worktree.ErrDirtyWorktree) || !strings.Contains(err.Error(), "--force")) {
+				t.Errorf("Expected dirty worktree error with hint, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRemoveWithRecoveryAlreadyForced(t *testing.T) {
+	asked := answerRecovery(t, true)
+
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "list").Return("")
+	runner.On("worktree", "remove").Fail("fatal: '/tmp/wt' contains modified or untracked files")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+	if err := removeWithRecovery(context.Background(), terminal, manager, "/tmp/wt", true); err == nil {
+		t.Error("Expected error")
+	}
+	if *asked != 0 {
+		t.Error("Expected no prompt when removal was already forced")
+	}
+}
+
+func TestDeleteBranchWithRecovery(t *testing.T) {
+	asked := answerRecovery(t, true)
+
+	runner := worktreetest.NewRunner()
+	runner.On("branch", "-d").Fail("error: The branch 'feature' is not fully merged.")
+	runner.On("branch", "-D", "feature")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+	if err := deleteBranchWithRecovery(context.Background(), terminal, manager, "feature", false); err != nil {
+		t.Fatalf("deleteBranchWithRecovery() failed: %v", err)
+	}
+	if *asked != 1 || !runner.Called("branch", "-D", "feature") {
+		t.Errorf("Expected forced deletion after confirmation, got calls %+v", runner.Calls())
+	}
+}
+
+func TestAddWithRecovery(t *testing.T) {
+	tests := []struct {
+		name         string
+		branchExists bool
+		createBranch bool
+		expectedArgs []string
+	}{
+		{
+			name:         "Check out existing branch",
+			branchExists: true,
+			createBranch: true,
+			expectedArgs: []string{"worktree", "add", "/tmp/wt", "feature"},
+		},
+		{
+			name:         "Create missing branch",
+			branchExists: false,
+			createBranch: false,
+			expectedArgs: []string{"worktree", "add", "-b", "feature", "/tmp/wt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asked := answerRecovery(t, true)
+
+			runner := worktreetest.NewRunner()
+			if tt.branchExists {
+				runner.On("rev-parse", "--verify").Return("abc\n")
+			} else {
+				runner.On("rev-parse", "--verify").Fail("fatal: Needed a single revision")
+			}
+			runner.On("worktree", "add")
+			manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+			if err := addWithRecovery(context.Background(), terminal, manager, "/tmp/wt", "feature", tt.createBranch); err != nil {
+				t.Fatalf("addWithRecovery() failed: %v", err)
+			}
+			if *asked != 1 {
+				t.Errorf("Expected one prompt, got %d", *asked)
+			}
+			if !runner.Called(tt.expectedArgs...) {
+				t.Errorf("Expected git %v, got calls %+v", tt.expectedArgs, runner.Calls())
+			}
+		})
+	}
+}
+
+func TestRecoveryHint(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{err: &worktree.WorktreeError{Path: "/tmp/wt", Err: worktree.ErrLocked}, expected: "yosegi unlock"},
+		{err: &worktree.WorktreeError{Path: "/tmp/wt", Err: worktree.ErrDirtyWorktree}, expected: "--force"},
+		{err: &worktree.WorktreeError{Path: "/tmp/wt", Err: worktree.ErrMissing}, expected: "yosegi prune"},
+		{err: &worktree.BranchError{Branch: "x", Err: worktree.ErrBranchExists}, expected: "Omit --create-branch"},
+		{err: &worktree.BranchError{Branch: "x", Err: worktree.ErrBranchNotFound}, expected: "Pass --create-branch"},
+		{err: fmt.Errorf("something else"), expected: ""},
+		{err: nil, expected: ""},
+	}
+
+	for _, tt := range tests {
+		hint := recoveryHint(tt.err)
+		if tt.expected == "" && hint != "" {
+			t.Errorf("recoveryHint(%v) = %q, want no hint", tt.err, hint)
+		}
+		if !strings.Contains(hint, tt.expected) {
+			t.Errorf("recoveryHint(%v) = %q, want it to contain %q", tt.err, hint, tt.expected)
+		}
+	}
+
+	wrapped := withRecoveryHint(&worktree.WorktreeError{Path: "/tmp/wt", Err: worktree.ErrLocked})
+	if !errors.Is(wrapped, worktree.ErrLocked) {
+		t.Error("Expected hinted error to still match ErrLocked")
+	}
+	if withRecoveryHint(nil) != nil {
+		t.Error("Expected nil error to stay nil")
+	}
+}
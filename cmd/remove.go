@@ -6,12 +6,13 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/yagi2/yosegi/internal/config"
-	"github.com/yagi2/yosegi/internal/git"
 	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 var (
-	forceRemove bool
+	forceRemove   bool
+	trashOnRemove bool
 )
 
 var removeCmd = &cobra.Command{
@@ -20,12 +21,13 @@ var removeCmd = &cobra.Command{
 	Long:    "Interactively select and remove a git worktree.",
 	Aliases: []string{"rm", "delete", "del", "r"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		manager, err := git.NewManager()
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to initialize git manager: %w", err)
 		}
 
-		worktrees, err := manager.List()
+		worktrees, err := manager.List(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to list worktrees: %w", err)
 		}
@@ -35,9 +37,10 @@ var removeCmd = &cobra.Command{
 			return nil
 		}
 
-		// Filter out current worktree (can't remove current worktree)
-		var removableWorktrees []git.Worktree
-		for _, wt := range worktrees {
+		// Filter out current worktree (can't remove current worktree) and the
+		// bare repository, which holds every branch
+		var removableWorktrees []worktree.Worktree
+		for _, wt := range worktree.WithoutBare(worktrees) {
 			if !wt.IsCurrent {
 				removableWorktrees = append(removableWorktrees, wt)
 			}
@@ -49,7 +52,8 @@ var removeCmd = &cobra.Command{
 		}
 
 		// Interactive mode
-		model := ui.NewSelector(removableWorktrees, "Remove Worktree", "remove", true)
+		model := ui.NewSelector(removableWorktrees, "Remove Worktree", "remove", true).
+			WithPreview(worktreePreview(ctx, manager))
 		program := tea.NewProgram(model)
 
 		finalModel, err := program.Run()
@@ -63,6 +67,10 @@ var removeCmd = &cobra.Command{
 		}
 
 		if result.Action == "remove" || result.Action == "delete" || result.Action == "select" {
+			if err := ensureUnlocked(result.Worktree); err != nil {
+				return err
+			}
+
 			// Confirm removal
 			confirmModel := ui.NewConfirm(
 				"Confirm Removal",
@@ -81,14 +89,24 @@ var removeCmd = &cobra.Command{
 				return nil
 			}
 
+			// Snapshot the worktree so the removal can be undone
+			var snapshot *worktree.TrashEntry
+			if shouldTrashOnRemove() {
+				if snapshot, err = trashWorktree(ctx, terminal, manager, result.Worktree); err != nil {
+					return err
+				}
+			}
+
 			// Remove the worktree
 			fmt.Printf("Removing worktree at '%s'...\n", result.Worktree.Path)
-			err = manager.Remove(result.Worktree.Path, forceRemove)
+			err = removeWithRecovery(ctx, terminal, manager, result.Worktree.Path, forceRemove)
 			if err != nil {
+				dropSnapshot(ctx, terminal, manager, snapshot)
 				return fmt.Errorf("failed to remove worktree: %w", err)
 			}
 
 			fmt.Printf("✅ Successfully removed worktree at '%s'\n", result.Worktree.Path)
+			killSession(ctx, terminal, result.Worktree)
 
 			// Check if we should also delete the branch
 			cfg, err := config.Load()
@@ -105,7 +123,7 @@ var removeCmd = &cobra.Command{
 			deleteBranch := cfg.Git.DeleteBranchOnWorktreeRemove
 
 			// Check for unpushed commits
-			hasUnpushed, unpushedCount, err := manager.HasUnpushedCommits(result.Worktree.Branch)
+			hasUnpushed, unpushedCount, err := manager.HasUnpushedCommits(ctx, result.Worktree.Branch)
 			if err == nil && hasUnpushed {
 				// Show warning and ask for confirmation
 				warningModel := ui.NewConfirm(
@@ -145,7 +163,7 @@ var removeCmd = &cobra.Command{
 			// Delete the branch if confirmed
 			if deleteBranch {
 				fmt.Printf("Deleting branch '%s'...\n", result.Worktree.Branch)
-				err = manager.DeleteBranch(result.Worktree.Branch, forceRemove || hasUnpushed)
+				err = deleteBranchWithRecovery(ctx, terminal, manager, result.Worktree.Branch, forceRemove || hasUnpushed)
 				if err != nil {
 					// Don't fail the whole operation if branch deletion fails
 					fmt.Printf("⚠️  Warning: Failed to delete branch: %v\n", err)
@@ -161,5 +179,6 @@ var removeCmd = &cobra.Command{
 
 func init() {
 	removeCmd.Flags().BoolVarP(&forceRemove, "force", "f", false, "Force removal even if worktree is dirty")
+	removeCmd.Flags().BoolVarP(&trashOnRemove, "trash", "t", false, "Save the worktree and its uncommitted changes to the trash before removal")
 	rootCmd.AddCommand(removeCmd)
 }
@@ -486,6 +486,21 @@ func TestRemoveCommandFlagBinding(t *testing.T) {
 	forceRemove = originalForceRemove
 }
 
+func TestRemoveCommandTrashFlag(t *testing.T) {
+	trashFlag := removeCmd.Flags().Lookup("trash")
+	if trashFlag == nil {
+		t.Fatal("trash flag should exist")
+	}
+
+	if trashFlag.Shorthand != "t" {
+		t.Errorf("Expected trash flag shorthand to be 't', got '%s'", trashFlag.Shorthand)
+	}
+
+	if trashFlag.DefValue != "false" {
+		t.Errorf("Expected trash flag default value 'false', got '%s'", trashFlag.DefValue)
+	}
+}
+
 // Benchmark tests
 func BenchmarkRemoveCommandCreation(b *testing.B) {
 	b.ResetTimer()
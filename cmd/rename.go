@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/internal/config"
+	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var (
+	renameWorktree   string
+	renameMove       bool
+	renameNoUpstream bool
+)
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <new-branch>",
+	Short: "Rename a worktree's branch",
+	Long: `Rename the branch checked out in the current worktree (or --worktree).
+With --move, the worktree directory is also moved to the path derived from default_worktree_path.
+The upstream is retargeted to the new branch name unless --no-upstream is given, provided
+the remote has a branch with that name; otherwise it is left unchanged.
+If any step fails, the completed steps are rolled back.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		newBranch := args[0]
+
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		worktrees, err := manager.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+
+		target, err := findRenameTarget(worktrees, renameWorktree)
+		if err != nil {
+			return err
+		}
+
+		if target.Branch == "(detached)" || target.Branch == "(bare)" {
+			return fmt.Errorf("worktree at '%s' has no branch to rename", target.Path)
+		}
+
+		opts := worktree.RenameOptions{
+			Path:           target.Path,
+			OldBranch:      target.Branch,
+			NewBranch:      newBranch,
+			UpdateUpstream: !renameNoUpstream,
+		}
+
+		if renameMove {
+			cfg, err := config.Load()
+			if err != nil {
+				cfg = &config.Config{DefaultWorktreePath: "../"}
+			}
+			opts.NewPath = ui.WorktreePathForBranch(cfg.DefaultWorktreePath, newBranch)
+		}
+
+		fmt.Printf("Renaming branch '%s' to '%s'...\n", opts.OldBranch, opts.NewBranch)
+		result, err := manager.Rename(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to rename worktree: %w", err)
+		}
+
+		fmt.Printf("✅ Successfully renamed branch '%s' to '%s'\n", opts.OldBranch, opts.NewBranch)
+		if result.Upstream != "" {
+			fmt.Printf("✅ Upstream now tracks '%s'\n", result.Upstream)
+		}
+		if result.MissingUpstream != "" {
+			fmt.Printf("⚠️  '%s' does not exist, so the upstream was left unchanged. Push the branch with -u to track it\n", result.MissingUpstream)
+		}
+		if opts.NewPath != "" {
+			fmt.Printf("✅ Moved worktree to '%s'\n", opts.NewPath)
+			if target.IsCurrent {
+				fmt.Println("The current directory was moved; cd to the new path to continue working")
+			}
+		}
+		return nil
+	},
+}
+
+// findRenameTarget returns the worktree at path, or the worktree containing
+// the working directory when path is empty
+func findRenameTarget(worktrees []worktree.Worktree, path string) (*worktree.Worktree, error) {
+	if path != "" {
+		return findWorktreeByPath(worktrees, path)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the working directory: %w", err)
+	}
+	if wt := worktreeContaining(worktrees, cwd); wt != nil {
+		return wt, nil
+	}
+	return nil, fmt.Errorf("not in a worktree. Use --worktree to choose one")
+}
+
+// worktreeContaining returns the worktree that dir is in, the innermost one
+// when worktrees are nested, or nil when dir is in none of them
+func worktreeContaining(worktrees []worktree.Worktree, dir string) *worktree.Worktree {
+	var found *worktree.Worktree
+	for i, wt := range worktrees {
+		if wt.Bare {
+			continue
+		}
+		rel, err := filepath.Rel(wt.Path, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if found == nil || len(wt.Path) > len(found.Path) {
+			found = &worktrees[i]
+		}
+	}
+	return found
+}
+
+func init() {
+	flags := renameCmd.Flags()
+	flags.StringVarP(&renameWorktree, "worktree", "w", "", "Path of the worktree to rename (defaults to the current worktree)")
+	flags.BoolVarP(&renameMove, "move", "m", false, "Also move the worktree directory to match the new branch name")
+	flags.BoolVar(&renameNoUpstream, "no-upstream", false, "Keep the upstream pointing at the old remote branch name")
+
+	rootCmd.AddCommand(renameCmd)
+}
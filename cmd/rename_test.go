@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+func TestRenameCommand(t *testing.T) {
+	if renameCmd.Use != "rename <new-branch>" {
+		t.Errorf("Expected rename command use to be 'rename <new-branch>', got '%s'", renameCmd.Use)
+	}
+
+	if renameCmd.Short == "" {
+		t.Errorf("Rename command should have short description")
+	}
+
+	if renameCmd.Long == "" {
+		t.Errorf("Rename command should have long description")
+	}
+
+	if renameCmd.RunE == nil {
+		t.Errorf("Rename command should have RunE function")
+	}
+
+	if err := renameCmd.Args(renameCmd, []string{}); err == nil {
+		t.Error("Rename should require the new branch name")
+	}
+	if err := renameCmd.Args(renameCmd, []string{"feature/new"}); err != nil {
+		t.Errorf("Rename should accept a single argument: %v", err)
+	}
+}
+
+func TestRenameCommandRegistered(t *testing.T) {
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == "rename" {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		t.Error("Rename command should be registered with root command")
+	}
+}
+
+func TestRenameCommandFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		shorthand string
+		defValue  string
+	}{
+		{name: "worktree", shorthand: "w", defValue: ""},
+		{name: "move", shorthand: "m", defValue: "false"},
+		{name: "no-upstream", shorthand: "", defValue: "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := renameCmd.Flags().Lookup(tt.name)
+			if flag == nil {
+				t.Fatalf("%s flag should exist", tt.name)
+			}
+			if flag.Shorthand != tt.shorthand {
+				t.Errorf("Expected %s shorthand '%s', got '%s'", tt.name, tt.shorthand, flag.Shorthand)
+			}
+			if flag.DefValue != tt.defValue {
+				t.Errorf("Expected %s default '%s', got '%s'", tt.name, tt.defValue, flag.DefValue)
+			}
+		})
+	}
+}
+
+func TestFindRenameTarget(t *testing.T) {
+	root := t.TempDir()
+	worktrees := []worktree.Worktree{
+		{Path: filepath.Join(root, "repo"), Branch: "main"},
+		{Path: filepath.Join(root, "repo", ".worktrees", "feature"), Branch: "feature"},
+	}
+	subdir := filepath.Join(worktrees[1].Path, "pkg", "api")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A subdirectory belongs to the innermost worktree containing it
+	t.Chdir(subdir)
+	wt, err := findRenameTarget(worktrees, "")
+	if err != nil {
+		t.Fatalf("Expected the worktree containing the working directory, got error: %v", err)
+	}
+	if wt.Branch != "feature" {
+		t.Errorf("Expected worktree 'feature', got '%s'", wt.Branch)
+	}
+
+	wt, err = findRenameTarget(worktrees, worktrees[0].Path)
+	if err != nil {
+		t.Fatalf("Expected worktree by path, got error: %v", err)
+	}
+	if wt.Branch != "main" {
+		t.Errorf("Expected worktree 'main', got '%s'", wt.Branch)
+	}
+
+	t.Chdir(root)
+	_, err = findRenameTarget(worktrees, "")
+	if err == nil || !strings.Contains(err.Error(), "--worktree") {
+		t.Errorf("Expected hint about --worktree, got: %v", err)
+	}
+}
@@ -1,13 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/yagi2/yosegi/internal/config"
 	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 // Version information (set by main.go)
@@ -18,12 +22,22 @@ var (
 	builtBy = "unknown"
 )
 
+var (
+	traceGit bool
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "yosegi",
 	Short: "Interactive git worktree management tool",
 	Long: `Yosegi is a CLI tool for managing git worktrees with an interactive interface.
 It provides visual and intuitive commands to create, list, and manage git worktrees.`,
 	Version: getVersionString(),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Log git invocations to stderr so stdout stays usable in scripts
+		if traceGit {
+			worktree.SetDefaultRunner(worktree.NewTraceRunner(worktree.ExecRunner{}, os.Stderr))
+		}
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Use the same functionality as list command
 		return listCmd.RunE(cmd, args)
@@ -55,14 +69,26 @@ func Execute() {
 	cfg, err := config.Load()
 	if err == nil {
 		ui.InitializeTheme(cfg)
+		worktree.SetDefaultTimeout(cfg.Git.CommandTimeout)
+
+		// A broken keybindings section leaves the defaults in place
+		if err := ui.InitializeKeyBindings(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: ignoring keybindings: %v\n", err)
+		}
 	}
 
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl+C cancels the context, which stops any running git process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		stop()
 		os.Exit(1)
 	}
 }
 
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.PersistentFlags().BoolVar(&traceGit, "trace", false, "Log every git command with its duration to stderr")
 }
@@ -153,10 +153,22 @@ func TestRootCommandConfiguration(t *testing.T) {
 
 func TestRootCommandHasSubcommands(t *testing.T) {
 	expectedCommands := []string{
+		"clone",
 		"config",
+		"exec",
 		"list",
+		"lock",
+		"move",
 		"new",
+		"prune",
 		"remove",
+		"rename",
+		"repair",
+		"serve",
+		"sync",
+		"task",
+		"trash",
+		"unlock",
 	}
 
 	commands := rootCmd.Commands()
@@ -387,6 +399,19 @@ func TestRootCommandFlags(t *testing.T) {
 	}
 }
 
+func TestRootCommandTraceFlag(t *testing.T) {
+	flag := rootCmd.PersistentFlags().Lookup("trace")
+	if flag == nil {
+		t.Fatal("Expected persistent --trace flag")
+	}
+	if flag.DefValue != "false" {
+		t.Errorf("Expected --trace to default to false, got %s", flag.DefValue)
+	}
+	if rootCmd.PersistentPreRun == nil {
+		t.Error("Expected root command to install the trace runner before running")
+	}
+}
+
 // Test that the command structure is valid
 func TestCommandStructure(t *testing.T) {
 	// Validate that all commands have proper configuration
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/internal/config"
+	"github.com/yagi2/yosegi/internal/mcp"
+	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var (
+	serveStdio bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve --stdio",
+	Short: "Serve worktree operations to AI coding agents",
+	Long: `Serve worktree operations over JSON-RPC 2.0 for AI coding agents and other tools.
+The server speaks the Model Context Protocol (MCP) and offers the tools list_worktrees,
+create_worktree, remove_worktree, worktree_status and worktree_diff. Plain JSON-RPC
+clients can also call the tools directly as methods.
+
+With --stdio, newline-delimited messages are read from stdin and answered on stdout.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !serveStdio {
+			return fmt.Errorf("no transport selected. Pass --stdio")
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			cfg = &config.Config{DefaultWorktreePath: "../"}
+		}
+
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		options := mcp.Options{
+			Version: version,
+			PathForBranch: func(branch string) string {
+				return ui.WorktreePathForBranch(cfg.DefaultWorktreePath, branch)
+			},
+		}
+		// Removed worktrees' sessions end as with 'yosegi remove'
+		if mux, err := newMultiplexer(cfg.Multiplexer); err == nil {
+			options.EndSession = func(ctx context.Context, wt worktree.Worktree) (string, error) {
+				return endSession(ctx, mux, wt)
+			}
+		}
+
+		server := mcp.NewServer(manager, options)
+		return server.Serve(ctx, os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	serveCmd.Flags().BoolVar(&serveStdio, "stdio", false, "Communicate over stdin and stdout")
+	rootCmd.AddCommand(serveCmd)
+}
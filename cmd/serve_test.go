@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServeCommand(t *testing.T) {
+	if serveCmd.Short == "" || serveCmd.Long == "" {
+		t.Errorf("Serve command should have descriptions")
+	}
+
+	if serveCmd.Flags().Lookup("stdio") == nil {
+		t.Error("Serve command should have a --stdio flag")
+	}
+
+	if err := serveCmd.Args(serveCmd, []string{"extra"}); err == nil {
+		t.Error("Expected positional arguments to be rejected")
+	}
+}
+
+func TestServeCommandRequiresTransport(t *testing.T) {
+	original := serveStdio
+	defer func() { serveStdio = original }()
+
+	serveStdio = false
+	err := serveCmd.RunE(serveCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "--stdio") {
+		t.Errorf("Expected error asking for --stdio, got %v", err)
+	}
+}
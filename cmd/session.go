@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yagi2/yosegi/internal/ui"
+)
+
+// terminal is the session of commands run on their own: questions and forms
+// are standalone dialogs and progress is printed to stdout. The list's app
+// provides its own session, showing them on its screens instead.
+var terminal ui.Session = terminalSession{}
+
+type terminalSession struct{}
+
+// confirmDialog asks a yes or no question in a standalone dialog. It is a
+// variable so tests can answer without a terminal.
+var confirmDialog = func(title, message string) bool {
+	program := tea.NewProgram(ui.NewConfirm(title, message))
+
+	finalModel, err := program.Run()
+	if err != nil {
+		return false
+	}
+
+	result := finalModel.(ui.ConfirmModel).GetResult()
+	return !result.Cancelled && result.Confirmed
+}
+
+func (terminalSession) Confirm(title, message string) bool {
+	return confirmDialog(title, message)
+}
+
+func (terminalSession) Form(form ui.InputModel) (ui.InputResult, error) {
+	finalModel, err := tea.NewProgram(form).Run()
+	if err != nil {
+		return ui.InputResult{}, fmt.Errorf("failed to run interactive interface: %w", err)
+	}
+	return finalModel.(ui.InputModel).GetResult(), nil
+}
+
+func (terminalSession) Report(message string) {
+	fmt.Println(message)
+}
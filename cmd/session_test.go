@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestTerminalSession(t *testing.T) {
+	originalDialog := confirmDialog
+	defer func() { confirmDialog = originalDialog }()
+
+	var asked string
+	confirmDialog = func(title, message string) bool {
+		asked = title + ": " + message
+		return true
+	}
+	if !terminal.Confirm("Remove Worktree", "Are you sure?") || asked != "Remove Worktree: Are you sure?" {
+		t.Errorf("Expected the question to go to the dialog, got %q", asked)
+	}
+
+	// Reports are printed to stdout
+	originalStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	terminal.Report("Removed /repo/feature")
+	_ = w.Close()
+	os.Stdout = originalStdout
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	if buf.String() != "Removed /repo/feature\n" {
+		t.Errorf("Expected the report on stdout, got %q", buf.String())
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/internal/config"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var (
+	syncRebase bool
+	syncBase   string
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch and fast-forward all worktrees",
+	Long: `Fetch once from git.default_remote, then fast-forward each worktree's branch to its
+upstream when the worktree is clean and only behind. With --rebase, each branch is also
+rebased onto --base (the remote's default branch unless given). Worktrees with uncommitted
+changes, diverged branches and conflicting rebases are left untouched and reported, and so
+are locked worktrees.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			cfg = &config.Config{}
+		}
+		remote := cfg.Git.DefaultRemote
+		if remote == "" {
+			remote = "origin"
+		}
+
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		fmt.Printf("Fetching from '%s'...\n", remote)
+		if err := manager.Fetch(ctx, remote); err != nil {
+			return err
+		}
+
+		var opts worktree.SyncOptions
+		if syncRebase {
+			opts.RebaseOnto = syncBase
+			if opts.RebaseOnto == "" {
+				if opts.RebaseOnto, err = manager.DefaultBranch(ctx, remote); err != nil {
+					return fmt.Errorf("%w, or pass --base", err)
+				}
+			}
+		}
+
+		worktrees, err := manager.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+
+		rows, failed := syncWorktrees(ctx, manager, listedWorktrees(worktrees), opts)
+		if err := printSyncSummary(os.Stdout, rows, opts.RebaseOnto); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to sync %d of %d worktrees", failed, len(rows))
+		}
+		return nil
+	},
+}
+
+// syncWorktrees syncs each worktree in turn, returning the outcomes and how
+// many failed. Locked worktrees are left untouched.
+func syncWorktrees(ctx context.Context, manager worktree.Manager, worktrees []worktree.Worktree, opts worktree.SyncOptions) ([]syncRow, int) {
+	var rows []syncRow
+	failed := 0
+	for _, wt := range worktrees {
+		if wt.Locked {
+			rows = append(rows, syncRow{Worktree: wt, Result: &worktree.SyncResult{Outcome: worktree.SyncLocked}})
+			continue
+		}
+
+		result, err := manager.Sync(ctx, wt.Path, opts)
+		if err != nil {
+			failed++
+		}
+		rows = append(rows, syncRow{Worktree: wt, Result: result, Err: err})
+	}
+	return rows, failed
+}
+
+// syncRow is the outcome of syncing one worktree
+type syncRow struct {
+	Worktree worktree.Worktree
+	Result   *worktree.SyncResult
+	Err      error
+}
+
+// printSyncSummary writes a table with the outcome for each worktree
+func printSyncSummary(out io.Writer, rows []syncRow, base string) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "BRANCH\tRESULT\tDETAIL\tPATH")
+	for _, row := range rows {
+		outcome, detail := describeSync(row, base)
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", execLabel(row.Worktree), outcome, detail, row.Worktree.Path)
+	}
+	return w.Flush()
+}
+
+// describeSync returns the result column and an explanation for a row
+func describeSync(row syncRow, base string) (string, string) {
+	if row.Err != nil {
+		return "error", row.Err.Error()
+	}
+
+	result := row.Result
+	switch result.Outcome {
+	case worktree.SyncUpdated:
+		detail := fmt.Sprintf("pulled %d commit(s)", result.Pulled)
+		switch {
+		case result.Rebased && result.Pulled > 0:
+			detail += ", rebased onto " + base
+		case result.Rebased:
+			detail = "rebased onto " + base
+		}
+		return "updated", detail
+	case worktree.SyncUpToDate:
+		return "up to date", ""
+	case worktree.SyncDirty:
+		return "skipped", "uncommitted changes"
+	case worktree.SyncDiverged:
+		return "diverged", "local and upstream commits; merge or rebase by hand"
+	case worktree.SyncConflict:
+		return "conflict", "rebase onto " + base + " conflicts; aborted"
+	case worktree.SyncNoUpstream:
+		return "skipped", "no upstream branch"
+	case worktree.SyncDetached:
+		return "skipped", "detached HEAD"
+	case worktree.SyncLocked:
+		if row.Worktree.LockReason != "" {
+			return "locked", row.Worktree.LockReason
+		}
+		return "locked", "unlock to sync"
+	}
+	return string(result.Outcome), ""
+}
+
+func init() {
+	syncCmd.Flags().BoolVar(&syncRebase, "rebase", false, "Also rebase each branch onto the base branch")
+	syncCmd.Flags().StringVar(&syncBase, "base", "", "Branch to rebase onto (default: the remote's default branch, e.g. origin/main)")
+	rootCmd.AddCommand(syncCmd)
+}
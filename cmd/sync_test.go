@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
+)
+
+func TestSyncCommand(t *testing.T) {
+	if syncCmd.Short == "" || syncCmd.Long == "" {
+		t.Errorf("Sync command should have descriptions")
+	}
+	if syncCmd.Flags().Lookup("rebase") == nil || syncCmd.Flags().Lookup("base") == nil {
+		t.Error("Sync command should have --rebase and --base flags")
+	}
+	if err := syncCmd.Args(syncCmd, []string{"extra"}); err == nil {
+		t.Error("Expected positional arguments to be rejected")
+	}
+}
+
+func TestDescribeSync(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     syncRow
+		outcome string
+		detail  string
+	}{
+		{
+			name:    "Fast-forwarded",
+			row:     syncRow{Result: &worktree.SyncResult{Outcome: worktree.SyncUpdated, Pulled: 3}},
+			outcome: "updated",
+			detail:  "pulled 3 commit(s)",
+		},
+		{
+			name:    "Fast-forwarded and rebased",
+			row:     syncRow{Result: &worktree.SyncResult{Outcome: worktree.SyncUpdated, Pulled: 1, Rebased: true}},
+			outcome: "updated",
+			detail:  "pulled 1 commit(s), rebased onto origin/main",
+		},
+		{
+			name:    "Rebased",
+			row:     syncRow{Result: &worktree.SyncResult{Outcome: worktree.SyncUpdated, Rebased: true}},
+			outcome: "updated",
+			detail:  "rebased onto origin/main",
+		},
+		{
+			name:    "Dirty",
+			row:     syncRow{Result: &worktree.SyncResult{Outcome: worktree.SyncDirty}},
+			outcome: "skipped",
+			detail:  "uncommitted changes",
+		},
+		{
+			name:    "Diverged",
+			row:     syncRow{Result: &worktree.SyncResult{Outcome: worktree.SyncDiverged}},
+			outcome: "diverged",
+		},
+		{
+			name:    "Locked",
+			row:     syncRow{Worktree: worktree.Worktree{LockReason: "agent running"}, Result: &worktree.SyncResult{Outcome: worktree.SyncLocked}},
+			outcome: "locked",
+			detail:  "agent running",
+		},
+		{
+			name:    "Error",
+			row:     syncRow{Err: errors.New("boom")},
+			outcome: "error",
+			detail:  "boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, detail := describeSync(tt.row, "origin/main")
+			if outcome != tt.outcome {
+				t.Errorf("Expected outcome %q, got %q", tt.outcome, outcome)
+			}
+			if tt.detail != "" && detail != tt.detail {
+				t.Errorf("Expected detail %q, got %q", tt.detail, detail)
+			}
+		})
+	}
+}
+
+func TestSyncWorktreesSkipsLocked(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("status").Return("# branch.head feature\n# branch.upstream origin/feature\n# branch.ab +0 -1\n")
+	runner.On("merge", "--ff-only").Return("")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+	worktrees := []worktree.Worktree{
+		{Path: "/work/feature", Branch: "feature"},
+		{Path: "/work/locked", Branch: "locked", Locked: true},
+	}
+	rows, failed := syncWorktrees(context.Background(), manager, worktrees, worktree.SyncOptions{})
+	if failed != 0 || len(rows) != 2 {
+		t.Fatalf("Expected two rows without failures, got %+v, %d", rows, failed)
+	}
+	if rows[0].Result.Outcome != worktree.SyncUpdated || rows[1].Result.Outcome != worktree.SyncLocked {
+		t.Errorf("Expected feature updated and the locked worktree skipped, got %s and %s", rows[0].Result.Outcome, rows[1].Result.Outcome)
+	}
+	for _, call := range runner.Calls() {
+		if strings.Contains(strings.Join(call.Args, " "), "/work/locked") || call.Dir == "/work/locked" {
+			t.Errorf("Expected no git command in the locked worktree, got %+v", call)
+		}
+	}
+}
+
+func TestPrintSyncSummary(t *testing.T) {
+	rows := []syncRow{
+		{Worktree: worktree.Worktree{Path: "/repo", Branch: "main"}, Result: &worktree.SyncResult{Outcome: worktree.SyncUpToDate}},
+		{Worktree: worktree.Worktree{Path: "/feature", Branch: "feature"}, Result: &worktree.SyncResult{Outcome: worktree.SyncNoUpstream}},
+	}
+
+	var out bytes.Buffer
+	if err := printSyncSummary(&out, rows, ""); err != nil {
+		t.Fatalf("printSyncSummary failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "BRANCH") {
+		t.Fatalf("Unexpected summary:\n%s", out.String())
+	}
+	if !strings.Contains(lines[1], "up to date") || !strings.Contains(lines[2], "no upstream branch") {
+		t.Errorf("Unexpected summary:\n%s", out.String())
+	}
+}
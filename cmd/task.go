@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/internal/config"
+	"github.com/yagi2/yosegi/internal/task"
+	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var (
+	taskBase       string
+	taskPath       string
+	discardConfirm bool
+)
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Run commands in dedicated worktrees",
+	Long: `Run a command, such as an AI coding agent, in a worktree of its own and review the result.
+Each task gets a branch named task/<name>. Its output is logged, and whatever it leaves
+behind is committed so it can be accepted (merged) or discarded.`,
+}
+
+var taskStartCmd = &cobra.Command{
+	Use:   "start <name> -- <command> [args...]",
+	Short: "Start a task in a new worktree",
+	Long: `Create a worktree on the branch task/<name> from --base (HEAD by default) and run the
+command inside it. Output is shown and saved to the task's log. When the command exits,
+remaining changes are committed and the exit status and diff stats are recorded.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		dash := cmd.ArgsLenAtDash()
+		if dash != 1 {
+			return fmt.Errorf("usage: yosegi task start <name> -- <command> [args...]")
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("a command to run is required after --")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		path := taskPath
+		if path == "" {
+			cfg, err := config.Load()
+			if err != nil {
+				cfg = &config.Config{DefaultWorktreePath: "../"}
+			}
+			path = ui.WorktreePathForBranch(cfg.DefaultWorktreePath, task.BranchPrefix+name)
+		}
+
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		fmt.Printf("Starting task '%s' in '%s'...\n", name, path)
+		t, err := task.Start(ctx, manager, task.StartOptions{
+			Name:    name,
+			Base:    taskBase,
+			Path:    path,
+			Command: args[1:],
+			Stdout:  os.Stdout,
+			Stderr:  os.Stderr,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println()
+		printTaskSummary(t)
+		if t.State == task.StateFailed {
+			return fmt.Errorf("task '%s' failed (see: yosegi task show %s)", t.Name, t.Name)
+		}
+		fmt.Printf("✅ Task '%s' finished. Review it, then run 'yosegi task accept %s' or 'yosegi task discard %s'\n", t.Name, t.Name, t.Name)
+		return nil
+	},
+}
+
+var taskListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List tasks",
+	Long:    "Display all tasks with their state, exit code and changes, oldest first.",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		tasks, err := task.List(ctx, manager)
+		if err != nil {
+			return err
+		}
+
+		if len(tasks) == 0 {
+			fmt.Println("No tasks found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "NAME\tSTATE\tEXIT\tCHANGES\tSTARTED\tPATH")
+		for _, t := range tasks {
+			exit := "-"
+			if t.State != task.StateRunning {
+				exit = fmt.Sprintf("%d", t.ExitCode)
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.Name, t.State, exit, formatTaskChanges(&t), t.StartedAt.Format("2006-01-02 15:04"), t.Path)
+		}
+		return w.Flush()
+	},
+}
+
+var taskShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a task and its log",
+	Long:  "Display the details of a task followed by the output its command produced.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		t, err := task.Find(ctx, manager, args[0])
+		if err != nil {
+			return err
+		}
+
+		printTaskSummary(t)
+		fmt.Printf("Log:      %s\n", t.LogPath())
+
+		log, err := os.ReadFile(t.LogPath())
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("failed to read task log: %w", err)
+		}
+		if len(log) > 0 {
+			fmt.Println()
+			fmt.Print(string(log))
+		}
+		return nil
+	},
+}
+
+var taskAcceptCmd = &cobra.Command{
+	Use:   "accept <name>",
+	Short: "Merge a task into the current branch",
+	Long: `Merge the task's branch into the branch checked out in the current worktree, then
+remove the task's worktree and branch. A merge that conflicts is aborted and the task is kept.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		t, err := task.Find(ctx, manager, args[0])
+		if err != nil {
+			return err
+		}
+
+		worktrees, err := manager.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+		var current *worktree.Worktree
+		for i := range worktrees {
+			if worktrees[i].IsCurrent {
+				current = &worktrees[i]
+				break
+			}
+		}
+		if current == nil {
+			return fmt.Errorf("run 'yosegi task accept' from the worktree to merge into")
+		}
+		if current.Path == t.Path {
+			return fmt.Errorf("cannot accept a task from its own worktree. Run this from the worktree to merge into")
+		}
+
+		fmt.Printf("Merging '%s' into '%s'...\n", t.Branch, current.Branch)
+		if err := task.Accept(ctx, manager, t, current.Path); err != nil {
+			if errors.Is(err, worktree.ErrMergeConflict) {
+				return fmt.Errorf("%w. Resolve it by hand in the task worktree at '%s', or discard the task", err, t.Path)
+			}
+			return err
+		}
+
+		fmt.Printf("✅ Accepted task '%s'\n", t.Name)
+		killSession(ctx, terminal, worktree.Worktree{Path: t.Path, Branch: t.Branch})
+		return nil
+	},
+}
+
+var taskDiscardCmd = &cobra.Command{
+	Use:   "discard <name>",
+	Short: "Throw away a task",
+	Long:  "Remove the task's worktree and branch, dropping everything the task changed.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		t, err := task.Find(ctx, manager, args[0])
+		if err != nil {
+			return err
+		}
+
+		if !discardConfirm {
+			confirmModel := ui.NewConfirm(
+				"Confirm Discard",
+				fmt.Sprintf("Discard task '%s' and all of its changes?", t.Name),
+			)
+			program := tea.NewProgram(confirmModel)
+
+			finalModel, err := program.Run()
+			if err != nil {
+				return fmt.Errorf("failed to run confirmation dialog: %w", err)
+			}
+
+			result := finalModel.(ui.ConfirmModel).GetResult()
+			if result.Cancelled || !result.Confirmed {
+				fmt.Println("Discard cancelled")
+				return nil
+			}
+		}
+
+		if err := task.Discard(ctx, manager, t); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Discarded task '%s'\n", t.Name)
+		killSession(ctx, terminal, worktree.Worktree{Path: t.Path, Branch: t.Branch})
+		return nil
+	},
+}
+
+// printTaskSummary prints the recorded outcome of a task
+func printTaskSummary(t *task.Task) {
+	fmt.Printf("Task:     %s (%s)\n", t.Name, t.State)
+	fmt.Printf("Branch:   %s\n", t.Branch)
+	fmt.Printf("Path:     %s\n", t.Path)
+	fmt.Printf("Base:     %s (%s)\n", t.Base, shortCommit(t.BaseCommit))
+	fmt.Printf("Command:  %s\n", formatCommand(t.Command))
+	if t.FinishedAt != nil {
+		fmt.Printf("Exit:     %d after %s\n", t.ExitCode, t.FinishedAt.Sub(t.StartedAt).Round(time.Second))
+	}
+	fmt.Printf("Changes:  %s\n", formatTaskChanges(t))
+	if t.Error != "" {
+		fmt.Printf("Error:    %s\n", t.Error)
+	}
+}
+
+// formatTaskChanges summarizes a task's diff stats, e.g. "3 files +10 -2"
+func formatTaskChanges(t *task.Task) string {
+	if t.FilesChanged == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d files +%d -%d", t.FilesChanged, t.Insertions, t.Deletions)
+}
+
+// formatCommand joins a command's arguments, quoting those containing spaces
+func formatCommand(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		if arg == "" || strings.ContainsAny(arg, " \t\n") {
+			arg = fmt.Sprintf("%q", arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+func shortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+func init() {
+	taskStartCmd.Flags().StringVarP(&taskBase, "base", "b", "", "Ref to start the task from (default HEAD)")
+	taskStartCmd.Flags().StringVarP(&taskPath, "path", "p", "", "Directory for the task's worktree")
+	taskDiscardCmd.Flags().BoolVarP(&discardConfirm, "yes", "y", false, "Discard without asking for confirmation")
+
+	taskCmd.AddCommand(taskStartCmd)
+	taskCmd.AddCommand(taskListCmd)
+	taskCmd.AddCommand(taskShowCmd)
+	taskCmd.AddCommand(taskAcceptCmd)
+	taskCmd.AddCommand(taskDiscardCmd)
+	rootCmd.AddCommand(taskCmd)
+}
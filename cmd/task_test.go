@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/internal/task"
+)
+
+func TestTaskCommand(t *testing.T) {
+	if taskCmd.Short == "" || taskCmd.Long == "" {
+		t.Errorf("Task command should have descriptions")
+	}
+
+	expected := map[string]bool{"start": false, "list": false, "show": false, "accept": false, "discard": false}
+	for _, sub := range taskCmd.Commands() {
+		if _, ok := expected[sub.Name()]; ok {
+			expected[sub.Name()] = true
+		}
+	}
+	for name, found := range expected {
+		if !found {
+			t.Errorf("Expected task subcommand '%s'", name)
+		}
+	}
+
+	if taskStartCmd.Flags().Lookup("base") == nil || taskStartCmd.Flags().Lookup("path") == nil {
+		t.Error("Task start should have --base and --path flags")
+	}
+	if taskDiscardCmd.Flags().Lookup("yes") == nil {
+		t.Error("Task discard should have a --yes flag")
+	}
+}
+
+func TestTaskStartArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "Name and command", args: []string{"fix", "--", "make", "test"}},
+		{name: "Missing separator", args: []string{"fix", "make"}, expected: "usage"},
+		{name: "Missing name", args: []string{"--", "make"}, expected: "usage"},
+		{name: "Missing command", args: []string{"fix", "--"}, expected: "command to run is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := *taskStartCmd
+			cmd.ResetFlags()
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("Failed to parse arguments: %v", err)
+			}
+
+			err := cmd.Args(&cmd, cmd.Flags().Args())
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Expected arguments to be accepted, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestFormatTaskChanges(t *testing.T) {
+	if got := formatTaskChanges(&task.Task{}); got != "none" {
+		t.Errorf("Expected 'none', got %q", got)
+	}
+	if got := formatTaskChanges(&task.Task{FilesChanged: 3, Insertions: 10, Deletions: 2}); got != "3 files +10 -2" {
+		t.Errorf("Expected '3 files +10 -2', got %q", got)
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	got := formatCommand([]string{"sh", "-c", "echo hi", ""})
+	if got != `sh -c "echo hi" ""` {
+		t.Errorf("Unexpected formatted command: %s", got)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/internal/config"
+	"github.com/yagi2/yosegi/internal/launch"
+	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// newMultiplexer returns the multiplexer for a multiplexer setting. It is a
+// variable so tests can run without tmux.
+var newMultiplexer = launch.NewMultiplexer
+
+var tmuxCmd = &cobra.Command{
+	Use:     "tmux [query]",
+	Aliases: []string{"mux"},
+	Short:   "Attach to a worktree's tmux or zellij session",
+	Long: `Attach to the terminal multiplexer session of a worktree, creating it in the worktree's
+directory when it is not running. Sessions are named after the branch, with anything but
+letters, digits, '-' and '_' replaced by '-' and then a short hash of the branch added.
+Set 'multiplexer' in the configuration to tmux, the default, or zellij.
+
+The query picks the worktree as in 'yosegi open'. 'yosegi list' marks worktrees whose
+session is running, and removing a worktree ends its session.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mux, err := multiplexer()
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		selected, err := resolveWorktree(ctx, manager, args, "Attach to Session", "attach")
+		if err != nil || selected == nil {
+			return err
+		}
+
+		recordChoice(manager, *selected)
+		return attachSession(ctx, mux, *selected)
+	},
+}
+
+// multiplexer returns the multiplexer set in the configuration
+func multiplexer() (launch.Multiplexer, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+	return newMultiplexer(cfg.Multiplexer)
+}
+
+// attachSession attaches the terminal to wt's session until it detaches
+func attachSession(ctx context.Context, mux launch.Multiplexer, wt worktree.Worktree) error {
+	cmd, err := mux.Attach(ctx, launch.SessionName(wt), wt.Path)
+	if err != nil {
+		return err
+	}
+
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", mux.Name(), err)
+	}
+	return nil
+}
+
+// worktreeSessions returns how the list finds the running sessions. An
+// unknown multiplexer is reported and no sessions are shown.
+func worktreeSessions(ctx context.Context) ui.SessionsFunc {
+	mux, err := multiplexer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: %v\n", err)
+		return nil
+	}
+	return func() (map[string]bool, error) {
+		return mux.Sessions(ctx)
+	}
+}
+
+// killSession ends the session of a removed worktree if it is running.
+// Failing to end it is reported but does not fail the removal.
+func killSession(ctx context.Context, s ui.Session, wt worktree.Worktree) {
+	mux, err := multiplexer()
+	if err != nil {
+		return
+	}
+
+	name, err := endSession(ctx, mux, wt)
+	if err != nil {
+		s.Report(fmt.Sprintf("⚠️  Warning: %v", err))
+		return
+	}
+	if name != "" {
+		s.Report(fmt.Sprintf("Ended %s session '%s'", mux.Name(), name))
+	}
+}
+
+// endSession ends wt's session and returns its name, or an empty name when
+// the session is not running
+func endSession(ctx context.Context, mux launch.Multiplexer, wt worktree.Worktree) (string, error) {
+	name := launch.SessionName(wt)
+	sessions, err := mux.Sessions(ctx)
+	if err != nil || !sessions[name] {
+		return "", nil
+	}
+
+	if err := mux.Kill(ctx, name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func init() {
+	rootCmd.AddCommand(tmuxCmd)
+}
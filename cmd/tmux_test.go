@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/internal/launch"
+	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// fakeMultiplexer has the sessions it is given, recording what is killed
+type fakeMultiplexer struct {
+	sessions map[string]bool
+	attach   string // command returned by Attach
+	killErr  error
+	killed   []string
+}
+
+func (f *fakeMultiplexer) Name() string { return "tmux" }
+
+func (f *fakeMultiplexer) Sessions(ctx context.Context) (map[string]bool, error) {
+	return f.sessions, nil
+}
+
+func (f *fakeMultiplexer) Attach(ctx context.Context, name, dir string) (*exec.Cmd, error) {
+	return exec.Command(f.attach), nil
+}
+
+func (f *fakeMultiplexer) Kill(ctx context.Context, name string) error {
+	f.killed = append(f.killed, name)
+	return f.killErr
+}
+
+// useMultiplexer makes the commands use mux until the test ends
+func useMultiplexer(t *testing.T, mux launch.Multiplexer, err error) {
+	t.Helper()
+	original := newMultiplexer
+	t.Cleanup(func() { newMultiplexer = original })
+	newMultiplexer = func(string) (launch.Multiplexer, error) { return mux, err }
+}
+
+// reportedSession records what is reported to it
+type reportedSession struct {
+	ui.Session
+	reports []string
+}
+
+func (s *reportedSession) Report(message string) {
+	s.reports = append(s.reports, message)
+}
+
+func TestKillSession(t *testing.T) {
+	wt := worktree.Worktree{Path: "/work/login", Branch: "feature/login"}
+	mux := &fakeMultiplexer{sessions: map[string]bool{"feature-login-df7c7a": true}}
+	useMultiplexer(t, mux, nil)
+
+	s := &reportedSession{}
+	killSession(context.Background(), s, wt)
+	if len(mux.killed) != 1 || mux.killed[0] != "feature-login-df7c7a" {
+		t.Errorf("Expected the worktree's session to be killed, got %v", mux.killed)
+	}
+	if len(s.reports) != 1 || s.reports[0] != "Ended tmux session 'feature-login-df7c7a'" {
+		t.Errorf("Expected the session's end to be reported, got %v", s.reports)
+	}
+
+	// Sessions that are not running are left alone
+	mux.killed = nil
+	killSession(context.Background(), s, worktree.Worktree{Path: "/work/fix", Branch: "fix/typo"})
+	if len(mux.killed) != 0 {
+		t.Errorf("Expected no session to be killed, got %v", mux.killed)
+	}
+
+	// A failure is only a warning
+	mux.killErr = errors.New("failed to kill tmux session 'feature-login-df7c7a'")
+	s = &reportedSession{}
+	killSession(context.Background(), s, wt)
+	if len(s.reports) != 1 || !strings.Contains(s.reports[0], "Warning: failed to kill") {
+		t.Errorf("Expected a warning, got %v", s.reports)
+	}
+}
+
+func TestAttachSession(t *testing.T) {
+	wt := worktree.Worktree{Path: t.TempDir(), Branch: "feature/login"}
+
+	if err := attachSession(context.Background(), &fakeMultiplexer{attach: "true"}, wt); err != nil {
+		t.Errorf("Expected attaching to succeed, got %v", err)
+	}
+	if err := attachSession(context.Background(), &fakeMultiplexer{attach: "false"}, wt); err == nil || !strings.Contains(err.Error(), "tmux failed") {
+		t.Errorf("Expected the multiplexer's failure, got %v", err)
+	}
+}
+
+func TestWorktreeSessions(t *testing.T) {
+	mux := &fakeMultiplexer{sessions: map[string]bool{"main": true}}
+	useMultiplexer(t, mux, nil)
+
+	sessions, err := worktreeSessions(context.Background())()
+	if err != nil || !sessions["main"] {
+		t.Errorf("Expected the multiplexer's sessions, got %v, %v", sessions, err)
+	}
+
+	useMultiplexer(t, nil, errors.New("unknown multiplexer 'screen' (expected tmux or zellij)"))
+	if fn := worktreeSessions(context.Background()); fn != nil {
+		t.Error("Expected no sessions with an unknown multiplexer")
+	}
+}
+
+func TestTmuxCommandRegistered(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"mux"})
+	if err != nil || cmd != tmuxCmd {
+		t.Errorf("Expected mux to run the tmux command, got %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yagi2/yosegi/internal/config"
+	"github.com/yagi2/yosegi/internal/ui"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var (
+	restorePath    string
+	purgeOlderThan string
+	purgeAll       bool
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage removed worktree snapshots",
+	Long: `List, restore, or purge worktrees that were removed with --trash.
+Each snapshot keeps the branch tip and all uncommitted changes under refs/yosegi/trash/.`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List trashed worktrees",
+	Long:    "Display all worktree snapshots stored in the trash, newest first.",
+	Aliases: []string{"ls"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		entries, err := manager.ListTrash(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("Trash is empty")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "ID\tREMOVED\tBRANCH\tPATH")
+		for _, entry := range entries {
+			branch := entry.Branch
+			if branch == "" {
+				branch = "(detached)"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.ID, entry.CreatedAt.Format("2006-01-02 15:04"), branch, entry.Path)
+		}
+		return w.Flush()
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Restore a trashed worktree",
+	Long:  "Recreate a trashed worktree at its original path (or --path) and reapply its uncommitted changes.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		fmt.Printf("Restoring '%s'...\n", args[0])
+		entry, err := manager.RestoreTrash(ctx, args[0], restorePath)
+		if err != nil {
+			return fmt.Errorf("failed to restore worktree: %w", err)
+		}
+
+		path := restorePath
+		if path == "" {
+			path = entry.Path
+		}
+		fmt.Printf("✅ Successfully restored worktree at '%s'\n", path)
+		return nil
+	},
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently delete trashed worktrees",
+	Long:  "Permanently delete trashed worktree snapshots older than the given age, or all of them with --all.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if purgeOlderThan == "" && !purgeAll {
+			return fmt.Errorf("specify --older-than <age> or --all")
+		}
+
+		var maxAge time.Duration
+		if purgeOlderThan != "" {
+			age, err := parseAge(purgeOlderThan)
+			if err != nil {
+				return err
+			}
+			maxAge = age
+		}
+
+		ctx := cmd.Context()
+		manager, err := worktree.NewManager(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git manager: %w", err)
+		}
+
+		entries, err := manager.ListTrash(ctx)
+		if err != nil {
+			return err
+		}
+
+		cutoff := time.Now().Add(-maxAge)
+		purged := 0
+		for _, entry := range entries {
+			if !purgeAll && entry.CreatedAt.After(cutoff) {
+				continue
+			}
+			if err := manager.DeleteTrash(ctx, entry.ID); err != nil {
+				return err
+			}
+			purged++
+		}
+
+		fmt.Printf("✅ Purged %d trashed worktree(s)\n", purged)
+		return nil
+	},
+}
+
+// parseAge parses a duration that additionally accepts day (d) and week (w) units
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, found := strings.CutSuffix(value, suffix); found {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age '%s'", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age '%s'", value)
+	}
+	return age, nil
+}
+
+// shouldTrashOnRemove reports whether removed worktrees should be snapshotted first
+func shouldTrashOnRemove() bool {
+	if trashOnRemove {
+		return true
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return false
+	}
+	return cfg.Git.TrashOnRemove
+}
+
+// trashWorktree snapshots a worktree into the trash before it is removed
+func trashWorktree(ctx context.Context, s ui.Session, manager worktree.Manager, wt worktree.Worktree) (*worktree.TrashEntry, error) {
+	s.Report(fmt.Sprintf("Saving worktree at '%s' to trash...", wt.Path))
+	entry, err := manager.Trash(ctx, wt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save worktree to trash: %w", err)
+	}
+	s.Report(fmt.Sprintf("🗑️  Saved as '%s' (restore with: yosegi trash restore %s)", entry.ID, entry.ID))
+	return entry, nil
+}
+
+// dropSnapshot deletes the snapshot of a worktree whose removal failed, so
+// that the trash does not offer to restore a worktree that still exists
+func dropSnapshot(ctx context.Context, s ui.Session, manager worktree.Manager, entry *worktree.TrashEntry) {
+	if entry == nil {
+		return
+	}
+	if err := manager.DeleteTrash(ctx, entry.ID); err != nil {
+		s.Report(fmt.Sprintf("⚠️  Warning: %v", err))
+		return
+	}
+	s.Report(fmt.Sprintf("Dropped snapshot '%s' since the worktree was kept", entry.ID))
+}
+
+func init() {
+	trashRestoreCmd.Flags().StringVarP(&restorePath, "path", "p", "", "Restore the worktree at a different path")
+	trashPurgeCmd.Flags().StringVar(&purgeOlderThan, "older-than", "", "Only purge snapshots older than this age (e.g. 72h, 30d, 2w)")
+	trashPurgeCmd.Flags().BoolVar(&purgeAll, "all", false, "Purge every snapshot")
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+	rootCmd.AddCommand(trashCmd)
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
+)
+
+func TestTrashCommand(t *testing.T) {
+	if trashCmd.Use != "trash" {
+		t.Errorf("Expected trash command use to be 'trash', got '%s'", trashCmd.Use)
+	}
+
+	if trashCmd.Short == "" {
+		t.Errorf("Trash command should have short description")
+	}
+
+	if trashCmd.Long == "" {
+		t.Errorf("Trash command should have long description")
+	}
+
+	expectedSubcommands := []string{"list", "restore", "purge"}
+	for _, expected := range expectedSubcommands {
+		found := false
+		for _, sub := range trashCmd.Commands() {
+			if sub.Name() == expected {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected trash subcommand '%s' to be registered", expected)
+		}
+	}
+}
+
+func TestTrashCommandRegistered(t *testing.T) {
+	found := false
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == "trash" {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		t.Error("Trash command should be registered with root command")
+	}
+}
+
+func TestTrashSubcommandStructure(t *testing.T) {
+	if trashListCmd.RunE == nil || trashRestoreCmd.RunE == nil || trashPurgeCmd.RunE == nil {
+		t.Error("Trash subcommands should have RunE functions")
+	}
+
+	if len(trashListCmd.Aliases) != 1 || trashListCmd.Aliases[0] != "ls" {
+		t.Errorf("Expected trash list alias 'ls', got %v", trashListCmd.Aliases)
+	}
+
+	if err := trashRestoreCmd.Args(trashRestoreCmd, []string{}); err == nil {
+		t.Error("Trash restore should require an id argument")
+	}
+	if err := trashRestoreCmd.Args(trashRestoreCmd, []string{"id"}); err != nil {
+		t.Errorf("Trash restore should accept a single id argument: %v", err)
+	}
+}
+
+func TestTrashCommandFlags(t *testing.T) {
+	pathFlag := trashRestoreCmd.Flags().Lookup("path")
+	if pathFlag == nil {
+		t.Error("restore should have path flag")
+	} else if pathFlag.Shorthand != "p" {
+		t.Errorf("Expected path flag shorthand to be 'p', got '%s'", pathFlag.Shorthand)
+	}
+
+	if trashPurgeCmd.Flags().Lookup("older-than") == nil {
+		t.Error("purge should have older-than flag")
+	}
+	if trashPurgeCmd.Flags().Lookup("all") == nil {
+		t.Error("purge should have all flag")
+	}
+}
+
+func TestTrashPurgeRequiresSelection(t *testing.T) {
+	purgeOlderThan = ""
+	purgeAll = false
+
+	err := trashPurgeCmd.RunE(trashPurgeCmd, []string{})
+	if err == nil {
+		t.Fatal("Expected error when neither --older-than nor --all is given")
+	}
+	if !strings.Contains(err.Error(), "--older-than") {
+		t.Errorf("Expected hint about --older-than, got: %v", err)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{input: "72h", expected: 72 * time.Hour},
+		{input: "30m", expected: 30 * time.Minute},
+		{input: "30d", expected: 30 * 24 * time.Hour},
+		{input: "2w", expected: 14 * 24 * time.Hour},
+		{input: " 1d ", expected: 24 * time.Hour},
+		{input: "", expectError: true},
+		{input: "abc", expectError: true},
+		{input: "xd", expectError: true},
+		{input: "-1d", expectError: true},
+		{input: "-5h", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			age, err := parseAge(tt.input)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseAge(%q) error = %v, expectError %v", tt.input, err, tt.expectError)
+			}
+			if !tt.expectError && age != tt.expected {
+				t.Errorf("parseAge(%q) = %v, expected %v", tt.input, age, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFailedRemovalDropsSnapshot(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	answerRecovery(t, true)
+	original := trashOnRemove
+	trashOnRemove = true
+	t.Cleanup(func() { trashOnRemove = original })
+
+	runner := worktreetest.NewRunner()
+	runner.On("rev-parse", "HEAD").Return("1111111111111111111111111111111111111111\n")
+	runner.On("read-tree")
+	runner.On("add", "--all")
+	runner.On("write-tree").Return("2222222222222222222222222222222222222222\n")
+	runner.On("commit-tree").Return("3333333333333333333333333333333333333333\n")
+	runner.On("update-ref")
+	runner.On("worktree", "remove").Fail("fatal: cannot remove a locked working tree")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+	wt := worktree.Worktree{Path: t.TempDir(), Branch: "feature"}
+	if err := runRemoveWithSelectedWorktree(context.Background(), terminal, manager, wt); err == nil {
+		t.Fatal("Expected the removal to fail")
+	}
+
+	var deleted bool
+	for _, call := range runner.Calls() {
+		if len(call.Args) == 3 && call.Args[0] == "update-ref" && call.Args[1] == "-d" && strings.HasPrefix(call.Args[2], "refs/yosegi/trash/") {
+			deleted = true
+		}
+	}
+	if !deleted {
+		t.Errorf("Expected the snapshot ref to be deleted, got calls %+v", runner.Calls())
+	}
+}
@@ -4,15 +4,19 @@ import (
 	"gopkg.in/yaml.v3"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // Config represents the application configuration
 type Config struct {
-	DefaultWorktreePath string            `yaml:"default_worktree_path"`
-	Theme               ThemeConfig       `yaml:"theme"`
-	Git                 GitConfig         `yaml:"git"`
-	UI                  UIConfig          `yaml:"ui"`
-	Aliases             map[string]string `yaml:"aliases"`
+	DefaultWorktreePath string              `yaml:"default_worktree_path"`
+	Theme               ThemeConfig         `yaml:"theme"`
+	Git                 GitConfig           `yaml:"git"`
+	UI                  UIConfig            `yaml:"ui"`
+	Aliases             map[string]string   `yaml:"aliases"`
+	Editor              string              `yaml:"editor,omitempty"`      // command opening a worktree, such as "code {{.Path}}"
+	Multiplexer         string              `yaml:"multiplexer,omitempty"` // tmux or zellij, for worktree sessions
+	KeyBindings         map[string][]string `yaml:"keybindings,omitempty"` // action name to keys, replacing its defaults
 }
 
 // ThemeConfig represents theme configuration
@@ -28,17 +32,21 @@ type ThemeConfig struct {
 
 // GitConfig represents git-specific configuration
 type GitConfig struct {
-	AutoCreateBranch             bool     `yaml:"auto_create_branch"`
-	DeleteBranchOnWorktreeRemove bool     `yaml:"delete_branch_on_worktree_remove"`
-	DefaultRemote                string   `yaml:"default_remote"`
-	ExcludePatterns              []string `yaml:"exclude_patterns"`
+	AutoCreateBranch             bool          `yaml:"auto_create_branch"`
+	DeleteBranchOnWorktreeRemove bool          `yaml:"delete_branch_on_worktree_remove"`
+	DefaultRemote                string        `yaml:"default_remote"`
+	ExcludePatterns              []string      `yaml:"exclude_patterns"`
+	TrashOnRemove                bool          `yaml:"trash_on_remove"`
+	CommandTimeout               time.Duration `yaml:"command_timeout"` // per git command; negative disables
 }
 
 // UIConfig represents UI-specific configuration
 type UIConfig struct {
-	ShowIcons     bool `yaml:"show_icons"`
-	ConfirmDelete bool `yaml:"confirm_delete"`
-	MaxPathLength int  `yaml:"max_path_length"`
+	ShowIcons     bool   `yaml:"show_icons"`
+	ConfirmDelete bool   `yaml:"confirm_delete"`
+	MaxPathLength int    `yaml:"max_path_length"`
+	Sort          string `yaml:"sort"`            // git, frecency, branch, path or commit
+	GroupByPrefix bool   `yaml:"group_by_prefix"` // group branches like feature/ and fix/
 }
 
 // defaultConfig returns the default configuration
@@ -59,11 +67,14 @@ func defaultConfig() *Config {
 			DeleteBranchOnWorktreeRemove: false, // Default to false for safety
 			DefaultRemote:                "origin",
 			ExcludePatterns:              []string{},
+			TrashOnRemove:                false,
+			CommandTimeout:               time.Minute,
 		},
 		UI: UIConfig{
 			ShowIcons:     true,
 			ConfirmDelete: true,
 			MaxPathLength: 50,
+			Sort:          "frecency",
 		},
 		Aliases: map[string]string{
 			"ls": "list",
@@ -179,6 +190,9 @@ func mergeGitConfig(config, defaultCfg *GitConfig) {
 	if config.ExcludePatterns == nil {
 		config.ExcludePatterns = defaultCfg.ExcludePatterns
 	}
+	if config.CommandTimeout == 0 {
+		config.CommandTimeout = defaultCfg.CommandTimeout
+	}
 }
 
 // mergeUIConfig merges UI configuration with defaults
@@ -186,6 +200,9 @@ func mergeUIConfig(config, defaultCfg *UIConfig) {
 	if config.MaxPathLength == 0 {
 		config.MaxPathLength = defaultCfg.MaxPathLength
 	}
+	if config.Sort == "" {
+		config.Sort = defaultCfg.Sort
+	}
 	// Note: ShowIcons and ConfirmDelete will use Go's zero values (false)
 	// if not explicitly set in config. This is expected behavior.
 }
@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -30,6 +31,10 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected default remote 'origin', got '%s'", cfg.Git.DefaultRemote)
 	}
 
+	if cfg.Git.CommandTimeout != time.Minute {
+		t.Errorf("Expected CommandTimeout to be 1m, got %s", cfg.Git.CommandTimeout)
+	}
+
 	if !cfg.UI.ShowIcons {
 		t.Errorf("Expected ShowIcons to be true by default")
 	}
@@ -42,6 +47,10 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected MaxPathLength to be 50, got %d", cfg.UI.MaxPathLength)
 	}
 
+	if cfg.UI.Sort != "frecency" || cfg.UI.GroupByPrefix {
+		t.Errorf("Expected frecency order without grouping, got %q, %t", cfg.UI.Sort, cfg.UI.GroupByPrefix)
+	}
+
 	// Test theme colors
 	expectedColors := map[string]string{
 		"Primary":   "#7C3AED",
@@ -216,13 +225,21 @@ theme:
 git:
   auto_create_branch: false
   default_remote: "upstream"
+  command_timeout: 90s
 ui:
   show_icons: false
   confirm_delete: false
   max_path_length: 100
+  sort: commit
+  group_by_prefix: true
 aliases:
   l: "list"
   n: "new"
+editor: "code {{.Path}}"
+multiplexer: zellij
+keybindings:
+  delete: [x]
+  create: ["n", "a"]
 `,
 			expectDefault: false,
 			expectedError: false,
@@ -239,15 +256,30 @@ aliases:
 				if cfg.Git.DefaultRemote != "upstream" {
 					t.Errorf("Expected custom remote, got '%s'", cfg.Git.DefaultRemote)
 				}
+				if cfg.Git.CommandTimeout != 90*time.Second {
+					t.Errorf("Expected CommandTimeout to be 90s, got %s", cfg.Git.CommandTimeout)
+				}
 				if cfg.UI.ShowIcons {
 					t.Errorf("Expected ShowIcons to be false")
 				}
 				if cfg.UI.MaxPathLength != 100 {
 					t.Errorf("Expected MaxPathLength to be 100, got %d", cfg.UI.MaxPathLength)
 				}
+				if cfg.UI.Sort != "commit" || !cfg.UI.GroupByPrefix {
+					t.Errorf("Expected commit order grouped by prefix, got %q, %t", cfg.UI.Sort, cfg.UI.GroupByPrefix)
+				}
 				if cfg.Aliases["l"] != "list" {
 					t.Errorf("Expected alias 'l' to map to 'list'")
 				}
+				if keys := cfg.KeyBindings["create"]; len(keys) != 2 || keys[0] != "n" || keys[1] != "a" {
+					t.Errorf("Expected create bound to n and a, got %v", keys)
+				}
+				if cfg.Editor != "code {{.Path}}" {
+					t.Errorf("Expected the editor template, got %q", cfg.Editor)
+				}
+				if cfg.Multiplexer != "zellij" {
+					t.Errorf("Expected zellij, got %q", cfg.Multiplexer)
+				}
 			},
 		},
 		{
@@ -293,6 +325,9 @@ git:
 				if cfg.UI.MaxPathLength != 50 {
 					t.Errorf("Should use default for unset MaxPathLength")
 				}
+				if cfg.UI.Sort != "frecency" {
+					t.Errorf("Should use default for unset Sort")
+				}
 			},
 		},
 	}
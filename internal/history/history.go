@@ -0,0 +1,153 @@
+// Package history remembers which worktrees were chosen and when, so that the
+// selector can rank them by frecency and return to the previous one.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// maxEntries bounds the state file; the least recently used entries go first
+const maxEntries = 500
+
+// Entry records how often and how recently a worktree was chosen
+type Entry struct {
+	Path     string    `json:"path"`
+	Repo     string    `json:"repo"` // common git directory of the worktree's repository
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// History is the set of recorded worktrees, backed by a file
+type History struct {
+	Entries []Entry `json:"entries"`
+
+	path string
+}
+
+// DefaultPath returns the state file under the XDG state directory:
+// $XDG_STATE_HOME/yosegi/history.json, or ~/.local/state/yosegi/history.json
+func DefaultPath() (string, error) {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if !filepath.IsAbs(stateHome) {
+		// The XDG spec says relative paths are invalid and should be ignored
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+	return filepath.Join(stateHome, "yosegi", "history.json"), nil
+}
+
+// Load reads the history from path. A missing file is an empty history.
+func Load(path string) (*History, error) {
+	h := &History{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse history %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// Save writes the history back to its file, replacing it atomically
+func (h *History) Save() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), ".history-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), h.path); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Record notes that the worktree at path in repo was chosen at now
+func (h *History) Record(repo, path string, now time.Time) {
+	for i := range h.Entries {
+		if h.Entries[i].Path == path {
+			h.Entries[i].Repo = repo
+			h.Entries[i].Count++
+			h.Entries[i].LastUsed = now
+			return
+		}
+	}
+
+	h.Entries = append(h.Entries, Entry{Path: path, Repo: repo, Count: 1, LastUsed: now})
+	if len(h.Entries) > maxEntries {
+		sort.SliceStable(h.Entries, func(i, j int) bool {
+			return h.Entries[i].LastUsed.After(h.Entries[j].LastUsed)
+		})
+		h.Entries = h.Entries[:maxEntries]
+	}
+}
+
+// Recent returns the entries of repo, most recently used first
+func (h *History) Recent(repo string) []Entry {
+	var entries []Entry
+	for _, e := range h.Entries {
+		if e.Repo == repo {
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries
+}
+
+// Scores returns the frecency of each recorded worktree by path, for ranking
+// worktrees that were chosen often and lately above the rest
+func (h *History) Scores(now time.Time) map[string]float64 {
+	scores := make(map[string]float64, len(h.Entries))
+	for _, e := range h.Entries {
+		scores[e.Path] = e.Frecency(now)
+	}
+	return scores
+}
+
+// Frecency weighs how often the worktree was chosen by how recently, so a
+// worktree used a lot last month ranks below one used a few times today
+func (e Entry) Frecency(now time.Time) float64 {
+	age := now.Sub(e.LastUsed)
+	weight := 0.25
+	switch {
+	case age < time.Hour:
+		weight = 4
+	case age < 24*time.Hour:
+		weight = 2
+	case age < 7*24*time.Hour:
+		weight = 0.5
+	}
+	return float64(e.Count) * weight
+}
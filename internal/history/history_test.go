@@ -0,0 +1,128 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	if path, err := DefaultPath(); err != nil || path != filepath.Join("/state", "yosegi", "history.json") {
+		t.Errorf("Expected history under XDG_STATE_HOME, got %q, %v", path, err)
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, value := range []string{"", "relative/state"} {
+		t.Setenv("XDG_STATE_HOME", value)
+		if path, err := DefaultPath(); err != nil || path != filepath.Join(home, ".local", "state", "yosegi", "history.json") {
+			t.Errorf("Expected history under ~/.local/state for %q, got %q, %v", value, path, err)
+		}
+	}
+}
+
+func TestLoadAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "yosegi", "history.json")
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	h, err := Load(path)
+	if err != nil || len(h.Entries) != 0 {
+		t.Fatalf("Expected an empty history without a file, got %+v, %v", h, err)
+	}
+
+	h.Record("/repo/.git", "/repo/feature", now)
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Entries) != 1 || loaded.Entries[0].Path != "/repo/feature" || !loaded.Entries[0].LastUsed.Equal(now) {
+		t.Errorf("Expected the recorded entry back, got %+v", loaded.Entries)
+	}
+
+	// Saving replaces the file without leaving temporary files behind
+	loaded.Record("/repo/.git", "/repo/feature", now)
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	files, _ := os.ReadDir(filepath.Dir(path))
+	if len(files) != 1 {
+		t.Errorf("Expected only the history file, got %v", files)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected a corrupt history to fail to load")
+	}
+}
+
+func TestRecordAndRecent(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	h := &History{}
+
+	h.Record("/repo/.git", "/repo/main", now.Add(-3*time.Hour))
+	h.Record("/repo/.git", "/repo/feature", now.Add(-2*time.Hour))
+	h.Record("/other/.git", "/other/main", now.Add(-1*time.Hour))
+	h.Record("/repo/.git", "/repo/main", now)
+
+	recent := h.Recent("/repo/.git")
+	if len(recent) != 2 || recent[0].Path != "/repo/main" || recent[1].Path != "/repo/feature" {
+		t.Fatalf("Expected this repository's worktrees, most recent first, got %+v", recent)
+	}
+	if recent[0].Count != 2 || !recent[0].LastUsed.Equal(now) {
+		t.Errorf("Expected the repeated choice to be counted, got %+v", recent[0])
+	}
+	if len(h.Recent("/missing/.git")) != 0 {
+		t.Error("Expected no entries for an unknown repository")
+	}
+}
+
+func TestRecordLimit(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	h := &History{}
+
+	for i := 0; i <= maxEntries; i++ {
+		h.Record("/repo/.git", filepath.Join("/repo", time.Duration(i).String()), now.Add(time.Duration(i)*time.Minute))
+	}
+	if len(h.Entries) != maxEntries {
+		t.Fatalf("Expected %d entries, got %d", maxEntries, len(h.Entries))
+	}
+	for _, e := range h.Entries {
+		if e.Path == "/repo/0s" {
+			t.Error("Expected the least recently used entry to be dropped")
+		}
+	}
+}
+
+func TestFrecency(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		entry    Entry
+		expected float64
+	}{
+		{name: "Last hour", entry: Entry{Count: 2, LastUsed: now.Add(-10 * time.Minute)}, expected: 8},
+		{name: "Today", entry: Entry{Count: 2, LastUsed: now.Add(-5 * time.Hour)}, expected: 4},
+		{name: "This week", entry: Entry{Count: 2, LastUsed: now.Add(-3 * 24 * time.Hour)}, expected: 1},
+		{name: "Older", entry: Entry{Count: 20, LastUsed: now.Add(-30 * 24 * time.Hour)}, expected: 5},
+	}
+
+	for _, tt := range tests {
+		if got := tt.entry.Frecency(now); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+
+	h := &History{Entries: []Entry{{Path: "/repo/main", Count: 1, LastUsed: now}}}
+	if scores := h.Scores(now); scores["/repo/main"] != 4 || len(scores) != 1 {
+		t.Errorf("Unexpected scores: %v", scores)
+	}
+}
@@ -0,0 +1,149 @@
+// Package launch builds the commands that open a worktree outside yosegi: the
+// configured editor, the user's shell and terminal multiplexer sessions.
+package launch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// Editor returns the command opening wt in an editor. editor is the editor
+// setting: a command line whose words may use the worktree's fields, as in
+// "code {{ .Path }}". Unless a word uses .Path, the path is added as the last
+// argument, and an empty setting falls back to $VISUAL, then $EDITOR.
+func Editor(editor string, wt worktree.Worktree) (*exec.Cmd, error) {
+	if strings.TrimSpace(editor) == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if strings.TrimSpace(editor) == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	// Words are expanded one by one so that paths with spaces stay one argument
+	words := commandWords(editor)
+	if len(words) == 0 {
+		return nil, errors.New("no editor to open worktrees in: configure editor, or set $VISUAL or $EDITOR")
+	}
+
+	var args []string
+	hasPath := false
+	for _, word := range words {
+		arg, usesPath, err := expand(word, wt)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		hasPath = hasPath || usesPath
+	}
+	if !hasPath {
+		args = append(args, wt.Path)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = wt.Path
+	return cmd, nil
+}
+
+// commandWords splits a command line at spaces outside template actions, so
+// that "{{ .Path }}" stays one word
+func commandWords(line string) []string {
+	var words []string
+	var word strings.Builder
+	inAction := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case strings.HasPrefix(line[i:], "{{"), strings.HasPrefix(line[i:], "}}"):
+			inAction = line[i] == '{'
+			word.WriteString(line[i : i+2])
+			i++
+		case !inAction && (line[i] == ' ' || line[i] == '\t' || line[i] == '\n'):
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		default:
+			word.WriteByte(line[i])
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// expand executes word as a template over wt, reporting whether it uses the
+// worktree's path
+func expand(word string, wt worktree.Worktree) (string, bool, error) {
+	tmpl, err := template.New("editor").Option("missingkey=error").Parse(word)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid editor template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, wt); err != nil {
+		return "", false, fmt.Errorf("invalid editor template: %w", err)
+	}
+	return b.String(), usesPath(tmpl.Tree.Root), nil
+}
+
+// usesPath reports whether a template node refers to the Path field
+func usesPath(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if usesPath(child) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return usesPath(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if usesPath(cmd) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if usesPath(arg) {
+				return true
+			}
+		}
+	case *parse.IfNode:
+		return usesPath(n.Pipe) || usesPath(n.List) || usesPath(n.ElseList)
+	case *parse.WithNode:
+		return usesPath(n.Pipe) || usesPath(n.List) || usesPath(n.ElseList)
+	case *parse.RangeNode:
+		return usesPath(n.Pipe) || usesPath(n.List) || usesPath(n.ElseList)
+	case *parse.ChainNode:
+		return usesPath(n.Node) || (len(n.Field) > 0 && n.Field[0] == "Path")
+	case *parse.FieldNode:
+		return len(n.Ident) > 0 && n.Ident[0] == "Path"
+	}
+	return false
+}
+
+// Shell returns the command starting $SHELL, or /bin/sh, in wt
+func Shell(wt worktree.Worktree) *exec.Cmd {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	cmd := exec.Command(shell)
+	cmd.Dir = wt.Path
+	return cmd
+}
@@ -0,0 +1,83 @@
+package launch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+func TestEditor(t *testing.T) {
+	wt := worktree.Worktree{Path: "/work/my repo/feature", Branch: "feature/login"}
+
+	tests := []struct {
+		name     string
+		editor   string
+		visual   string
+		env      string
+		expected []string
+	}{
+		{name: "Template", editor: "code {{.Path}}", expected: []string{"code", "/work/my repo/feature"}},
+		{name: "Spaced template", editor: "code {{ .Path }}", expected: []string{"code", "/work/my repo/feature"}},
+		{name: "Template with arguments", editor: `code --goto {{ printf "%s/go.mod" .Path }}`, expected: []string{"code", "--goto", "/work/my repo/feature/go.mod"}},
+		{name: "Path appended after fields", editor: "idea --title={{.Branch}}", expected: []string{"idea", "--title=feature/login", "/work/my repo/feature"}},
+		{name: "Several fields", editor: "idea --title={{.Branch}} {{.Path}}", expected: []string{"idea", "--title=feature/login", "/work/my repo/feature"}},
+		{name: "Path appended", editor: "code -n", expected: []string{"code", "-n", "/work/my repo/feature"}},
+		{name: "Setting before environment", editor: "zed", visual: "vim", expected: []string{"zed", "/work/my repo/feature"}},
+		{name: "VISUAL", visual: "nvim", env: "vi", expected: []string{"nvim", "/work/my repo/feature"}},
+		{name: "EDITOR", env: "vim -p", expected: []string{"vim", "-p", "/work/my repo/feature"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.env)
+
+			cmd, err := Editor(tt.editor, wt)
+			if err != nil {
+				t.Fatalf("Editor failed: %v", err)
+			}
+			if strings.Join(cmd.Args, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Expected %q, got %q", tt.expected, cmd.Args)
+			}
+			if cmd.Dir != wt.Path {
+				t.Errorf("Expected the command to run in the worktree, got %q", cmd.Dir)
+			}
+		})
+	}
+}
+
+func TestEditorErrors(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	wt := worktree.Worktree{Path: "/repo/feature"}
+
+	tests := []struct {
+		editor   string
+		expected string
+	}{
+		{editor: "", expected: "set $VISUAL or $EDITOR"},
+		{editor: "code {{.Path", expected: "invalid editor template"},
+		{editor: "code {{.Dir}}", expected: "invalid editor template"},
+	}
+
+	for _, tt := range tests {
+		if _, err := Editor(tt.editor, wt); err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Editor(%q): expected error containing %q, got %v", tt.editor, tt.expected, err)
+		}
+	}
+}
+
+func TestShell(t *testing.T) {
+	wt := worktree.Worktree{Path: "/repo/feature"}
+
+	t.Setenv("SHELL", "/bin/zsh")
+	if cmd := Shell(wt); cmd.Path != "/bin/zsh" || cmd.Dir != wt.Path {
+		t.Errorf("Expected $SHELL in the worktree, got %q in %q", cmd.Path, cmd.Dir)
+	}
+
+	t.Setenv("SHELL", "")
+	if cmd := Shell(wt); cmd.Args[0] != "/bin/sh" {
+		t.Errorf("Expected /bin/sh without $SHELL, got %q", cmd.Args)
+	}
+}
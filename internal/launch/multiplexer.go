@@ -0,0 +1,166 @@
+package launch
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// Multiplexer runs a terminal session per worktree, named by SessionName
+type Multiplexer interface {
+	// Name is the multiplexer's command, such as tmux
+	Name() string
+	// Sessions returns the names of the running sessions. None are running
+	// when the multiplexer is not installed.
+	Sessions(ctx context.Context) (map[string]bool, error)
+	// Attach returns the command attaching to the session, which it creates
+	// in dir when it is not running
+	Attach(ctx context.Context, name, dir string) (*exec.Cmd, error)
+	// Kill ends the session
+	Kill(ctx context.Context, name string) error
+}
+
+// runFunc runs a multiplexer command and returns its output
+type runFunc func(ctx context.Context, name string, args ...string) (string, error)
+
+// run runs a command, returning its standard error as the error's text
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	var stdout, stderr strings.Builder
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w", msg, err)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// NewMultiplexer returns the multiplexer set by the multiplexer setting:
+// tmux, the default, or zellij
+func NewMultiplexer(name string) (Multiplexer, error) {
+	switch name {
+	case "", "tmux":
+		return tmux{run: run}, nil
+	case "zellij":
+		return zellij{run: run}, nil
+	}
+	return nil, fmt.Errorf("unknown multiplexer '%s' (expected tmux or zellij)", name)
+}
+
+// unsafeName matches what is replaced in session names: tmux turns '.' and
+// ':' into targets, and zellij names sockets after sessions
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// SessionName returns the name of wt's session: its branch, or the name of
+// its directory when it has none, with anything but letters, digits, '-'
+// and '_' replaced by '-'. When that changed the branch, or for a directory
+// name, a short hash of the branch or path follows so that worktrees such as
+// feature/login and feature-login do not share a session.
+func SessionName(wt worktree.Worktree) string {
+	name, key := wt.Branch, wt.Branch
+	if name == "" || strings.HasPrefix(name, "(") {
+		name, key = filepath.Base(wt.Path), wt.Path
+	}
+
+	safe := strings.Trim(unsafeName.ReplaceAllString(name, "-"), "-")
+	if safe == key {
+		return safe
+	}
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("%s-%x", safe, sum[:3])
+}
+
+// notInstalled reports whether err is from a command that is not installed
+func notInstalled(err error) bool {
+	return errors.Is(err, exec.ErrNotFound)
+}
+
+// lines returns the non-empty lines of output as a set
+func lines(output string) map[string]bool {
+	set := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			set[line] = true
+		}
+	}
+	return set
+}
+
+type tmux struct{ run runFunc }
+
+func (tmux) Name() string { return "tmux" }
+
+func (t tmux) Sessions(ctx context.Context) (map[string]bool, error) {
+	output, err := t.run(ctx, "tmux", "list-sessions", "-F", "#{session_name}")
+	switch {
+	case err == nil:
+		return lines(output), nil
+	case notInstalled(err), strings.Contains(err.Error(), "no server running"), strings.Contains(err.Error(), "error connecting"):
+		return map[string]bool{}, nil
+	}
+	return nil, fmt.Errorf("failed to list tmux sessions: %w", err)
+}
+
+func (t tmux) Attach(ctx context.Context, name, dir string) (*exec.Cmd, error) {
+	// Inside tmux the client switches to the session instead, since tmux
+	// does not nest; the session has to exist first
+	if os.Getenv("TMUX") != "" {
+		if _, err := t.run(ctx, "tmux", "has-session", "-t", "="+name); err != nil {
+			if _, err := t.run(ctx, "tmux", "new-session", "-d", "-s", name, "-c", dir); err != nil {
+				return nil, fmt.Errorf("failed to create tmux session '%s': %w", name, err)
+			}
+		}
+		return exec.Command("tmux", "switch-client", "-t", "="+name), nil
+	}
+	return exec.Command("tmux", "new-session", "-A", "-s", name, "-c", dir), nil
+}
+
+func (t tmux) Kill(ctx context.Context, name string) error {
+	if _, err := t.run(ctx, "tmux", "kill-session", "-t", "="+name); err != nil {
+		return fmt.Errorf("failed to kill tmux session '%s': %w", name, err)
+	}
+	return nil
+}
+
+type zellij struct{ run runFunc }
+
+func (zellij) Name() string { return "zellij" }
+
+func (z zellij) Sessions(ctx context.Context) (map[string]bool, error) {
+	output, err := z.run(ctx, "zellij", "list-sessions", "--short", "--no-formatting")
+	switch {
+	case err == nil:
+		return lines(output), nil
+	case notInstalled(err), strings.Contains(err.Error(), "No active zellij sessions"):
+		return map[string]bool{}, nil
+	}
+	return nil, fmt.Errorf("failed to list zellij sessions: %w", err)
+}
+
+func (z zellij) Attach(ctx context.Context, name, dir string) (*exec.Cmd, error) {
+	if os.Getenv("ZELLIJ") != "" {
+		return nil, errors.New("already inside a zellij session; detach from it first")
+	}
+
+	// A new session starts in the working directory
+	cmd := exec.Command("zellij", "attach", "--create", name)
+	cmd.Dir = dir
+	return cmd, nil
+}
+
+func (z zellij) Kill(ctx context.Context, name string) error {
+	if _, err := z.run(ctx, "zellij", "kill-session", name); err != nil {
+		return fmt.Errorf("failed to kill zellij session '%s': %w", name, err)
+	}
+	return nil
+}
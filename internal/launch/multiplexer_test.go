@@ -0,0 +1,133 @@
+package launch
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// fakeRun records the commands it is asked to run and answers them from
+// outputs, keyed by the command's first two words
+type fakeRun struct {
+	calls   []string
+	outputs map[string]string
+	errs    map[string]error
+}
+
+func (f *fakeRun) run(ctx context.Context, name string, args ...string) (string, error) {
+	call := strings.Join(append([]string{name}, args...), " ")
+	f.calls = append(f.calls, call)
+	key := name + " " + args[0]
+	return f.outputs[key], f.errs[key]
+}
+
+func TestNewMultiplexer(t *testing.T) {
+	for setting, expected := range map[string]string{"": "tmux", "tmux": "tmux", "zellij": "zellij"} {
+		m, err := NewMultiplexer(setting)
+		if err != nil || m.Name() != expected {
+			t.Errorf("NewMultiplexer(%q) = %v, %v, expected %s", setting, m, err, expected)
+		}
+	}
+
+	if _, err := NewMultiplexer("screen"); err == nil || !strings.Contains(err.Error(), "unknown multiplexer 'screen'") {
+		t.Errorf("Expected an unknown multiplexer to be refused, got %v", err)
+	}
+}
+
+func TestSessionName(t *testing.T) {
+	tests := []struct {
+		wt       worktree.Worktree
+		expected string
+	}{
+		{wt: worktree.Worktree{Path: "/work/login", Branch: "feature-login"}, expected: "feature-login"},
+		{wt: worktree.Worktree{Path: "/work/login", Branch: "feature/login"}, expected: "feature-login-df7c7a"},
+		{wt: worktree.Worktree{Path: "/work/v2", Branch: "release/1.2:rc"}, expected: "release-1-2-rc-d82a2f"},
+		{wt: worktree.Worktree{Path: "/work/spike", Branch: "(detached)"}, expected: "spike-c6e657"},
+		{wt: worktree.Worktree{Path: "/work/spike"}, expected: "spike-c6e657"},
+		{wt: worktree.Worktree{Path: "/other/spike"}, expected: "spike-e77356"},
+	}
+
+	for _, tt := range tests {
+		if got := SessionName(tt.wt); got != tt.expected {
+			t.Errorf("SessionName(%+v) = %q, expected %q", tt.wt, got, tt.expected)
+		}
+	}
+}
+
+func TestTmux(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("TMUX", "")
+	f := &fakeRun{outputs: map[string]string{"tmux list-sessions": "main\nfeature-login\n"}}
+	m := tmux{run: f.run}
+
+	sessions, err := m.Sessions(ctx)
+	if err != nil || len(sessions) != 2 || !sessions["feature-login"] {
+		t.Errorf("Expected the running sessions, got %v, %v", sessions, err)
+	}
+
+	cmd, err := m.Attach(ctx, "feature-login", "/work/login")
+	if err != nil || strings.Join(cmd.Args, " ") != "tmux new-session -A -s feature-login -c /work/login" {
+		t.Errorf("Expected to create or attach to the session, got %v, %v", cmd, err)
+	}
+
+	if err := m.Kill(ctx, "feature-login"); err != nil || f.calls[len(f.calls)-1] != "tmux kill-session -t =feature-login" {
+		t.Errorf("Expected the session to be killed, got %v, %v", f.calls, err)
+	}
+
+	// Without a server or tmux itself, no sessions are running
+	for _, err := range []error{errors.New("no server running on /tmp/tmux-0/default: exit status 1"), exec.ErrNotFound} {
+		f.errs = map[string]error{"tmux list-sessions": err}
+		if sessions, err := m.Sessions(ctx); err != nil || len(sessions) != 0 {
+			t.Errorf("Expected no sessions, got %v, %v", sessions, err)
+		}
+	}
+}
+
+func TestTmuxAttachInsideTmux(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-0/default,1,0")
+	f := &fakeRun{errs: map[string]error{"tmux has-session": errors.New("can't find session")}}
+	m := tmux{run: f.run}
+
+	// The session is created detached and the client switched to it
+	cmd, err := m.Attach(context.Background(), "feature-login", "/work/login")
+	if err != nil || strings.Join(cmd.Args, " ") != "tmux switch-client -t =feature-login" {
+		t.Fatalf("Expected to switch to the session, got %v, %v", cmd, err)
+	}
+	if f.calls[1] != "tmux new-session -d -s feature-login -c /work/login" {
+		t.Errorf("Expected the session to be created first, got %v", f.calls)
+	}
+}
+
+func TestZellij(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("ZELLIJ", "")
+	f := &fakeRun{outputs: map[string]string{"zellij list-sessions": "feature-login\n"}}
+	m := zellij{run: f.run}
+
+	if sessions, err := m.Sessions(ctx); err != nil || !sessions["feature-login"] {
+		t.Errorf("Expected the running sessions, got %v, %v", sessions, err)
+	}
+
+	cmd, err := m.Attach(ctx, "feature-login", "/work/login")
+	if err != nil || strings.Join(cmd.Args, " ") != "zellij attach --create feature-login" || cmd.Dir != "/work/login" {
+		t.Errorf("Expected to create or attach to the session in the worktree, got %v, %v", cmd, err)
+	}
+
+	if err := m.Kill(ctx, "feature-login"); err != nil || f.calls[len(f.calls)-1] != "zellij kill-session feature-login" {
+		t.Errorf("Expected the session to be killed, got %v, %v", f.calls, err)
+	}
+
+	f.errs = map[string]error{"zellij list-sessions": errors.New("No active zellij sessions found.: exit status 1")}
+	if sessions, err := m.Sessions(ctx); err != nil || len(sessions) != 0 {
+		t.Errorf("Expected no sessions, got %v, %v", sessions, err)
+	}
+
+	t.Setenv("ZELLIJ", "0")
+	if _, err := m.Attach(ctx, "feature-login", "/work/login"); err == nil {
+		t.Error("Expected attaching from inside zellij to be refused")
+	}
+}
@@ -0,0 +1,271 @@
+// Package mcp serves worktree operations over JSON-RPC 2.0 using the Model
+// Context Protocol, so that coding agents can manage worktrees without
+// parsing yosegi's human-readable output.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeToolFailed     = -32000
+)
+
+// protocolVersions lists the MCP revisions the server speaks, newest first
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// maxMessageSize bounds a single JSON-RPC message read from the client
+const maxMessageSize = 16 * 1024 * 1024
+
+// Options configures a Server
+type Options struct {
+	Version string // reported to clients as the server version
+	// PathForBranch derives the directory of a new worktree when the client
+	// does not pass one
+	PathForBranch func(branch string) string
+	// EndSession ends the terminal session of a removed worktree, returning
+	// its name or "" when none was running. Sessions are left alone when nil.
+	EndSession func(ctx context.Context, wt worktree.Worktree) (string, error)
+}
+
+// Server answers JSON-RPC requests about the worktrees of one repository
+type Server struct {
+	manager worktree.Manager
+	options Options
+	tools   []tool
+}
+
+// NewServer creates a server that operates on manager's repository
+func NewServer(manager worktree.Manager, options Options) *Server {
+	s := &Server{manager: manager, options: options}
+	s.tools = s.defineTools()
+	return s
+}
+
+// request is a JSON-RPC request or, without an ID, a notification
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// Serve reads newline-delimited JSON-RPC messages from in and writes the
+// responses to out until in is exhausted or ctx is cancelled. Requests are
+// handled one at a time, in order.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			line := slices.Clone(scanner.Bytes())
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	encoder := json.NewEncoder(out)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case line := <-lines:
+			if resp := s.handleMessage(ctx, line); resp != nil {
+				if err := encoder.Encode(resp); err != nil {
+					return fmt.Errorf("failed to write response: %w", err)
+				}
+			}
+		}
+	}
+}
+
+// handleMessage processes one message and returns the response to send, or
+// nil for notifications and blank lines
+func (s *Server) handleMessage(ctx context.Context, line []byte) *response {
+	trimmed := strings.TrimSpace(string(line))
+	if trimmed == "" {
+		return nil
+	}
+	if strings.HasPrefix(trimmed, "[") {
+		return errorResponse(nil, &rpcError{Code: codeInvalidRequest, Message: "batch requests are not supported"})
+	}
+
+	var req request
+	if err := json.Unmarshal([]byte(trimmed), &req); err != nil {
+		return errorResponse(nil, &rpcError{Code: codeParseError, Message: fmt.Sprintf("parse error: %v", err)})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, &rpcError{Code: codeInvalidRequest, Message: "invalid request: expected jsonrpc 2.0 and a method"})
+	}
+
+	result, err := s.dispatch(ctx, req)
+	// Notifications never get a response, not even an error
+	if len(req.ID) == 0 {
+		return nil
+	}
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{Code: codeToolFailed, Message: err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr)
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func errorResponse(id json.RawMessage, err *rpcError) *response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", ID: id, Error: err}
+}
+
+// dispatch routes a request to the MCP lifecycle methods or, for plain
+// JSON-RPC clients, directly to a tool by name
+func (s *Server) dispatch(ctx context.Context, req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	}
+
+	if strings.HasPrefix(req.Method, "notifications/") {
+		return struct{}{}, nil
+	}
+	if t := s.findTool(req.Method); t != nil {
+		return t.handler(ctx, req.Params)
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+}
+
+// initialize negotiates the protocol version and advertises the tools capability
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+	}
+
+	version := protocolVersions[0]
+	if slices.Contains(protocolVersions, p.ProtocolVersion) {
+		version = p.ProtocolVersion
+	}
+
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools": map[string]any{},
+		},
+		"serverInfo": map[string]any{
+			"name":    "yosegi",
+			"version": s.options.Version,
+		},
+		"instructions": "Manage git worktrees of the repository yosegi was started in. " +
+			"Create one worktree per task, check its status and diff, and remove it when done.",
+	}, nil
+}
+
+func (s *Server) listTools() any {
+	tools := make([]map[string]any, 0, len(s.tools))
+	for _, t := range s.tools {
+		tools = append(tools, map[string]any{
+			"name":        t.name,
+			"description": t.description,
+			"inputSchema": t.inputSchema,
+		})
+	}
+	return map[string]any{"tools": tools}
+}
+
+// callTool runs a tool for an MCP client. Failures of the tool itself are
+// reported in the result so the agent can read them, not as protocol errors.
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, invalidParams(err)
+	}
+
+	t := s.findTool(p.Name)
+	if t == nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", p.Name)}
+	}
+
+	result, err := t.handler(ctx, p.Arguments)
+	if err != nil {
+		return map[string]any{
+			"content": []map[string]any{{"type": "text", "text": err.Error()}},
+			"isError": true,
+		}, nil
+	}
+
+	text, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"content":           []map[string]any{{"type": "text", "text": string(text)}},
+		"structuredContent": result,
+		"isError":           false,
+	}, nil
+}
+
+func (s *Server) findTool(name string) *tool {
+	for i := range s.tools {
+		if s.tools[i].name == name {
+			return &s.tools[i]
+		}
+	}
+	return nil
+}
+
+func invalidParams(err error) error {
+	return &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+}
@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
+)
+
+// serve runs the server over the given input lines and decodes every response
+func serve(t *testing.T, server *Server, lines ...string) []map[string]any {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := server.Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	var responses []map[string]any
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var resp map[string]any
+		if err := decoder.Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v\n%s", err, out.String())
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func newTestServer(runner *worktreetest.Runner) *Server {
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+	return NewServer(manager, Options{Version: "test"})
+}
+
+func TestServeInitialize(t *testing.T) {
+	server := newTestServer(worktreetest.NewRunner())
+
+	responses := serve(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"agent","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+		`{"jsonrpc":"2.0","id":"p","method":"ping"}`,
+	)
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses (no reply to the notification), got %d: %+v", len(responses), responses)
+	}
+
+	result := responses[0]["result"].(map[string]any)
+	if result["protocolVersion"] != "2025-03-26" {
+		t.Errorf("Expected the client's supported version to be echoed, got %v", result["protocolVersion"])
+	}
+	if _, ok := result["capabilities"].(map[string]any)["tools"]; !ok {
+		t.Error("Expected the tools capability to be advertised")
+	}
+	if info := result["serverInfo"].(map[string]any); info["name"] != "yosegi" || info["version"] != "test" {
+		t.Errorf("Unexpected server info: %+v", info)
+	}
+
+	if version := responses[1]["result"].(map[string]any)["protocolVersion"]; version != protocolVersions[0] {
+		t.Errorf("Expected fallback to the latest version, got %v", version)
+	}
+	if responses[2]["id"] != "p" || responses[2]["result"] == nil {
+		t.Errorf("Expected ping result with string id, got %+v", responses[2])
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	server := newTestServer(worktreetest.NewRunner())
+
+	responses := serve(t, server,
+		`not json`,
+		`{"jsonrpc":"1.0","id":1,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"unknown"}`,
+		`[{"jsonrpc":"2.0","id":3,"method":"ping"}]`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"missing"}}`,
+		``,
+		`{"jsonrpc":"2.0","method":"unknown"}`,
+	)
+
+	expected := []float64{codeParseError, codeInvalidRequest, codeMethodNotFound, codeInvalidRequest, codeInvalidParams}
+	if len(responses) != len(expected) {
+		t.Fatalf("Expected %d responses, got %d: %+v", len(expected), len(responses), responses)
+	}
+	for i, code := range expected {
+		rpcErr, ok := responses[i]["error"].(map[string]any)
+		if !ok {
+			t.Errorf("Response %d: expected an error, got %+v", i, responses[i])
+			continue
+		}
+		if rpcErr["code"] != code {
+			t.Errorf("Response %d: expected code %v, got %v", i, code, rpcErr["code"])
+		}
+	}
+	if responses[0]["id"] != nil {
+		t.Errorf("Expected null id for unparseable request, got %v", responses[0]["id"])
+	}
+}
+
+func TestServeToolsList(t *testing.T) {
+	server := newTestServer(worktreetest.NewRunner())
+
+	responses := serve(t, server, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	tools := responses[0]["result"].(map[string]any)["tools"].([]any)
+
+	names := map[string]bool{}
+	for _, raw := range tools {
+		tool := raw.(map[string]any)
+		names[tool["name"].(string)] = true
+		if tool["description"] == "" {
+			t.Errorf("Tool %v has no description", tool["name"])
+		}
+		if schema := tool["inputSchema"].(map[string]any); schema["type"] != "object" {
+			t.Errorf("Tool %v should take an object, got %v", tool["name"], schema["type"])
+		}
+	}
+	for _, name := range []string{"list_worktrees", "create_worktree", "remove_worktree", "worktree_status", "worktree_diff"} {
+		if !names[name] {
+			t.Errorf("Expected tool %s to be listed", name)
+		}
+	}
+}
+
+func TestServeToolCall(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "list").Return("worktree /repo\nHEAD abc\nbranch refs/heads/main\n\n")
+	server := newTestServer(runner)
+
+	responses := serve(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_worktrees","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"list_worktrees"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"worktree_status","arguments":{"path":"/elsewhere"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"worktree_status","params":{"path":"/elsewhere"}}`,
+	)
+	if len(responses) != 4 {
+		t.Fatalf("Expected 4 responses, got %d", len(responses))
+	}
+
+	// MCP clients get text content plus the structured result
+	result := responses[0]["result"].(map[string]any)
+	if result["isError"] != false {
+		t.Errorf("Expected success, got %+v", result)
+	}
+	content := result["content"].([]any)[0].(map[string]any)
+	if content["type"] != "text" || !strings.Contains(content["text"].(string), `"/repo"`) {
+		t.Errorf("Expected worktree list as text, got %+v", content)
+	}
+	if _, ok := result["structuredContent"].(map[string]any)["worktrees"]; !ok {
+		t.Errorf("Expected structured content, got %+v", result)
+	}
+
+	// Plain JSON-RPC clients get the structured result directly
+	worktrees := responses[1]["result"].(map[string]any)["worktrees"].([]any)
+	if len(worktrees) != 1 || worktrees[0].(map[string]any)["main"] != true {
+		t.Errorf("Expected the main worktree, got %+v", worktrees)
+	}
+
+	// Tool failures are results for MCP clients and errors for plain ones
+	result = responses[2]["result"].(map[string]any)
+	if result["isError"] != true || !strings.Contains(result["content"].([]any)[0].(map[string]any)["text"].(string), "no worktree at") {
+		t.Errorf("Expected tool error result, got %+v", result)
+	}
+	if rpcErr, ok := responses[3]["error"].(map[string]any); !ok || rpcErr["code"] != float64(codeToolFailed) {
+		t.Errorf("Expected JSON-RPC error, got %+v", responses[3])
+	}
+}
+
+func TestServeCancellation(t *testing.T) {
+	server := newTestServer(worktreetest.NewRunner())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A reader that never returns stands in for an idle client
+	reader, writer := io.Pipe()
+	defer func() { _ = writer.Close() }()
+
+	if err := server.Serve(ctx, reader, &bytes.Buffer{}); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
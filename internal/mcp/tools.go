@@ -0,0 +1,330 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// tool is an operation exposed through tools/call and as a JSON-RPC method
+type tool struct {
+	name        string
+	description string
+	inputSchema map[string]any
+	handler     func(ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// worktreeInfo is the JSON form of a worktree
+type worktreeInfo struct {
+	Path       string `json:"path"`
+	Branch     string `json:"branch"`
+	Commit     string `json:"commit"`
+	Main       bool   `json:"main"`
+	Current    bool   `json:"current"`
+	Locked     bool   `json:"locked"`
+	LockReason string `json:"lock_reason,omitempty"`
+	Prunable   bool   `json:"prunable"`
+}
+
+// statusInfo is the JSON form of a worktree status
+type statusInfo struct {
+	Path       string `json:"path"`
+	Branch     string `json:"branch"`
+	Upstream   string `json:"upstream"`
+	Ahead      int    `json:"ahead"`
+	Behind     int    `json:"behind"`
+	Staged     int    `json:"staged"`
+	Modified   int    `json:"modified"`
+	Untracked  int    `json:"untracked"`
+	Conflicted int    `json:"conflicted"`
+	Clean      bool   `json:"clean"`
+}
+
+func (s *Server) defineTools() []tool {
+	return []tool{
+		{
+			name:        "list_worktrees",
+			description: "List the worktrees of the repository with their branch, commit and lock state.",
+			inputSchema: objectSchema(nil),
+			handler:     s.listWorktrees,
+		},
+		{
+			name: "create_worktree",
+			description: "Create a worktree for a branch, for example to work on a task in isolation. " +
+				"The branch is created unless it already exists.",
+			inputSchema: objectSchema(map[string]any{
+				"branch":        stringProperty("Branch to check out in the new worktree"),
+				"path":          stringProperty("Directory for the worktree; a relative path is taken from the repository root. Defaults to the configured worktree location for the branch."),
+				"create_branch": boolProperty("Require the branch to be created (true) or to exist already (false)"),
+			}, "branch"),
+			handler: s.createWorktree,
+		},
+		{
+			name: "remove_worktree",
+			description: "Remove a worktree. Fails when it has uncommitted changes unless force is set. " +
+				"The main worktree and the worktree yosegi runs in cannot be removed. Its terminal session, if running, is ended.",
+			inputSchema: objectSchema(map[string]any{
+				"path":          stringProperty("Path of the worktree to remove"),
+				"force":         boolProperty("Discard uncommitted changes and delete unmerged branches"),
+				"delete_branch": boolProperty("Also delete the worktree's local branch"),
+			}, "path"),
+			handler: s.removeWorktree,
+		},
+		{
+			name:        "worktree_status",
+			description: "Report the branch, upstream divergence and uncommitted changes of a worktree.",
+			inputSchema: objectSchema(map[string]any{
+				"path": stringProperty("Path of the worktree"),
+			}, "path"),
+			handler: s.worktreeStatus,
+		},
+		{
+			name:        "worktree_diff",
+			description: "Show the uncommitted changes of a worktree, or all its changes relative to a base branch or commit.",
+			inputSchema: objectSchema(map[string]any{
+				"path": stringProperty("Path of the worktree"),
+				"base": stringProperty("Branch or commit to compare against. Defaults to HEAD."),
+				"stat": boolProperty("Summarise changes per file instead of returning the patch"),
+			}, "path"),
+			handler: s.worktreeDiff,
+		},
+	}
+}
+
+func (s *Server) listWorktrees(ctx context.Context, args json.RawMessage) (any, error) {
+	if err := decodeArgs(args, &struct{}{}); err != nil {
+		return nil, err
+	}
+
+	worktrees, err := s.manager.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []worktreeInfo{}
+	for _, wt := range worktree.WithoutBare(worktrees) {
+		infos = append(infos, s.worktreeInfo(wt))
+	}
+	return map[string]any{"worktrees": infos}, nil
+}
+
+func (s *Server) createWorktree(ctx context.Context, args json.RawMessage) (any, error) {
+	var a struct {
+		Branch       string `json:"branch"`
+		Path         string `json:"path"`
+		CreateBranch *bool  `json:"create_branch"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	if a.Branch == "" {
+		return nil, invalidParams(errors.New("branch is required"))
+	}
+
+	path := a.Path
+	if path == "" && s.options.PathForBranch != nil {
+		path = s.options.PathForBranch(a.Branch)
+	}
+	if path == "" {
+		return nil, invalidParams(errors.New("path is required"))
+	}
+	// Relative paths are taken from the repository root, as 'yosegi new' does
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.manager.MainWorktreePath(), path)
+	}
+
+	opts := worktree.CreateOptions{Path: path, Branch: a.Branch, CreateBranch: true}
+	if a.CreateBranch != nil {
+		opts.CreateBranch = *a.CreateBranch
+	}
+	err := s.manager.Create(ctx, opts)
+	// Without an explicit choice, an existing branch is simply checked out
+	if a.CreateBranch == nil && errors.Is(err, worktree.ErrBranchExists) {
+		opts.CreateBranch = false
+		err = s.manager.Create(ctx, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"path": path, "branch": a.Branch, "created_branch": opts.CreateBranch}, nil
+}
+
+func (s *Server) removeWorktree(ctx context.Context, args json.RawMessage) (any, error) {
+	var a struct {
+		Path         string `json:"path"`
+		Force        bool   `json:"force"`
+		DeleteBranch bool   `json:"delete_branch"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+
+	wt, err := s.findWorktree(ctx, a.Path)
+	if err != nil {
+		return nil, err
+	}
+	if wt.Path == s.manager.MainWorktreePath() {
+		return nil, fmt.Errorf("'%s' is the main worktree and cannot be removed", wt.Path)
+	}
+	if wt.IsCurrent {
+		return nil, fmt.Errorf("'%s' is the worktree yosegi is running in and cannot be removed", wt.Path)
+	}
+
+	if err := s.manager.Remove(ctx, wt.Path, worktree.RemoveOptions{Force: a.Force}); err != nil {
+		if errors.Is(err, worktree.ErrDirtyWorktree) {
+			return nil, fmt.Errorf("%w. Commit the changes first, or pass force to discard them", err)
+		}
+		return nil, err
+	}
+
+	result := map[string]any{"path": wt.Path, "removed": true}
+	if s.options.EndSession != nil {
+		// As with the branch, a session left running is reported, not failed
+		if name, err := s.options.EndSession(ctx, *wt); err != nil {
+			result["session_error"] = err.Error()
+		} else if name != "" {
+			result["ended_session"] = name
+		}
+	}
+	if a.DeleteBranch && wt.Branch != "(detached)" {
+		// The worktree is gone either way, so report a failed deletion instead of failing
+		if err := s.manager.DeleteBranch(ctx, wt.Branch, a.Force); err != nil {
+			result["branch_error"] = err.Error()
+		} else {
+			result["deleted_branch"] = wt.Branch
+		}
+	}
+	return result, nil
+}
+
+func (s *Server) worktreeStatus(ctx context.Context, args json.RawMessage) (any, error) {
+	var a struct {
+		Path string `json:"path"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+
+	wt, err := s.findWorktree(ctx, a.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.manager.Status(ctx, wt.Path)
+	if err != nil {
+		return nil, err
+	}
+	return statusInfo{
+		Path:       wt.Path,
+		Branch:     status.Branch,
+		Upstream:   status.Upstream,
+		Ahead:      status.Ahead,
+		Behind:     status.Behind,
+		Staged:     status.Staged,
+		Modified:   status.Modified,
+		Untracked:  status.Untracked,
+		Conflicted: status.Conflicted,
+		Clean:      status.Clean(),
+	}, nil
+}
+
+func (s *Server) worktreeDiff(ctx context.Context, args json.RawMessage) (any, error) {
+	var a struct {
+		Path string `json:"path"`
+		Base string `json:"base"`
+		Stat bool   `json:"stat"`
+	}
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+
+	wt, err := s.findWorktree(ctx, a.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := s.manager.Diff(ctx, wt.Path, worktree.DiffOptions{Base: a.Base, Stat: a.Stat})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"path": wt.Path, "diff": diff}, nil
+}
+
+// findWorktree resolves path to one of the repository's worktrees, so tools
+// never run git in arbitrary directories
+func (s *Server) findWorktree(ctx context.Context, path string) (*worktree.Worktree, error) {
+	if path == "" {
+		return nil, invalidParams(errors.New("path is required"))
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees, err := s.manager.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, wt := range worktree.WithoutBare(worktrees) {
+		if wt.Path == absPath {
+			return &wt, nil
+		}
+	}
+	return nil, fmt.Errorf("no worktree at '%s'", absPath)
+}
+
+func (s *Server) worktreeInfo(wt worktree.Worktree) worktreeInfo {
+	return worktreeInfo{
+		Path:       wt.Path,
+		Branch:     wt.Branch,
+		Commit:     wt.Commit,
+		Main:       wt.Path == s.manager.MainWorktreePath(),
+		Current:    wt.IsCurrent,
+		Locked:     wt.Locked,
+		LockReason: wt.LockReason,
+		Prunable:   wt.Prunable,
+	}
+}
+
+// decodeArgs decodes tool arguments, rejecting unknown ones so that typos
+// are reported instead of silently ignored
+func decodeArgs(args json.RawMessage, v any) error {
+	if len(bytes.TrimSpace(args)) == 0 || string(bytes.TrimSpace(args)) == "null" {
+		args = json.RawMessage("{}")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(args))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return invalidParams(err)
+	}
+	return nil
+}
+
+func objectSchema(properties map[string]any, required ...string) map[string]any {
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProperty(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+func boolProperty(description string) map[string]any {
+	return map[string]any{"type": "boolean", "description": description}
+}
@@ -0,0 +1,266 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
+)
+
+const testWorktreeList = "worktree /repo\nHEAD abc\nbranch refs/heads/main\n\n" +
+	"worktree /wt/feature\nHEAD def\nbranch refs/heads/feature\n\n" +
+	"worktree /wt/locked\nHEAD 123\nbranch refs/heads/locked\nlocked in use\n\n"
+
+// call invokes a tool handler directly with JSON arguments
+func call(t *testing.T, server *Server, name, args string) (any, error) {
+	t.Helper()
+
+	tool := server.findTool(name)
+	if tool == nil {
+		t.Fatalf("Tool %s not defined", name)
+	}
+	return tool.handler(context.Background(), json.RawMessage(args))
+}
+
+func TestListWorktreesTool(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "list").Return("worktree /repo/.bare\nbare\n\n" + testWorktreeList)
+	server := newTestServer(runner)
+
+	result, err := call(t, server, "list_worktrees", `{}`)
+	if err != nil {
+		t.Fatalf("list_worktrees failed: %v", err)
+	}
+
+	infos := result.(map[string]any)["worktrees"].([]worktreeInfo)
+	if len(infos) != 3 {
+		t.Fatalf("Expected bare entry to be hidden, got %+v", infos)
+	}
+	if !infos[0].Main || infos[1].Main {
+		t.Errorf("Expected only /repo to be main, got %+v", infos)
+	}
+	if !infos[2].Locked || infos[2].LockReason != "in use" {
+		t.Errorf("Expected lock state, got %+v", infos[2])
+	}
+
+	if _, err := call(t, server, "list_worktrees", `{"unexpected":true}`); err == nil {
+		t.Error("Expected unknown arguments to be rejected")
+	}
+}
+
+func TestCreateWorktreeTool(t *testing.T) {
+	tests := []struct {
+		name            string
+		args            string
+		branchExists    bool
+		expectedArgs    []string
+		expectCreated   bool
+		expectError     string
+		withPathForName bool
+		relativeDefault bool
+	}{
+		{
+			name:            "New branch at derived path",
+			args:            `{"branch":"feature/x"}`,
+			expectedArgs:    []string{"worktree", "add", "-b", "feature/x", "/wt/feature-x"},
+			expectCreated:   true,
+			withPathForName: true,
+		},
+		{
+			name:          "Relative path from the repository root",
+			args:          `{"branch":"feature/x","path":"../feature-x"}`,
+			expectedArgs:  []string{"worktree", "add", "-b", "feature/x", "/feature-x"},
+			expectCreated: true,
+		},
+		{
+			name:            "Relative default path from the repository root",
+			args:            `{"branch":"feature/x"}`,
+			expectedArgs:    []string{"worktree", "add", "-b", "feature/x", "/repo/worktrees/feature-x"},
+			expectCreated:   true,
+			withPathForName: true,
+			relativeDefault: true,
+		},
+		{
+			name:         "Existing branch is checked out",
+			args:         `{"branch":"feature","path":"/wt/other"}`,
+			branchExists: true,
+			expectedArgs: []string{"worktree", "add", "/wt/other", "feature"},
+		},
+		{
+			name:         "Explicit create of existing branch fails",
+			args:         `{"branch":"feature","path":"/wt/other","create_branch":true}`,
+			branchExists: true,
+			expectError:  "already exists",
+		},
+		{
+			name:        "Path required without a default",
+			args:        `{"branch":"feature"}`,
+			expectError: "path is required",
+		},
+		{
+			name:        "Branch required",
+			args:        `{"path":"/wt/other"}`,
+			expectError: "branch is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := worktreetest.NewRunner()
+			if tt.branchExists {
+				runner.On("rev-parse", "--verify").Return("abc\n")
+			} else {
+				runner.On("rev-parse", "--verify").Fail("")
+			}
+			runner.On("worktree", "add")
+
+			options := Options{}
+			if tt.withPathForName {
+				options.PathForBranch = func(branch string) string {
+					if tt.relativeDefault {
+						return "worktrees/" + strings.ReplaceAll(branch, "/", "-")
+					}
+					return "/wt/" + strings.ReplaceAll(branch, "/", "-")
+				}
+			}
+			server := NewServer(worktree.NewManagerWithRunner("/repo", "/repo/.git", runner), options)
+
+			result, err := call(t, server, "create_worktree", tt.args)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("create_worktree failed: %v", err)
+			}
+			if !runner.Called(tt.expectedArgs...) {
+				t.Errorf("Expected git %v, got calls %+v", tt.expectedArgs, runner.Calls())
+			}
+			if created := result.(map[string]any)["created_branch"]; created != tt.expectCreated {
+				t.Errorf("created_branch = %v, want %v", created, tt.expectCreated)
+			}
+		})
+	}
+}
+
+func TestRemoveWorktreeTool(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        string
+		removeFails string
+		expectError string
+		expectForce bool
+	}{
+		{name: "Remove with branch", args: `{"path":"/wt/feature","delete_branch":true}`},
+		{name: "Main worktree refused", args: `{"path":"/repo"}`, expectError: "main worktree"},
+		{name: "Unknown path refused", args: `{"path":"/tmp/other"}`, expectError: "no worktree at"},
+		{
+			name:        "Dirty worktree explains force",
+			args:        `{"path":"/wt/feature"}`,
+			removeFails: "fatal: '/wt/feature' contains modified or untracked files, use --force to delete it",
+			expectError: "pass force",
+		},
+		{name: "Forced removal", args: `{"path":"/wt/feature","force":true}`, expectForce: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := worktreetest.NewRunner()
+			runner.On("worktree", "list").Return(testWorktreeList)
+			if tt.removeFails != "" {
+				runner.On("worktree", "remove").Fail(tt.removeFails)
+			} else {
+				runner.On("worktree", "remove")
+			}
+			runner.On("branch", "-d", "feature")
+			server := newTestServer(runner)
+
+			result, err := call(t, server, "remove_worktree", tt.args)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("remove_worktree failed: %v", err)
+			}
+			if runner.Called("worktree", "remove", "--force") != tt.expectForce {
+				t.Errorf("Expected force=%v, got calls %+v", tt.expectForce, runner.Calls())
+			}
+			if strings.Contains(tt.args, "delete_branch") && result.(map[string]any)["deleted_branch"] != "feature" {
+				t.Errorf("Expected branch to be deleted, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestRemoveWorktreeToolEndsSession(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "list").Return(testWorktreeList)
+	runner.On("worktree", "remove")
+
+	var ended []string
+	var endErr error
+	server := NewServer(worktree.NewManagerWithRunner("/repo", "/repo/.git", runner), Options{
+		EndSession: func(ctx context.Context, wt worktree.Worktree) (string, error) {
+			ended = append(ended, wt.Path)
+			if endErr != nil {
+				return "", endErr
+			}
+			return "feature", nil
+		},
+	})
+
+	result, err := call(t, server, "remove_worktree", `{"path":"/wt/feature"}`)
+	if err != nil {
+		t.Fatalf("remove_worktree failed: %v", err)
+	}
+	if len(ended) != 1 || ended[0] != "/wt/feature" || result.(map[string]any)["ended_session"] != "feature" {
+		t.Errorf("Expected the removed worktree's session to end, got %v, %+v", ended, result)
+	}
+
+	// Failing to end the session does not fail the removal
+	endErr = errors.New("failed to kill tmux session 'feature'")
+	result, err = call(t, server, "remove_worktree", `{"path":"/wt/feature"}`)
+	if err != nil {
+		t.Fatalf("remove_worktree failed: %v", err)
+	}
+	if result.(map[string]any)["session_error"] != endErr.Error() {
+		t.Errorf("Expected the session error in the result, got %+v", result)
+	}
+}
+
+func TestStatusAndDiffTools(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "list").Return(testWorktreeList)
+	runner.On("status").Return("# branch.head feature\n# branch.upstream origin/feature\n# branch.ab +1 -0\n? notes.txt\n")
+	runner.On("diff", "--no-color", "--no-ext-diff", "--stat", "main").Return(" README.md | 2 +-\n")
+	server := newTestServer(runner)
+
+	result, err := call(t, server, "worktree_status", `{"path":"/wt/feature"}`)
+	if err != nil {
+		t.Fatalf("worktree_status failed: %v", err)
+	}
+	status := result.(statusInfo)
+	if status.Branch != "feature" || status.Ahead != 1 || status.Untracked != 1 || status.Clean {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if calls := runner.Calls(); calls[len(calls)-1].Dir != "/wt/feature" {
+		t.Errorf("Expected status to run in the worktree, got %+v", calls[len(calls)-1])
+	}
+
+	result, err = call(t, server, "worktree_diff", `{"path":"/wt/feature","base":"main","stat":true}`)
+	if err != nil {
+		t.Fatalf("worktree_diff failed: %v", err)
+	}
+	if diff := result.(map[string]any)["diff"]; diff != " README.md | 2 +-\n" {
+		t.Errorf("Unexpected diff: %q", diff)
+	}
+}
@@ -0,0 +1,313 @@
+// Package task runs a command in a dedicated worktree and records its outcome,
+// so that parallel agent runs can be reviewed and then merged or thrown away.
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// BranchPrefix is prepended to a task's name to form its branch
+const BranchPrefix = "task/"
+
+// Files kept in the worktree's git directory, so they disappear with it
+const (
+	metadataFile = "yosegi-task.json"
+	logFile      = "yosegi-task.log"
+)
+
+// State is the lifecycle stage of a task
+type State string
+
+const (
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+)
+
+// ErrNotFound is returned when no task has the requested name
+var ErrNotFound = errors.New("task not found")
+
+// Task records a command run in its own worktree
+type Task struct {
+	Name         string     `json:"name"`
+	Branch       string     `json:"branch"`
+	Path         string     `json:"path"`
+	Base         string     `json:"base"`        // ref the task started from, as given
+	BaseCommit   string     `json:"base_commit"` // commit Base resolved to
+	Command      []string   `json:"command"`
+	State        State      `json:"state"`
+	ExitCode     int        `json:"exit_code"`
+	Error        string     `json:"error,omitempty"` // why the command or its bookkeeping failed
+	Committed    bool       `json:"committed"`       // whether leftover changes were committed
+	FilesChanged int        `json:"files_changed"`
+	Insertions   int        `json:"insertions"`
+	Deletions    int        `json:"deletions"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+
+	gitDir string
+}
+
+// LogPath returns the file holding the command's output
+func (t *Task) LogPath() string {
+	return filepath.Join(t.gitDir, logFile)
+}
+
+// StartOptions describes a task to run
+type StartOptions struct {
+	Name    string
+	Base    string // ref to branch from, HEAD when empty
+	Path    string // directory for the task's worktree
+	Command []string
+	Stdout  io.Writer // also receives the command's output, besides the log
+	Stderr  io.Writer
+}
+
+// Start creates a worktree on a new branch for the task, runs the command in
+// it and commits whatever the command left behind. A command that fails is
+// recorded in the returned task, not reported as an error.
+func Start(ctx context.Context, manager worktree.Manager, opts StartOptions) (*Task, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("task name is required")
+	}
+	if len(opts.Command) == 0 {
+		return nil, fmt.Errorf("a command to run is required")
+	}
+
+	base := opts.Base
+	if base == "" {
+		base = "HEAD"
+	}
+	baseCommit, err := manager.ResolveCommit(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := BranchPrefix + opts.Name
+	if err := manager.Create(ctx, worktree.CreateOptions{Path: opts.Path, Branch: branch, CreateBranch: true, Base: baseCommit}); err != nil {
+		if errors.Is(err, worktree.ErrBranchExists) {
+			return nil, fmt.Errorf("task '%s' already exists", opts.Name)
+		}
+		return nil, fmt.Errorf("failed to create task worktree: %w", err)
+	}
+
+	// git reports the canonical path, which may differ from the one given
+	path, err := worktreePathForBranch(ctx, manager, branch)
+	if err != nil {
+		return nil, err
+	}
+	gitDir, err := manager.GitDir(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Task{
+		Name:       opts.Name,
+		Branch:     branch,
+		Path:       path,
+		Base:       base,
+		BaseCommit: baseCommit,
+		Command:    opts.Command,
+		State:      StateRunning,
+		StartedAt:  time.Now(),
+		gitDir:     gitDir,
+	}
+	if err := t.save(); err != nil {
+		return nil, err
+	}
+
+	t.ExitCode, err = run(ctx, t, opts)
+	var failures []string
+	if err != nil {
+		failures = append(failures, err.Error())
+	}
+
+	// Record the outcome even when the run was cancelled
+	ctx = context.WithoutCancel(ctx)
+	message := fmt.Sprintf("yosegi task %s: %s", t.Name, strings.Join(t.Command, " "))
+	if t.Committed, err = manager.CommitAll(ctx, path, message); err != nil {
+		failures = append(failures, err.Error())
+	}
+	if stat, err := manager.DiffStat(ctx, path, baseCommit); err != nil {
+		failures = append(failures, err.Error())
+	} else {
+		t.FilesChanged, t.Insertions, t.Deletions = stat.Files, stat.Insertions, stat.Deletions
+	}
+
+	t.State = StateSucceeded
+	if t.ExitCode != 0 || len(failures) > 0 {
+		t.State = StateFailed
+	}
+	t.Error = strings.Join(failures, "; ")
+	finished := time.Now()
+	t.FinishedAt = &finished
+
+	if err := t.save(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// run executes the task's command in its worktree and returns its exit code.
+// The error explains a command that could not be run or was cancelled.
+func run(ctx context.Context, t *Task, opts StartOptions) (int, error) {
+	log, err := os.Create(t.LogPath())
+	if err != nil {
+		return -1, fmt.Errorf("failed to create log: %w", err)
+	}
+	defer func() {
+		_ = log.Close() // Ignore close errors on the log
+	}()
+
+	cmd := exec.CommandContext(ctx, t.Command[0], t.Command[1:]...)
+	cmd.Dir = t.Path
+	cmd.Stdout = teeWriter(log, opts.Stdout)
+	cmd.Stderr = teeWriter(log, opts.Stderr)
+
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return -1, fmt.Errorf("task was cancelled: %w", ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return -1, fmt.Errorf("failed to run command: %w", err)
+	}
+	return 0, nil
+}
+
+func teeWriter(log io.Writer, w io.Writer) io.Writer {
+	if w == nil {
+		return log
+	}
+	return io.MultiWriter(log, w)
+}
+
+// List returns the recorded tasks, oldest first
+func List(ctx context.Context, manager worktree.Manager) ([]Task, error) {
+	worktrees, err := manager.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+	for _, wt := range worktree.WithoutBare(worktrees) {
+		if !strings.HasPrefix(wt.Branch, BranchPrefix) {
+			continue
+		}
+		gitDir, err := manager.GitDir(ctx, wt.Path)
+		if err != nil {
+			continue // a broken worktree is reported by 'yosegi repair', not here
+		}
+		t, err := load(gitDir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // a branch under task/ created by hand
+		}
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].StartedAt.Before(tasks[j].StartedAt)
+	})
+	return tasks, nil
+}
+
+// Find returns the task called name
+func Find(ctx context.Context, manager worktree.Manager, name string) (*Task, error) {
+	tasks, err := List(ctx, manager)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if tasks[i].Name == name {
+			return &tasks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// Accept merges the task's branch into the branch checked out at into, then
+// removes the task's worktree and branch
+func Accept(ctx context.Context, manager worktree.Manager, t *Task, into string) error {
+	if t.State == StateRunning {
+		return fmt.Errorf("task '%s' is still running", t.Name)
+	}
+
+	if err := manager.Merge(ctx, into, t.Branch); err != nil {
+		return err
+	}
+	if err := manager.Remove(ctx, t.Path, worktree.RemoveOptions{}); err != nil {
+		return fmt.Errorf("merged, but failed to remove the task worktree: %w", err)
+	}
+	if err := manager.DeleteBranch(ctx, t.Branch, false); err != nil {
+		return fmt.Errorf("merged, but failed to delete the task branch: %w", err)
+	}
+	return nil
+}
+
+// Discard removes the task's worktree and branch, dropping its changes
+func Discard(ctx context.Context, manager worktree.Manager, t *Task) error {
+	if err := manager.Remove(ctx, t.Path, worktree.RemoveOptions{Force: true}); err != nil {
+		return fmt.Errorf("failed to remove the task worktree: %w", err)
+	}
+	if err := manager.DeleteBranch(ctx, t.Branch, true); err != nil {
+		return fmt.Errorf("failed to delete the task branch: %w", err)
+	}
+	return nil
+}
+
+// worktreePathForBranch finds the worktree that has branch checked out
+func worktreePathForBranch(ctx context.Context, manager worktree.Manager, branch string) (string, error) {
+	worktrees, err := manager.List(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == branch {
+			return wt.Path, nil
+		}
+	}
+	return "", fmt.Errorf("no worktree has branch '%s' checked out", branch)
+}
+
+func (t *Task) save() error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(t.gitDir, metadataFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to save task: %w", err)
+	}
+	return nil
+}
+
+func load(gitDir string) (*Task, error) {
+	data, err := os.ReadFile(filepath.Join(gitDir, metadataFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var t Task
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to read task in '%s': %w", gitDir, err)
+	}
+	t.gitDir = gitDir
+	return &t, nil
+}
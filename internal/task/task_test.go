@@ -0,0 +1,190 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// newTestRepo creates a repository with one commit on main and returns its
+// path and a manager for it
+func newTestRepo(t *testing.T) (string, worktree.Manager) {
+	t.Helper()
+
+	for _, bin := range []string{"git", "sh"} {
+		if _, err := exec.LookPath(bin); err != nil {
+			t.Skipf("%s not available", bin)
+		}
+	}
+
+	// Resolve symlinks (e.g. /tmp on macOS) so paths match git's output
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to resolve temp dir: %v", err)
+	}
+	repoDir := filepath.Join(base, "repo")
+
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main", repoDir},
+		{"-C", repoDir, "config", "user.name", "Yosegi Test"},
+		{"-C", repoDir, "config", "user.email", "test@example.com"},
+		{"-C", repoDir, "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	manager, err := worktree.NewManagerAt(context.Background(), repoDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	return repoDir, manager
+}
+
+func TestStartSucceeded(t *testing.T) {
+	repoDir, manager := newTestRepo(t)
+	ctx := context.Background()
+
+	var stdout bytes.Buffer
+	task, err := Start(ctx, manager, StartOptions{
+		Name:    "feature",
+		Path:    filepath.Join(filepath.Dir(repoDir), "task-feature"),
+		Command: []string{"sh", "-c", "echo working; printf 'a\\nb\\n' > result.txt"},
+		Stdout:  &stdout,
+	})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if task.State != StateSucceeded || task.ExitCode != 0 || task.Error != "" {
+		t.Errorf("Expected success, got %+v", task)
+	}
+	if task.Branch != "task/feature" || task.Base != "HEAD" || len(task.BaseCommit) != 40 {
+		t.Errorf("Unexpected branch or base: %+v", task)
+	}
+	if !task.Committed || task.FilesChanged != 1 || task.Insertions != 2 {
+		t.Errorf("Expected the result to be committed and counted, got %+v", task)
+	}
+	if task.FinishedAt == nil {
+		t.Error("Expected finish time to be recorded")
+	}
+
+	if stdout.String() != "working\n" {
+		t.Errorf("Expected output to be passed through, got %q", stdout.String())
+	}
+	log, err := os.ReadFile(task.LogPath())
+	if err != nil || string(log) != "working\n" {
+		t.Errorf("Expected output in the log, got %q (err: %v)", log, err)
+	}
+
+	found, err := Find(ctx, manager, "feature")
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if found.State != StateSucceeded || found.Path != task.Path || found.LogPath() != task.LogPath() {
+		t.Errorf("Expected the saved task, got %+v", found)
+	}
+
+	if _, err := Start(ctx, manager, StartOptions{Name: "feature", Path: filepath.Join(filepath.Dir(repoDir), "again"), Command: []string{"true"}}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected duplicate task to be rejected, got %v", err)
+	}
+
+	if err := Accept(ctx, manager, found, repoDir); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "result.txt")); err != nil {
+		t.Errorf("Expected task result to be merged: %v", err)
+	}
+	if _, err := os.Stat(task.Path); !os.IsNotExist(err) {
+		t.Errorf("Expected task worktree to be removed, got %v", err)
+	}
+	if _, err := Find(ctx, manager, "feature"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected accepted task to be gone, got %v", err)
+	}
+}
+
+func TestStartFailed(t *testing.T) {
+	repoDir, manager := newTestRepo(t)
+	ctx := context.Background()
+
+	task, err := Start(ctx, manager, StartOptions{
+		Name:    "broken",
+		Base:    "main",
+		Path:    filepath.Join(filepath.Dir(repoDir), "task-broken"),
+		Command: []string{"sh", "-c", "echo oops >&2; exit 3"},
+	})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if task.State != StateFailed || task.ExitCode != 3 || task.Committed {
+		t.Errorf("Expected failure with exit code 3, got %+v", task)
+	}
+	if log, _ := os.ReadFile(task.LogPath()); string(log) != "oops\n" {
+		t.Errorf("Expected stderr in the log, got %q", log)
+	}
+
+	missing, err := Start(ctx, manager, StartOptions{
+		Name:    "missing",
+		Path:    filepath.Join(filepath.Dir(repoDir), "task-missing"),
+		Command: []string{"yosegi-no-such-command"},
+	})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if missing.State != StateFailed || missing.ExitCode != -1 || !strings.Contains(missing.Error, "failed to run command") {
+		t.Errorf("Expected a command that cannot run to be recorded, got %+v", missing)
+	}
+
+	tasks, err := List(ctx, manager)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].Name != "broken" || tasks[1].Name != "missing" {
+		t.Errorf("Expected both tasks oldest first, got %+v", tasks)
+	}
+
+	if err := Discard(ctx, manager, task); err != nil {
+		t.Fatalf("Discard failed: %v", err)
+	}
+	if _, err := Find(ctx, manager, "broken"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected discarded task to be gone, got %v", err)
+	}
+}
+
+func TestStartValidation(t *testing.T) {
+	_, manager := newTestRepo(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		opts     StartOptions
+		expected string
+	}{
+		{name: "Missing name", opts: StartOptions{Path: "x", Command: []string{"true"}}, expected: "name is required"},
+		{name: "Missing command", opts: StartOptions{Name: "x", Path: "x"}, expected: "command to run is required"},
+		{name: "Unknown base", opts: StartOptions{Name: "x", Path: "x", Base: "nope", Command: []string{"true"}}, expected: "not a commit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Start(ctx, manager, tt.opts); err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestAcceptRunningTask(t *testing.T) {
+	err := Accept(context.Background(), nil, &Task{Name: "busy", State: StateRunning}, "/repo")
+	if err == nil || !strings.Contains(err.Error(), "still running") {
+		t.Errorf("Expected running task to be refused, got %v", err)
+	}
+}
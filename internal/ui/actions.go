@@ -0,0 +1,467 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yagi2/yosegi/internal/launch"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// logCommits is how many commits the log view loads
+const logCommits = 50
+
+// ActionOptions gives the actions menu what it needs to act on worktrees
+type ActionOptions struct {
+	Context context.Context
+	Manager worktree.Manager
+	Remote  string // fetched before pulling
+	Editor  string // the editor setting, see launch.Editor
+}
+
+// selectorMode is what the selector's keys currently drive
+type selectorMode int
+
+const (
+	modeList    selectorMode = iota
+	modeMenu                 // the actions menu is open
+	modeMove                 // asking where to move the worktree
+	modeConfirm              // asking before deleting the branch
+	modeLog                  // showing the worktree's history
+)
+
+// closeKey leaves the actions menu and the views opened from it
+var closeKey = key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "close"))
+
+// menuItem is an entry of the actions menu
+type menuItem struct {
+	key   string // shortcut while the menu is open
+	label string
+	run   func(m SelectorModel, wt worktree.Worktree) (SelectorModel, tea.Cmd)
+}
+
+// menuItems returns the entries of the actions menu for wt
+func menuItems(wt worktree.Worktree) []menuItem {
+	lock := menuItem{key: "l", label: "Lock", run: toggleLock}
+	if wt.Locked {
+		lock.label = "Unlock"
+	}
+	return []menuItem{
+		{key: "e", label: "Open in editor", run: openEditor},
+		{key: "t", label: "Open shell", run: openShell},
+		{key: "y", label: "Copy path", run: copyPath},
+		lock,
+		{key: "m", label: "Move", run: startMove},
+		{key: "g", label: "Show log", run: showLog},
+		{key: "f", label: "Fetch and pull", run: pull},
+		{key: "b", label: "Delete branch only", run: confirmDropBranch},
+	}
+}
+
+// actionMsg reports the outcome of an action, after which the list is
+// reloaded
+type actionMsg struct {
+	status string
+	err    error
+	focus  string // path to highlight after reloading, when it changed
+}
+
+// reloadMsg delivers the worktrees listed again after an action
+type reloadMsg struct {
+	worktrees []worktree.Worktree
+	focus     string
+	err       error
+}
+
+// logMsg delivers the history shown by the log view
+type logMsg struct {
+	path    string
+	commits []worktree.Commit
+	err     error
+}
+
+// WithActions enables the actions menu on the highlighted worktree. Actions
+// run without leaving the selector, which reloads the list after each one
+// when WithReload is set.
+func (m SelectorModel) WithActions(opts ActionOptions) SelectorModel {
+	m.actions = &opts
+	return m
+}
+
+// runAction returns a command running fn outside the UI loop and reporting
+// its outcome
+func runAction(fn func() actionMsg) tea.Cmd {
+	return func() tea.Msg {
+		return fn()
+	}
+}
+
+// reload returns a command listing the worktrees again
+func (m SelectorModel) reload(focus string) tea.Cmd {
+	if m.reloadFn == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		worktrees, err := m.reloadFn()
+		return reloadMsg{worktrees: worktrees, focus: focus, err: err}
+	}
+}
+
+// withWorktrees replaces the listed worktrees, keeping the cursor on the
+// highlighted worktree, or moving it to focus when set. Loaded previews are
+// dropped, since the action may have changed them.
+func (m SelectorModel) withWorktrees(worktrees []worktree.Worktree, focus string) SelectorModel {
+	if focus == "" && len(m.worktrees) > 0 {
+		focus = m.worktrees[m.cursor].Path
+	}
+
+	if m.sortable {
+		m.unsorted = worktrees
+		m.worktrees = SortWorktrees(worktrees, m.sort)
+	} else {
+		m.worktrees = worktrees
+	}
+	m.cursor = max(min(m.cursor, len(m.worktrees)-1), 0)
+	for i, wt := range m.worktrees {
+		if wt.Path == focus {
+			m.cursor = i
+		}
+	}
+
+	if m.previews != nil {
+		m.previews = make(map[string]*Preview)
+	}
+	m.offset = m.scrolled(m.listLines(), m.layout().listRows)
+	return m
+}
+
+// withStatus shows the outcome of an action below the list
+func (m SelectorModel) withStatus(status string, err error) SelectorModel {
+	m.status, m.statusErr = status, err != nil
+	if err != nil {
+		m.status = err.Error()
+	}
+	return m
+}
+
+// updateAction handles the messages of running actions
+func (m SelectorModel) updateAction(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case actionMsg:
+		m = m.withStatus(msg.status, msg.err)
+		return m, m.reload(msg.focus)
+
+	case reloadMsg:
+		if msg.err != nil {
+			return m.withStatus("", fmt.Errorf("failed to reload worktrees: %w", msg.err)), nil
+		}
+		m = m.withWorktrees(msg.worktrees, msg.focus)
+		return m, tea.Batch(m.loadPreview(), m.loadSessions())
+
+	case logMsg:
+		if m.mode != modeLog || len(m.worktrees) == 0 || m.worktrees[m.cursor].Path != msg.path {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.mode = modeList
+			return m.withStatus("", msg.err), nil
+		}
+		m.log = msg.commits
+	}
+	return m, nil
+}
+
+// updateMode handles keys while the actions menu or a view opened from it
+// is showing. Quitting works in all of them.
+func (m SelectorModel) updateMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == alwaysQuit {
+		m.quitting, m.done = true, true
+		return m, tea.Quit
+	}
+
+	wt := m.worktrees[m.cursor]
+	switch m.mode {
+	case modeMenu:
+		items := menuItems(wt)
+		switch {
+		case key.Matches(msg, closeKey), key.Matches(msg, keys.Quit), key.Matches(msg, keys.Actions):
+			m.mode = modeList
+		case key.Matches(msg, keys.Up):
+			m.menu = (m.menu - 1 + len(items)) % len(items)
+		case key.Matches(msg, keys.Down):
+			m.menu = (m.menu + 1) % len(items)
+		case key.Matches(msg, keys.Enter):
+			m.mode = modeList
+			return items[m.menu].run(m, wt)
+		default:
+			for _, item := range items {
+				if msg.String() == item.key {
+					m.mode = modeList
+					return item.run(m, wt)
+				}
+			}
+		}
+
+	case modeMove:
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.mode = modeList
+		case tea.KeyEnter:
+			m.mode = modeList
+			return m.move(wt, strings.TrimSpace(m.prompt.Value()))
+		default:
+			var cmd tea.Cmd
+			m.prompt, cmd = m.prompt.Update(msg)
+			return m, cmd
+		}
+
+	case modeConfirm:
+		switch {
+		case key.Matches(msg, confirmKeys.Yes):
+			m.mode = modeList
+			return m.dropBranch(wt)
+		case key.Matches(msg, confirmKeys.No), key.Matches(msg, confirmKeys.Quit):
+			m.mode = modeList
+		}
+
+	case modeLog:
+		if key.Matches(msg, closeKey) || key.Matches(msg, keys.Quit) {
+			m.mode = modeList
+		}
+	}
+	return m, nil
+}
+
+// openEditor opens the worktree in the configured editor
+func openEditor(m SelectorModel, wt worktree.Worktree) (SelectorModel, tea.Cmd) {
+	cmd, err := launch.Editor(m.actions.Editor, wt)
+	if err != nil {
+		return m.withStatus("", err), nil
+	}
+
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			return actionMsg{err: fmt.Errorf("editor failed: %w", err)}
+		}
+		return actionMsg{status: fmt.Sprintf("Opened %s in %s", wt.Path, filepath.Base(cmd.Args[0]))}
+	})
+}
+
+// openShell starts $SHELL in the worktree; the list returns when it exits
+func openShell(m SelectorModel, wt worktree.Worktree) (SelectorModel, tea.Cmd) {
+	return m, tea.ExecProcess(launch.Shell(wt), func(err error) tea.Msg {
+		// A shell's exit status is that of its last command, so it is not
+		// reported as a failure
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			return actionMsg{err: fmt.Errorf("shell failed: %w", err)}
+		}
+		return actionMsg{status: "Left the shell in " + wt.Path}
+	})
+}
+
+// copyPath copies the worktree's path to the system clipboard
+func copyPath(m SelectorModel, wt worktree.Worktree) (SelectorModel, tea.Cmd) {
+	return m, runAction(func() actionMsg {
+		if err := clipboard.WriteAll(wt.Path); err != nil {
+			return actionMsg{err: fmt.Errorf("failed to copy path: %w", err)}
+		}
+		return actionMsg{status: "Copied " + wt.Path}
+	})
+}
+
+// toggleLock locks the worktree, or unlocks it when it is locked
+func toggleLock(m SelectorModel, wt worktree.Worktree) (SelectorModel, tea.Cmd) {
+	ctx, manager := m.actions.Context, m.actions.Manager
+	return m, runAction(func() actionMsg {
+		if wt.Locked {
+			if err := manager.Unlock(ctx, wt.Path); err != nil {
+				return actionMsg{err: err}
+			}
+			return actionMsg{status: "Unlocked " + wt.Path}
+		}
+		if err := manager.Lock(ctx, wt.Path, ""); err != nil {
+			return actionMsg{err: err}
+		}
+		return actionMsg{status: "Locked " + wt.Path}
+	})
+}
+
+// startMove asks where to move the worktree
+func startMove(m SelectorModel, wt worktree.Worktree) (SelectorModel, tea.Cmd) {
+	if wt.IsCurrent {
+		return m.withStatus("", errors.New("cannot move current worktree")), nil
+	}
+
+	m.prompt = textinput.New()
+	m.prompt.Prompt = "Move to: "
+	m.prompt.SetValue(wt.Path)
+	m.mode = modeMove
+	return m, m.prompt.Focus()
+}
+
+// move moves the worktree to target, taken relative to the working
+// directory as in the move command
+func (m SelectorModel) move(wt worktree.Worktree, target string) (SelectorModel, tea.Cmd) {
+	if absTarget, err := filepath.Abs(target); err == nil && target != "" {
+		target = absTarget
+	}
+	if target == "" || target == wt.Path {
+		return m, nil
+	}
+
+	ctx, manager := m.actions.Context, m.actions.Manager
+
+	return m, runAction(func() actionMsg {
+		if err := manager.Move(ctx, wt.Path, target, worktree.MoveOptions{}); err != nil {
+			return actionMsg{err: fmt.Errorf("failed to move worktree: %w", err)}
+		}
+		return actionMsg{status: "Moved worktree to " + target, focus: target}
+	})
+}
+
+// showLog opens the log view and loads the worktree's history
+func showLog(m SelectorModel, wt worktree.Worktree) (SelectorModel, tea.Cmd) {
+	ctx, manager := m.actions.Context, m.actions.Manager
+	m.mode, m.log = modeLog, nil
+	return m, func() tea.Msg {
+		commits, err := manager.Log(ctx, wt.Path, logCommits)
+		return logMsg{path: wt.Path, commits: commits, err: err}
+	}
+}
+
+// pull fetches the remote and fast-forwards the worktree's branch when it is
+// safe to
+func pull(m SelectorModel, wt worktree.Worktree) (SelectorModel, tea.Cmd) {
+	ctx, manager, remote := m.actions.Context, m.actions.Manager, m.actions.Remote
+	m = m.withStatus(fmt.Sprintf("Fetching %s...", remote), nil)
+	return m, runAction(func() actionMsg {
+		if err := manager.Fetch(ctx, remote); err != nil {
+			return actionMsg{err: err}
+		}
+		result, err := manager.Sync(ctx, wt.Path, worktree.SyncOptions{})
+		if err != nil {
+			return actionMsg{err: err}
+		}
+		return actionMsg{status: describePull(wt, result)}
+	})
+}
+
+// describePull summarises a pull's outcome for the status line
+func describePull(wt worktree.Worktree, result *worktree.SyncResult) string {
+	switch result.Outcome {
+	case worktree.SyncUpdated:
+		return fmt.Sprintf("Pulled %d commit(s) into %s", result.Pulled, wt.Branch)
+	case worktree.SyncDirty:
+		return fmt.Sprintf("Fetched; %s has uncommitted changes and was not pulled", wt.Branch)
+	case worktree.SyncDiverged:
+		return fmt.Sprintf("Fetched; %s has diverged from its upstream and was not pulled", wt.Branch)
+	case worktree.SyncNoUpstream:
+		return fmt.Sprintf("Fetched; %s has no upstream branch", wt.Branch)
+	case worktree.SyncDetached:
+		return "Fetched; HEAD is detached, nothing to pull"
+	}
+	return fmt.Sprintf("Fetched; %s is up to date", wt.Branch)
+}
+
+// confirmDropBranch asks before deleting the worktree's branch
+func confirmDropBranch(m SelectorModel, wt worktree.Worktree) (SelectorModel, tea.Cmd) {
+	if wt.Bare || wt.Branch == "" || wt.Branch == "(detached)" {
+		return m.withStatus("", errors.New("the worktree has no branch to delete")), nil
+	}
+	m.mode = modeConfirm
+	return m, nil
+}
+
+// dropBranch deletes the worktree's branch, leaving the worktree detached
+func (m SelectorModel) dropBranch(wt worktree.Worktree) (SelectorModel, tea.Cmd) {
+	ctx, manager := m.actions.Context, m.actions.Manager
+	return m, runAction(func() actionMsg {
+		if err := manager.DropBranch(ctx, wt.Path, wt.Branch, false); err != nil {
+			return actionMsg{err: err}
+		}
+		return actionMsg{status: fmt.Sprintf("Deleted branch '%s'; %s is now detached", wt.Branch, wt.Path)}
+	})
+}
+
+// renderMenu renders the actions menu in a box shown in place of the list
+func (m SelectorModel) renderMenu(width int) string {
+	wt := m.worktrees[m.cursor]
+	lines := []string{SubtitleStyle.Render(truncate("Actions for "+wt.Branch, max(width-4, 0))), ""}
+	for i, item := range menuItems(wt) {
+		label := fmt.Sprintf("%s  %s", item.key, item.label)
+		if i == m.menu {
+			lines = append(lines, SelectedItemStyle.Render(label))
+		} else {
+			lines = append(lines, NormalItemStyle.Render(label))
+		}
+	}
+	return PreviewStyle.Render(strings.Join(lines, "\n"))
+}
+
+// renderLog renders the highlighted worktree's history in a box of the given
+// outer width and at most height rows when height is positive
+func (m SelectorModel) renderLog(width, height int) string {
+	wt := m.worktrees[m.cursor]
+	contentWidth := 0
+	if width > 0 {
+		// The box's border and padding take 4 columns
+		contentWidth = max(width-4, 10)
+	}
+
+	lines := []string{SubtitleStyle.Render(truncate("Log of "+wt.Branch, contentWidth)), ""}
+	switch {
+	case m.log == nil:
+		lines = append(lines, NormalStyle.Render("Loading..."))
+	case len(m.log) == 0:
+		lines = append(lines, NormalStyle.Render("No commits"))
+	}
+	// The box's border and the title take 4 rows
+	shown := len(m.log)
+	if height > 0 {
+		shown = min(shown, max(height-4, 1))
+	}
+	now := time.Now()
+	for _, c := range m.log[:shown] {
+		line := fmt.Sprintf("%s %s (%s, %s)", c.Hash, c.Subject, c.Author, relativeTime(c.Date, now))
+		lines = append(lines, NormalStyle.Render(truncate(line, contentWidth)))
+	}
+	return PreviewStyle.Render(strings.Join(lines, "\n"))
+}
+
+// footerView renders what goes below the list: the outcome of the last
+// action, then the move prompt, the question before deleting a branch or
+// the help line
+func (m SelectorModel) footerView(width int) string {
+	var parts []string
+	if m.status != "" {
+		style := SuccessStyle
+		if m.statusErr {
+			style = ErrorStyle
+		}
+		parts = append(parts, style.Render(truncate(m.status, max(width-2, 0))))
+	}
+
+	switch {
+	case m.mode == modeMove:
+		parts = append(parts, lipgloss.NewStyle().Margin(1, 0).Render(m.prompt.View()))
+	case m.mode == modeConfirm && len(m.worktrees) > 0:
+		wt := m.worktrees[m.cursor]
+		question := fmt.Sprintf("Delete branch '%s' and detach %s? (%s/%s)", wt.Branch, wt.Path,
+			confirmKeys.Yes.Help().Key, confirmKeys.No.Help().Key)
+		parts = append(parts, WarningStyle.Margin(1, 0).Render(truncate(question, max(width-2, 0))))
+	default:
+		parts = append(parts, m.helpView(width))
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,324 @@
+package ui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yagi2/yosegi/pkg/worktree"
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
+)
+
+// pressKeys sends each character of s to model as a key press, returning the
+// model and the command of the last one
+func pressKeys(model tea.Model, s string) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, r := range s {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+		if r == ' ' {
+			msg.Type = tea.KeySpace
+		}
+		model, cmd = model.Update(msg)
+	}
+	return model, cmd
+}
+
+// actionSelector returns a selector with actions on main and feature, the
+// highlighted one, run through runner and reloading as reloaded
+func actionSelector(runner *worktreetest.Runner, reloaded []worktree.Worktree) tea.Model {
+	worktrees := []worktree.Worktree{
+		{Path: "/repo", Branch: "main", IsCurrent: true},
+		{Path: "/repo/feature", Branch: "feature"},
+	}
+	model := NewSelector(worktrees, "Test", "select", false).WithActions(ActionOptions{
+		Context: context.Background(),
+		Manager: worktree.NewManagerWithRunner("/repo", "/repo/.git", runner),
+		Remote:  "origin",
+	}).WithReload(func() ([]worktree.Worktree, error) { return reloaded, nil })
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	return updated
+}
+
+func TestSelectorActionsMenu(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "lock").Return("")
+	model := actionSelector(runner, []worktree.Worktree{
+		{Path: "/repo", Branch: "main", IsCurrent: true},
+		{Path: "/repo/feature", Branch: "feature", Locked: true},
+	})
+
+	if view := model.View(); !strings.Contains(view, "a/space actions") {
+		t.Errorf("Expected the actions key in the help line:\n%s", view)
+	}
+
+	// Space opens the menu in place of the list
+	model, _ = pressKeys(model, " ")
+	view := model.View()
+	for _, expected := range []string{"Actions for feature", "Open in editor", "Copy path", "Lock", "Fetch and pull", "Delete branch only", "enter run", "esc close"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected %q in the menu:\n%s", expected, view)
+		}
+	}
+	if strings.Contains(view, "/repo/feature") {
+		t.Errorf("Expected the menu to replace the list:\n%s", view)
+	}
+
+	// Navigation moves through the menu and esc closes it
+	model, _ = pressKeys(model, "j")
+	if menu := model.(SelectorModel).menu; menu != 1 {
+		t.Errorf("Expected the second entry highlighted, got %d", menu)
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m := model.(SelectorModel); m.mode != modeList || m.cursor != 1 {
+		t.Errorf("Expected esc to return to the list on feature, got mode %d, cursor %d", m.mode, m.cursor)
+	}
+
+	// An entry's key runs it, then the list is reloaded
+	model, cmd := pressKeys(model, "al")
+	if cmd == nil {
+		t.Fatal("Expected the lock action to run")
+	}
+	model, cmd = model.Update(cmd())
+	if !runner.Called("worktree", "lock", "/repo/feature") {
+		t.Errorf("Expected the worktree to be locked, got %+v", runner.Calls())
+	}
+	if cmd == nil {
+		t.Fatal("Expected the list to be reloaded")
+	}
+	model, _ = model.Update(cmd())
+	view = model.View()
+	if !strings.Contains(view, "Locked /repo/feature") || !strings.Contains(view, GetLockIcon()) {
+		t.Errorf("Expected the outcome and the reloaded list:\n%s", view)
+	}
+	if m := model.(SelectorModel); m.mode != modeList || m.cursor != 1 {
+		t.Errorf("Expected the list on feature after the action, got mode %d, cursor %d", m.mode, m.cursor)
+	}
+
+	// The next key clears the outcome, and the menu offers to unlock
+	model, _ = pressKeys(model, "a")
+	if view := model.View(); strings.Contains(view, "Locked /repo/feature") || !strings.Contains(view, "Unlock") {
+		t.Errorf("Expected the menu to offer unlocking:\n%s", view)
+	}
+
+	// ctrl+c still quits
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil {
+		t.Error("Expected ctrl+c to quit from the menu")
+	}
+}
+
+func TestSelectorActionsDisabled(t *testing.T) {
+	worktrees := []worktree.Worktree{{Path: "/repo", Branch: "main"}}
+	model := NewSelector(worktrees, "Test", "select", false)
+
+	if strings.Contains(model.View(), "actions") {
+		t.Error("Expected no actions key without WithActions")
+	}
+	updated, _ := pressKeys(model, " ")
+	if updated.(SelectorModel).mode != modeList {
+		t.Error("Expected the actions key to be ignored without WithActions")
+	}
+}
+
+func TestSelectorMoveAction(t *testing.T) {
+	// A bare layout, used from the directory holding .bare and the worktrees
+	root := t.TempDir()
+	t.Chdir(root)
+	feature, renamed := filepath.Join(root, "feature"), filepath.Join(root, "renamed")
+
+	runner := worktreetest.NewRunner()
+	runner.On("worktree", "move").Return("")
+	worktrees := []worktree.Worktree{
+		{Path: filepath.Join(root, "main"), Branch: "main", IsCurrent: true},
+		{Path: feature, Branch: "feature"},
+	}
+	reloaded := []worktree.Worktree{
+		{Path: filepath.Join(root, "main"), Branch: "main", IsCurrent: true},
+		{Path: feature, Branch: "feature"},
+		{Path: renamed, Branch: "feature"},
+	}
+	bareDir := filepath.Join(root, ".bare")
+	var model tea.Model = NewSelector(worktrees, "Test", "select", false).WithActions(ActionOptions{
+		Context: context.Background(),
+		Manager: worktree.NewManagerWithRunner(bareDir, bareDir, runner),
+	}).WithReload(func() ([]worktree.Worktree, error) { return reloaded, nil })
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	model, _ = pressKeys(model, "am")
+	if view := model.View(); !strings.Contains(view, "Move to: "+feature) {
+		t.Errorf("Expected a prompt with the current path:\n%s", view)
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.(SelectorModel).mode != modeList || len(runner.Calls()) != 0 {
+		t.Error("Expected esc to cancel the move")
+	}
+
+	// Relative paths are taken from the working directory, as in yosegi
+	// move, not from the bare repository
+	model, _ = pressKeys(model, "am")
+	m := model.(SelectorModel)
+	m.prompt.SetValue("renamed")
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected the move to run")
+	}
+	model, cmd = model.Update(cmd())
+	if !runner.Called("worktree", "move", feature, renamed) {
+		t.Errorf("Expected the worktree to move next to it, got %+v", runner.Calls())
+	}
+	model, _ = model.Update(cmd())
+	if cursor := model.(SelectorModel).cursor; cursor != 2 {
+		t.Errorf("Expected the cursor to follow the moved worktree, got %d", cursor)
+	}
+
+	// The current worktree cannot be moved from under the shell
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyHome})
+	model, _ = pressKeys(model, "am")
+	if view := model.View(); !strings.Contains(view, "cannot move current worktree") {
+		t.Errorf("Expected moving the current worktree to be refused:\n%s", view)
+	}
+}
+
+func TestSelectorDropBranchAction(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("switch").Return("")
+	runner.On("branch", "-d").Return("")
+	model := actionSelector(runner, nil)
+
+	model, _ = pressKeys(model, "ab")
+	if view := model.View(); !strings.Contains(view, "Delete branch 'feature' and detach /repo/feature? (y/n)") {
+		t.Errorf("Expected to be asked first:\n%s", view)
+	}
+	model, cmd := pressKeys(model, "n")
+	if cmd != nil || model.(SelectorModel).mode != modeList {
+		t.Error("Expected n to cancel")
+	}
+
+	model, cmd = pressKeys(model, "aby")
+	if cmd == nil {
+		t.Fatal("Expected the branch to be deleted")
+	}
+	model, _ = model.Update(cmd())
+	if !runner.Called("switch", "--quiet", "--detach") || !runner.Called("branch", "-d", "feature") {
+		t.Errorf("Expected the worktree to be detached and its branch deleted, got %+v", runner.Calls())
+	}
+	if view := model.View(); !strings.Contains(view, "Deleted branch 'feature'") {
+		t.Errorf("Expected the outcome in the view:\n%s", view)
+	}
+
+	// git's refusal is shown in the list
+	runner = worktreetest.NewRunner()
+	runner.On("switch").Return("")
+	runner.On("branch", "-d").Fail("error: The branch 'feature' is not fully merged.")
+	model, cmd = pressKeys(actionSelector(runner, nil), "aby")
+	model, _ = model.Update(cmd())
+	if view := model.View(); !strings.Contains(view, "branch 'feature' is not fully merged") {
+		t.Errorf("Expected the error in the view:\n%s", view)
+	}
+	if !runner.Called("switch", "--quiet", "feature") {
+		t.Errorf("Expected the branch to be checked out again, got %+v", runner.Calls())
+	}
+}
+
+func TestSelectorLogAction(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("log").Return("abc1234\x00Ada\x001700000000\x00Add login\n")
+	model := actionSelector(runner, nil)
+
+	model, cmd := pressKeys(model, "ag")
+	if view := model.View(); !strings.Contains(view, "Log of feature") || !strings.Contains(view, "Loading...") {
+		t.Errorf("Expected the log view to open:\n%s", view)
+	}
+	model, _ = model.Update(cmd())
+	if view := model.View(); !strings.Contains(view, "abc1234 Add login (Ada,") {
+		t.Errorf("Expected the commits in the log view:\n%s", view)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view := model.View(); !strings.Contains(view, "/repo/feature") {
+		t.Errorf("Expected esc to return to the list:\n%s", view)
+	}
+}
+
+func TestSelectorPullAction(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("fetch").Return("")
+	runner.On("status").Return("# branch.head feature\n# branch.upstream origin/feature\n# branch.ab +0 -2\n")
+	runner.On("merge", "--ff-only").Return("")
+	model := actionSelector(runner, nil)
+
+	model, cmd := pressKeys(model, "af")
+	if view := model.View(); !strings.Contains(view, "Fetching origin...") {
+		t.Errorf("Expected progress while fetching:\n%s", view)
+	}
+	model, _ = model.Update(cmd())
+	if !runner.Called("fetch", "--quiet", "--prune", "origin") {
+		t.Errorf("Expected origin to be fetched, got %+v", runner.Calls())
+	}
+	if view := model.View(); !strings.Contains(view, "Pulled 2 commit(s) into feature") {
+		t.Errorf("Expected the outcome in the view:\n%s", view)
+	}
+}
+
+func TestSelectorEditorAction(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	model, cmd := pressKeys(actionSelector(worktreetest.NewRunner(), nil), "ae")
+	if cmd != nil || !strings.Contains(model.View(), "set $VISUAL or $EDITOR") {
+		t.Errorf("Expected to be told to set an editor:\n%s", model.View())
+	}
+
+	t.Setenv("EDITOR", "vim -p")
+	if _, cmd := pressKeys(actionSelector(worktreetest.NewRunner(), nil), "ae"); cmd == nil {
+		t.Error("Expected the editor to be started")
+	}
+}
+
+func TestSelectorOpenKey(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	model := actionSelector(worktreetest.NewRunner(), nil)
+	if view := model.View(); !strings.Contains(view, "o open") {
+		t.Errorf("Expected the open key in the help line:\n%s", view)
+	}
+
+	// o opens the highlighted worktree without the menu
+	model, cmd := pressKeys(model, "o")
+	if cmd != nil || !strings.Contains(model.View(), "set $VISUAL or $EDITOR") {
+		t.Errorf("Expected to be told to set an editor:\n%s", model.View())
+	}
+
+	// The editor setting is used before the environment
+	m := model.(SelectorModel)
+	m.actions.Editor = "code {{.Path"
+	model, _ = pressKeys(m, "o")
+	if view := model.View(); !strings.Contains(view, "invalid editor template") {
+		t.Errorf("Expected the editor setting to be used:\n%s", view)
+	}
+
+	m = model.(SelectorModel)
+	m.actions.Editor = "code {{.Path}}"
+	if _, cmd := pressKeys(m, "o"); cmd == nil {
+		t.Error("Expected the editor to be started")
+	}
+}
+
+func TestDescribePull(t *testing.T) {
+	wt := worktree.Worktree{Path: "/repo/feature", Branch: "feature"}
+	tests := []struct {
+		result   worktree.SyncResult
+		expected string
+	}{
+		{result: worktree.SyncResult{Outcome: worktree.SyncUpdated, Pulled: 3}, expected: "Pulled 3 commit(s) into feature"},
+		{result: worktree.SyncResult{Outcome: worktree.SyncUpToDate}, expected: "Fetched; feature is up to date"},
+		{result: worktree.SyncResult{Outcome: worktree.SyncDirty}, expected: "Fetched; feature has uncommitted changes and was not pulled"},
+		{result: worktree.SyncResult{Outcome: worktree.SyncNoUpstream}, expected: "Fetched; feature has no upstream branch"},
+	}
+
+	for _, tt := range tests {
+		if got := describePull(wt, &tt.result); got != tt.expected {
+			t.Errorf("describePull(%s) = %q, expected %q", tt.result.Outcome, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// Session lets an operation started from the app talk to the user while it
+// runs: it asks questions on the confirmation screen, fills in forms on the
+// form screen and reports progress on the progress screen
+type Session interface {
+	Confirm(title, message string) bool
+	Form(form InputModel) (InputResult, error)
+	Report(message string)
+}
+
+// Operation is work started from the app's list. It runs outside the UI
+// loop; once it returns, the list is refreshed and shows the last message it
+// reported, or its error. ctx is cancelled when the user quits meanwhile.
+type Operation func(ctx context.Context, s Session) error
+
+// AppHandlers carry out what the list's create, delete and move keys ask
+// for. A missing handler ends the app with the selector's result instead.
+type AppHandlers struct {
+	Create func(ctx context.Context, s Session) error
+	Remove func(ctx context.Context, s Session, wt worktree.Worktree) error
+	Move   func(ctx context.Context, s Session, wt worktree.Worktree) error
+}
+
+// appScreen is the screen the app shows
+type appScreen int
+
+const (
+	screenList appScreen = iota
+	screenForm
+	screenConfirm
+	screenProgress
+)
+
+// AppModel is a long-lived session around the worktree list. It routes
+// between the list and the form, confirmation and progress screens of the
+// operations started from it, returning to the refreshed list after each,
+// and only ends on quit or when a worktree is selected.
+type AppModel struct {
+	list     SelectorModel
+	handlers AppHandlers
+	screen   appScreen
+
+	// Operations and the list's actions run in ctx, which is cancelled when
+	// the app ends
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running chan struct{} // closed when the running operation returns
+
+	form    InputModel
+	confirm ConfirmModel
+
+	session  *appSession // of the running operation, nil when idle
+	progress string      // last message the operation reported
+	spinner  spinner.Model
+
+	result SelectionResult
+}
+
+// NewApp returns an app around list whose operations are run by handlers in
+// a context derived from ctx. Call Close once the program has ended.
+func NewApp(ctx context.Context, list SelectorModel, handlers AppHandlers) AppModel {
+	ctx, cancel := context.WithCancel(ctx)
+	if list.actions != nil {
+		actions := *list.actions
+		actions.Context = ctx
+		list.actions = &actions
+	}
+	return AppModel{
+		list:     list,
+		handlers: handlers,
+		ctx:      ctx,
+		cancel:   cancel,
+		spinner:  spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(lipgloss.NewStyle().Foreground(Primary))),
+		result:   SelectionResult{Action: "quit"},
+	}
+}
+
+// Messages from the running operation's session
+type (
+	confirmRequest struct{ title, message string }
+	formRequest    struct{ form InputModel }
+	reportMsg      struct{ message string }
+	operationDone  struct{ err error }
+)
+
+// appSession is the Session of an operation running in the app. Requests
+// go to the UI loop over events and block until they are answered, or until
+// ctx ends because the app was quit.
+type appSession struct {
+	ctx      context.Context
+	events   chan tea.Msg
+	confirms chan bool
+	forms    chan InputResult
+}
+
+func (s *appSession) Confirm(title, message string) bool {
+	if !s.send(confirmRequest{title: title, message: message}) {
+		return false
+	}
+	select {
+	case confirmed := <-s.confirms:
+		return confirmed
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+func (s *appSession) Form(form InputModel) (InputResult, error) {
+	if !s.send(formRequest{form: form}) {
+		return InputResult{}, s.ctx.Err()
+	}
+	select {
+	case result := <-s.forms:
+		return result, nil
+	case <-s.ctx.Done():
+		return InputResult{}, s.ctx.Err()
+	}
+}
+
+func (s *appSession) Report(message string) {
+	s.send(reportMsg{message: message})
+}
+
+// send delivers msg to the UI loop, reporting false when the app has ended
+func (s *appSession) send(msg tea.Msg) bool {
+	select {
+	case s.events <- msg:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// next returns a command waiting for the session's next request
+func (s *appSession) next() tea.Cmd {
+	return func() tea.Msg {
+		return <-s.events
+	}
+}
+
+func (a AppModel) Init() tea.Cmd {
+	return a.list.Init()
+}
+
+func (a AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Quitting during an operation cancels it, stopping its git commands
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == alwaysQuit && a.session != nil {
+		a.cancel()
+		return a, tea.Quit
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return a.updateList(msg)
+
+	case previewMsg, actionMsg, reloadMsg, logMsg, sessionsMsg:
+		// Results of the list's own commands reach it on any screen
+		return a.updateList(msg)
+
+	case spinner.TickMsg:
+		if a.screen != screenProgress {
+			return a, nil
+		}
+		var cmd tea.Cmd
+		a.spinner, cmd = a.spinner.Update(msg)
+		return a, cmd
+
+	case confirmRequest:
+		a.screen, a.confirm = screenConfirm, NewConfirm(msg.title, msg.message)
+		return a, nil
+
+	case formRequest:
+		a.screen, a.form = screenForm, msg.form
+		return a, a.form.Init()
+
+	case reportMsg:
+		a.progress = msg.message
+		return a, a.session.next()
+
+	case operationDone:
+		// The list shows the outcome and reloads, as after its own actions
+		status := a.progress
+		a.session, a.running, a.progress, a.screen = nil, nil, "", screenList
+		return a.updateList(actionMsg{status: status, err: msg.err})
+	}
+
+	switch a.screen {
+	case screenForm:
+		updated, cmd := a.form.Update(msg)
+		a.form = updated.(InputModel)
+		if !a.form.finished() {
+			return a, cmd
+		}
+		a.screen = screenProgress
+		a.session.forms <- a.form.GetResult()
+		return a, tea.Batch(a.session.next(), a.spinner.Tick)
+
+	case screenConfirm:
+		updated, cmd := a.confirm.Update(msg)
+		a.confirm = updated.(ConfirmModel)
+		if !a.confirm.finished() {
+			return a, cmd
+		}
+		a.screen = screenProgress
+		a.session.confirms <- a.confirm.GetResult().Confirmed
+		return a, tea.Batch(a.session.next(), a.spinner.Tick)
+
+	case screenProgress:
+		// Keys wait for the operation; only ctrl+c, handled above, quits
+		return a, nil
+	}
+	return a.updateList(msg)
+}
+
+// updateList passes msg to the list and starts the operation for its result
+// when it finishes
+func (a AppModel) updateList(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := a.list.Update(msg)
+	a.list = updated.(SelectorModel)
+	if !a.list.finished() {
+		return a, cmd
+	}
+
+	result := a.list.GetResult()
+	var op Operation
+	switch {
+	case result.Action == "create" && a.handlers.Create != nil:
+		op = a.handlers.Create
+	case result.Action == "delete" && a.handlers.Remove != nil:
+		op = func(ctx context.Context, s Session) error { return a.handlers.Remove(ctx, s, result.Worktree) }
+	case result.Action == "move" && a.handlers.Move != nil:
+		op = func(ctx context.Context, s Session) error { return a.handlers.Move(ctx, s, result.Worktree) }
+	default:
+		// Actions of the list still running are stopped
+		a.result = result
+		a.cancel()
+		return a, tea.Quit
+	}
+
+	a.list = a.list.resumed()
+	return a.start(op)
+}
+
+// start runs op in the background and shows its progress
+func (a AppModel) start(op Operation) (AppModel, tea.Cmd) {
+	s := &appSession{ctx: a.ctx, events: make(chan tea.Msg), confirms: make(chan bool), forms: make(chan InputResult)}
+	done := make(chan struct{})
+	a.session, a.running, a.progress, a.screen = s, done, "", screenProgress
+
+	run := func() tea.Msg {
+		defer close(done)
+		// Delivered through the session so that it arrives after every report
+		s.send(operationDone{err: op(a.ctx, s)})
+		return nil
+	}
+	return a, tea.Batch(run, s.next(), a.spinner.Tick)
+}
+
+func (a AppModel) View() string {
+	switch a.screen {
+	case screenForm:
+		return a.form.View()
+	case screenConfirm:
+		return a.confirm.View()
+	case screenProgress:
+		var b strings.Builder
+		b.WriteString(TitleStyle.Render(fmt.Sprintf("🌲 %s", a.list.title)))
+		b.WriteString("\n\n")
+		progress := a.progress
+		if progress == "" {
+			progress = "Working..."
+		}
+		b.WriteString(a.spinner.View() + " " + NormalStyle.Render(progress))
+		return BorderStyle.Render(b.String())
+	}
+	return a.list.View()
+}
+
+// Close cancels whatever the app still runs and waits for a running
+// operation to return, so that none of its git commands outlive the program
+func (a AppModel) Close() {
+	a.cancel()
+	if a.running != nil {
+		<-a.running
+	}
+}
+
+// GetResult returns the list's result once the app has ended: the selected
+// worktree, or quit
+func (a AppModel) GetResult() SelectionResult {
+	return a.result
+}
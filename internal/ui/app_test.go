@@ -0,0 +1,252 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// appDriver runs an app's commands concurrently, as a program does, and
+// delivers their messages back to it one at a time
+type appDriver struct {
+	t    *testing.T
+	app  tea.Model
+	msgs chan tea.Msg
+}
+
+func newAppDriver(t *testing.T, app AppModel) *appDriver {
+	return &appDriver{t: t, app: app, msgs: make(chan tea.Msg, 100)}
+}
+
+func (d *appDriver) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	go func() {
+		switch msg := cmd().(type) {
+		case nil:
+		case tea.BatchMsg:
+			for _, c := range msg {
+				d.run(c)
+			}
+		default:
+			d.msgs <- msg
+		}
+	}()
+}
+
+func (d *appDriver) update(msg tea.Msg) {
+	var cmd tea.Cmd
+	d.app, cmd = d.app.Update(msg)
+	d.run(cmd)
+}
+
+func (d *appDriver) keys(s string) {
+	for _, r := range s {
+		d.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+// until delivers messages until ready holds for the app
+func (d *appDriver) until(what string, ready func(AppModel) bool) {
+	d.t.Helper()
+	timeout := time.After(5 * time.Second)
+	for !ready(d.app.(AppModel)) {
+		select {
+		case msg := <-d.msgs:
+			if _, ok := msg.(spinner.TickMsg); ok {
+				continue
+			}
+			d.update(msg)
+		case <-timeout:
+			d.t.Fatalf("Timed out waiting for %s:\n%s", what, d.app.View())
+		}
+	}
+}
+
+func onScreen(screen appScreen) func(AppModel) bool {
+	return func(a AppModel) bool { return a.screen == screen }
+}
+
+// listed reports whether the app's list shows n worktrees
+func listed(n int) func(AppModel) bool {
+	return func(a AppModel) bool { return a.screen == screenList && len(a.list.worktrees) == n }
+}
+
+func appList(reloaded []worktree.Worktree) SelectorModel {
+	worktrees := []worktree.Worktree{
+		{Path: "/repo", Branch: "main", IsCurrent: true},
+		{Path: "/repo/feature", Branch: "feature"},
+	}
+	return NewSelector(worktrees, "Git Worktrees", "print path", true).
+		WithCreate().
+		WithReload(func() ([]worktree.Worktree, error) { return reloaded, nil })
+}
+
+func TestAppRemoveWithConfirmation(t *testing.T) {
+	var removed string
+	app := NewApp(context.Background(), appList([]worktree.Worktree{{Path: "/repo", Branch: "main", IsCurrent: true}}), AppHandlers{
+		Remove: func(ctx context.Context, s Session, wt worktree.Worktree) error {
+			if !s.Confirm("Remove Worktree", "Remove "+wt.Path+"?") {
+				s.Report("Removal cancelled")
+				return nil
+			}
+			s.Report("Removing " + wt.Path + "...")
+			removed = wt.Path
+			s.Report("Removed " + wt.Path)
+			return nil
+		},
+	})
+	d := newAppDriver(t, app)
+
+	d.update(tea.KeyMsg{Type: tea.KeyDown})
+	d.keys("d")
+	d.until("the confirmation", onScreen(screenConfirm))
+	if view := d.app.View(); !strings.Contains(view, "Remove /repo/feature?") {
+		t.Errorf("Expected the question on the confirmation screen:\n%s", view)
+	}
+
+	d.keys("y")
+	d.until("the reloaded list", listed(1))
+	if removed != "/repo/feature" {
+		t.Errorf("Expected the highlighted worktree to be removed, got %q", removed)
+	}
+	view := d.app.View()
+	if !strings.Contains(view, "Removed /repo/feature") || strings.Contains(view, "Removing") {
+		t.Errorf("Expected the list with the last report:\n%s", view)
+	}
+
+	// The list takes keys again, and selecting ends the app
+	d.update(tea.KeyMsg{Type: tea.KeyEnter})
+	d.until("the app to end", func(a AppModel) bool { return a.result.Action == "select" })
+	if result := d.app.(AppModel).GetResult(); result.Worktree.Path != "/repo" {
+		t.Errorf("Expected /repo to be selected, got %+v", result)
+	}
+}
+
+func TestAppCreateWithForm(t *testing.T) {
+	reloaded := []worktree.Worktree{
+		{Path: "/repo", Branch: "main", IsCurrent: true},
+		{Path: "/repo/feature", Branch: "feature"},
+		{Path: "/repo/login", Branch: "login"},
+	}
+	app := NewApp(context.Background(), appList(reloaded), AppHandlers{
+		Create: func(ctx context.Context, s Session) error {
+			result, err := s.Form(NewInput("Create New Worktree", []string{"Branch name:"}, []string{""}))
+			if err != nil {
+				return err
+			}
+			s.Report("Created " + result.Values[0])
+			return nil
+		},
+	})
+	d := newAppDriver(t, app)
+
+	d.keys("c")
+	d.until("the form", onScreen(screenForm))
+	if view := d.app.View(); !strings.Contains(view, "Create New Worktree") {
+		t.Errorf("Expected the form screen:\n%s", view)
+	}
+
+	d.keys("login")
+	d.update(tea.KeyMsg{Type: tea.KeyEnter})
+	d.until("the reloaded list", listed(3))
+	if view := d.app.View(); !strings.Contains(view, "Created login") || !strings.Contains(view, "/repo/login") {
+		t.Errorf("Expected the new worktree and the outcome in the list:\n%s", view)
+	}
+}
+
+func TestAppOperationError(t *testing.T) {
+	release := make(chan struct{})
+	app := NewApp(context.Background(), appList(nil), AppHandlers{
+		Remove: func(ctx context.Context, s Session, wt worktree.Worktree) error {
+			s.Report("Removing " + wt.Path + "...")
+			<-release
+			return errors.New("worktree is dirty")
+		},
+	})
+	d := newAppDriver(t, app)
+
+	d.update(tea.KeyMsg{Type: tea.KeyDown})
+	d.keys("d")
+	d.until("the progress report", func(a AppModel) bool { return a.progress != "" })
+	if view := d.app.View(); !strings.Contains(view, "Removing /repo/feature...") {
+		t.Errorf("Expected the progress screen:\n%s", view)
+	}
+
+	// Keys other than ctrl+c wait for the operation
+	d.keys("q")
+	if d.app.(AppModel).screen != screenProgress {
+		t.Error("Expected the operation to keep running")
+	}
+
+	close(release)
+	d.until("the list", onScreen(screenList))
+	if view := d.app.View(); !strings.Contains(view, "worktree is dirty") {
+		t.Errorf("Expected the error in the list:\n%s", view)
+	}
+}
+
+func TestAppQuitCancelsOperation(t *testing.T) {
+	started := make(chan struct{})
+	var opErr error
+	app := NewApp(context.Background(), appList(nil), AppHandlers{
+		Remove: func(ctx context.Context, s Session, wt worktree.Worktree) error {
+			close(started)
+			<-ctx.Done()
+			// The session does not block once the app has ended
+			s.Report("Interrupted")
+			if s.Confirm("Remove Worktree", "Remove anyway?") {
+				return errors.New("expected the confirmation to be refused")
+			}
+			opErr = ctx.Err()
+			return opErr
+		},
+	})
+	d := newAppDriver(t, app)
+
+	d.update(tea.KeyMsg{Type: tea.KeyDown})
+	d.keys("d")
+	<-started
+
+	var cmd tea.Cmd
+	d.app, cmd = d.app.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if cmd == nil || cmd() != tea.Quit() {
+		t.Error("Expected ctrl+c to quit during the operation")
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		d.app.(AppModel).Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the operation to return")
+	}
+	if !errors.Is(opErr, context.Canceled) {
+		t.Errorf("Expected the operation's context to be cancelled, got %v", opErr)
+	}
+}
+
+func TestAppWithoutHandlers(t *testing.T) {
+	app := NewApp(context.Background(), appList(nil), AppHandlers{})
+
+	// Operations without a handler end the app with the selector's result
+	updated, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if cmd == nil || updated.(AppModel).GetResult().Action != "create" {
+		t.Errorf("Expected the app to end with create, got %+v", updated.(AppModel).GetResult())
+	}
+
+	updated, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if updated.(AppModel).GetResult().Action != "quit" {
+		t.Errorf("Expected quit, got %+v", updated.(AppModel).GetResult())
+	}
+}
@@ -4,54 +4,50 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type confirmKeyMap struct {
-	Up    key.Binding
-	Down  key.Binding
-	Left  key.Binding
-	Right key.Binding
-	Yes   key.Binding
-	No    key.Binding
-	Enter key.Binding
-	Quit  key.Binding
+	ChooseYes key.Binding
+	ChooseNo  key.Binding
+	Yes       key.Binding
+	No        key.Binding
+	Enter     key.Binding
+	Quit      key.Binding
+	Help      key.Binding
 }
 
-var confirmKeys = confirmKeyMap{
-	Up: key.NewBinding(
-		key.WithKeys("up", "k"),
-		key.WithHelp("↑/k", "select yes"),
-	),
-	Down: key.NewBinding(
-		key.WithKeys("down", "j"),
-		key.WithHelp("↓/j", "select no"),
-	),
-	Left: key.NewBinding(
-		key.WithKeys("left", "h"),
-		key.WithHelp("←/h", "select yes"),
-	),
-	Right: key.NewBinding(
-		key.WithKeys("right", "l"),
-		key.WithHelp("→/l", "select no"),
-	),
-	Yes: key.NewBinding(
-		key.WithKeys("y"),
-		key.WithHelp("y", "yes"),
-	),
-	No: key.NewBinding(
-		key.WithKeys("n"),
-		key.WithHelp("n", "no"),
-	),
-	Enter: key.NewBinding(
-		key.WithKeys("enter"),
-		key.WithHelp("enter", "confirm"),
-	),
-	Quit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c", "esc"),
-		key.WithHelp("q/esc", "cancel"),
-	),
+// confirmKeys are the confirmation dialog's bindings, changed by
+// ApplyKeyBindings
+var confirmKeys = defaultConfirmKeys()
+
+// defaultConfirmKeys returns the confirmation dialog's bindings before
+// configuration
+func defaultConfirmKeys() confirmKeyMap {
+	return confirmKeyMap{
+		ChooseYes: newBinding("highlight yes", "left", "h", "up", "k"),
+		ChooseNo:  newBinding("highlight no", "right", "l", "down", "j"),
+		Yes:       newBinding("yes", "y"),
+		No:        newBinding("no", "n"),
+		Enter:     newBinding("confirm", "enter"),
+		Quit:      newBinding("cancel", "q", alwaysQuit, "esc"),
+		Help:      newBinding("help", "?"),
+	}
+}
+
+// actions lists the configurable actions of the confirmation dialog
+func (k *confirmKeyMap) actions() []keyAction {
+	return []keyAction{
+		{name: "choose_yes", binding: &k.ChooseYes, desc: "highlight yes"},
+		{name: "choose_no", binding: &k.ChooseNo, desc: "highlight no"},
+		{name: "yes", binding: &k.Yes, desc: "yes"},
+		{name: "no", binding: &k.No, desc: "no"},
+		{name: "confirm", binding: &k.Enter, desc: "confirm"},
+		{name: "cancel", binding: &k.Quit, desc: "cancel", quit: true},
+		{name: "help", binding: &k.Help, desc: "help"},
+	}
 }
 
 type ConfirmModel struct {
@@ -60,6 +56,7 @@ type ConfirmModel struct {
 	selected  bool // true = yes, false = no
 	confirmed bool
 	cancelled bool
+	help      help.Model // ShowAll is set while the full help is shown
 }
 
 type ConfirmResult struct {
@@ -72,6 +69,7 @@ func NewConfirm(title, message string) ConfirmModel {
 		title:    title,
 		message:  message,
 		selected: false, // default to "no" for safety
+		help:     newHelp(),
 	}
 }
 
@@ -87,10 +85,13 @@ func (m ConfirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cancelled = true
 			return m, tea.Quit
 
-		case key.Matches(msg, confirmKeys.Up), key.Matches(msg, confirmKeys.Left):
+		case key.Matches(msg, confirmKeys.Help):
+			m.help.ShowAll = !m.help.ShowAll
+
+		case key.Matches(msg, confirmKeys.ChooseYes):
 			m.selected = true
 
-		case key.Matches(msg, confirmKeys.Down), key.Matches(msg, confirmKeys.Right):
+		case key.Matches(msg, confirmKeys.ChooseNo):
 			m.selected = false
 
 		case key.Matches(msg, confirmKeys.Yes):
@@ -144,14 +145,30 @@ func (m ConfirmModel) View() string {
 	b.WriteString("\n\n")
 
 	// Help
-	helpText := []string{
-		"←/→/h/l switch", "↑/↓/k/j switch", "y yes", "n no", "enter confirm", "q/esc cancel",
-	}
-	b.WriteString(HelpStyle.Render(strings.Join(helpText, " • ")))
+	b.WriteString(HelpStyle.Render(m.help.View(m)))
 
 	return BorderStyle.Render(b.String())
 }
 
+// ShortHelp returns the bindings shown in the help line
+func (m ConfirmModel) ShortHelp() []key.Binding {
+	return []key.Binding{confirmKeys.Yes, confirmKeys.No, confirmKeys.Enter, confirmKeys.Quit, confirmKeys.Help}
+}
+
+// FullHelp returns every binding of the dialog, by column
+func (m ConfirmModel) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{confirmKeys.ChooseYes, confirmKeys.ChooseNo},
+		{confirmKeys.Yes, confirmKeys.No, confirmKeys.Enter},
+		{confirmKeys.Quit, confirmKeys.Help},
+	}
+}
+
+// finished reports whether the question was answered or cancelled
+func (m ConfirmModel) finished() bool {
+	return m.confirmed || m.cancelled
+}
+
 func (m ConfirmModel) GetResult() ConfirmResult {
 	if m.cancelled {
 		return ConfirmResult{
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -201,3 +202,23 @@ func TestConfirmView(t *testing.T) {
 		}
 	})
 }
+
+func TestConfirmHelp(t *testing.T) {
+	model := NewConfirm("Title", "Message")
+	if view := model.View(); !strings.Contains(view, "y yes") || !strings.Contains(view, "? help") || strings.Contains(view, "highlight") {
+		t.Errorf("Expected the short help line:\n%s", view)
+	}
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	if cmd != nil {
+		t.Error("Expected help not to close the dialog")
+	}
+	if view := updated.View(); !strings.Contains(view, "←/h/↑/k highlight yes") || !strings.Contains(view, "→/l/↓/j highlight no") {
+		t.Errorf("Expected the full help:\n%s", view)
+	}
+
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	if strings.Contains(updated.View(), "highlight") {
+		t.Error("Expected ? to hide the full help again")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -16,6 +18,21 @@ type FieldDependency struct {
 	UpdateFunc  func(sourceValue string) string // Function to compute target value from source
 }
 
+type inputKeyMap struct {
+	Next   key.Binding
+	Prev   key.Binding
+	Submit key.Binding
+	Cancel key.Binding
+}
+
+// inputKeys are fixed: every printable key is typed into the fields
+var inputKeys = inputKeyMap{
+	Next:   newBinding("next field", "tab"),
+	Prev:   newBinding("previous field", "shift+tab"),
+	Submit: newBinding("submit", "enter"),
+	Cancel: newBinding("cancel", "esc", alwaysQuit),
+}
+
 type InputModel struct {
 	title         string
 	inputs        []textinput.Model
@@ -25,6 +42,7 @@ type InputModel struct {
 	values        []string
 	dependencies  []FieldDependency // Field dependencies for auto-update
 	autoGenerated map[int]bool      // Track which fields have auto-generated values
+	help          help.Model
 }
 
 type InputResult struct {
@@ -62,6 +80,7 @@ func NewInputWithDependencies(title string, prompts []string, defaults []string,
 		values:        make([]string, len(prompts)),
 		dependencies:  dependencies,
 		autoGenerated: make(map[int]bool),
+		help:          newHelp(),
 	}
 }
 
@@ -79,13 +98,7 @@ func NewWorktreeInput(title string, worktreePathPrefix string) InputModel {
 			SourceIndex: 0,
 			TargetIndex: 1,
 			UpdateFunc: func(branchName string) string {
-				if strings.TrimSpace(branchName) == "" {
-					return ""
-				}
-				// Clean branch name: remove feature/ prefix if present, handle slashes
-				cleanBranch := strings.TrimSpace(branchName)
-				cleanBranch = strings.ReplaceAll(cleanBranch, "/", "-")
-				return filepath.Join(worktreePathPrefix, cleanBranch)
+				return WorktreePathForBranch(worktreePathPrefix, branchName)
 			},
 		},
 	}
@@ -93,6 +106,17 @@ func NewWorktreeInput(title string, worktreePathPrefix string) InputModel {
 	return NewInputWithDependencies(title, prompts, defaults, dependencies)
 }
 
+// WorktreePathForBranch derives the default worktree directory for a branch
+func WorktreePathForBranch(worktreePathPrefix, branchName string) string {
+	if strings.TrimSpace(branchName) == "" {
+		return ""
+	}
+	// Clean branch name: handle slashes so the branch maps to a single directory
+	cleanBranch := strings.TrimSpace(branchName)
+	cleanBranch = strings.ReplaceAll(cleanBranch, "/", "-")
+	return filepath.Join(worktreePathPrefix, cleanBranch)
+}
+
 func (m InputModel) Init() tea.Cmd {
 	return textinput.Blink
 }
@@ -102,12 +126,12 @@ func (m InputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyEsc:
+		switch {
+		case key.Matches(msg, inputKeys.Cancel):
 			m.cancelled = true
 			return m, tea.Quit
 
-		case tea.KeyEnter:
+		case key.Matches(msg, inputKeys.Submit):
 			// If on last input or all inputs filled, submit
 			if m.focused == len(m.inputs)-1 || m.allInputsFilled() {
 				for i, input := range m.inputs {
@@ -119,12 +143,11 @@ func (m InputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Otherwise, move to next input
 			m.nextInput()
 
-		case tea.KeyTab, tea.KeyShiftTab:
-			if msg.Type == tea.KeyTab {
-				m.nextInput()
-			} else {
-				m.prevInput()
-			}
+		case key.Matches(msg, inputKeys.Next):
+			m.nextInput()
+
+		case key.Matches(msg, inputKeys.Prev):
+			m.prevInput()
 		}
 	}
 
@@ -181,12 +204,30 @@ func (m InputModel) View() string {
 
 	// Help text
 	b.WriteString("\n\n")
-	helpText := "tab/shift+tab navigate • enter submit • esc cancel"
-	b.WriteString(HelpStyle.Render(helpText))
+	b.WriteString(HelpStyle.Render(m.help.View(m)))
 
 	return BorderStyle.Render(b.String())
 }
 
+// ShortHelp returns the bindings shown in the help line. Field navigation
+// is only listed when there is more than one field.
+func (m InputModel) ShortHelp() []key.Binding {
+	if len(m.inputs) < 2 {
+		return []key.Binding{inputKeys.Submit, inputKeys.Cancel}
+	}
+	return []key.Binding{inputKeys.Next, inputKeys.Prev, inputKeys.Submit, inputKeys.Cancel}
+}
+
+// FullHelp returns the bindings of the dialog, by column
+func (m InputModel) FullHelp() [][]key.Binding {
+	return [][]key.Binding{m.ShortHelp()}
+}
+
+// finished reports whether the form was submitted or cancelled
+func (m InputModel) finished() bool {
+	return m.submitted || m.cancelled
+}
+
 func (m InputModel) GetResult() InputResult {
 	return InputResult{
 		Values:    m.values,
@@ -254,7 +254,7 @@ func TestInputView(t *testing.T) {
 			},
 			expectedContains: []string{
 				"📝 Enter Information",
-				"tab/shift+tab navigate",
+				"tab next field",
 				"enter submit",
 				"esc cancel",
 			},
@@ -690,3 +690,39 @@ func TestNewWorktreeInputPrompts(t *testing.T) {
 		}
 	}
 }
+
+func TestWorktreePathForBranch(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		branch   string
+		expected string
+	}{
+		{prefix: "../", branch: "feature/login", expected: filepath.Join("../", "feature-login")},
+		{prefix: "../wt", branch: " main ", expected: filepath.Join("../wt", "main")},
+		{prefix: "../", branch: "  ", expected: ""},
+	}
+
+	for _, tt := range tests {
+		if got := WorktreePathForBranch(tt.prefix, tt.branch); got != tt.expected {
+			t.Errorf("WorktreePathForBranch(%q, %q) = %q, expected %q", tt.prefix, tt.branch, got, tt.expected)
+		}
+	}
+}
+
+func TestInputHelp(t *testing.T) {
+	single := NewInput("Title", []string{"New path"}, nil)
+	if view := single.View(); strings.Contains(view, "tab") || !strings.Contains(view, "enter submit") {
+		t.Errorf("Expected no field navigation for a single field:\n%s", view)
+	}
+
+	model := NewInput("Title", []string{"Branch", "Path"}, nil)
+	if len(model.ShortHelp()) != 4 || len(model.FullHelp()) != 1 {
+		t.Errorf("Expected navigation, submit and cancel, got %d", len(model.ShortHelp()))
+	}
+
+	// Typing ? goes into the field rather than toggling help
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	if value := updated.(InputModel).inputs[0].Value(); value != "?" {
+		t.Errorf("Expected ? to be typed, got %q", value)
+	}
+}
@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/yagi2/yosegi/internal/config"
+)
+
+// alwaysQuit is bound to quitting in every view on top of the configured
+// keys, so that a configuration cannot leave a view without a way out
+const alwaysQuit = "ctrl+c"
+
+// keyAction is an action that can be bound to keys in the configuration
+type keyAction struct {
+	name    string
+	binding *key.Binding
+	desc    string
+	quit    bool // also bound to alwaysQuit
+}
+
+// newBinding returns a binding to keys with help generated from them. A
+// binding without keys is disabled, which also leaves it out of help.
+func newBinding(desc string, keys ...string) key.Binding {
+	b := key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyLabel(keys), desc))
+	if len(keys) == 0 {
+		b.SetEnabled(false)
+	}
+	return b
+}
+
+// keyLabel describes keys for help text, e.g. "↑/k" for "up" and "k". The
+// always bound ctrl+c is left out unless it is the only key.
+func keyLabel(keys []string) string {
+	symbols := map[string]string{"up": "↑", "down": "↓", "left": "←", "right": "→", "pgdown": "pgdn", " ": "space"}
+
+	var labels []string
+	for _, k := range keys {
+		if k == alwaysQuit && len(keys) > 1 {
+			continue
+		}
+		if symbol, ok := symbols[k]; ok {
+			k = symbol
+		}
+		labels = append(labels, k)
+	}
+	return strings.Join(labels, "/")
+}
+
+// withHelpDesc returns a copy of b described as desc in help
+func withHelpDesc(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// InitializeKeyBindings applies the keybindings section of the configuration
+func InitializeKeyBindings(cfg *config.Config) error {
+	return ApplyKeyBindings(cfg.KeyBindings)
+}
+
+// ApplyKeyBindings binds the actions named in bindings to the listed keys,
+// replacing their default keys; an empty list unbinds an action. The space
+// bar is written "space". It fails
+// without changing anything when an action is unknown or a key would trigger
+// two actions in the same view.
+func ApplyKeyBindings(bindings map[string][]string) error {
+	selector, confirm := defaultKeys(), defaultConfirmKeys()
+	views := []struct {
+		name    string
+		actions []keyAction
+	}{
+		{name: "selector", actions: selector.actions()},
+		{name: "confirmation", actions: confirm.actions()},
+	}
+
+	// Actions such as help exist in several views and are bound in all of them
+	known := make(map[string][]keyAction)
+	for _, view := range views {
+		for _, action := range view.actions {
+			known[action.name] = append(known[action.name], action)
+		}
+	}
+
+	// Apply in name order so that errors are reported consistently
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		actions, ok := known[name]
+		if !ok {
+			return fmt.Errorf("unknown action '%s' (expected one of %s)", name, strings.Join(actionNames(known), ", "))
+		}
+
+		keys := make([]string, len(bindings[name]))
+		for i, k := range bindings[name] {
+			if strings.TrimSpace(k) == "" {
+				return fmt.Errorf("empty key for action '%s'", name)
+			}
+			if k == "space" {
+				k = " "
+			}
+			keys[i] = k
+		}
+		for _, action := range actions {
+			bound := keys
+			if action.quit && !containsKey(bound, alwaysQuit) {
+				bound = append(append([]string(nil), bound...), alwaysQuit)
+			}
+			*action.binding = newBinding(action.desc, bound...)
+		}
+	}
+
+	for _, view := range views {
+		bound := make(map[string]string)
+		for _, action := range view.actions {
+			for _, k := range action.binding.Keys() {
+				if other, ok := bound[k]; ok {
+					return fmt.Errorf("key '%s' is bound to both '%s' and '%s' in the %s view", k, other, action.name, view.name)
+				}
+				bound[k] = action.name
+			}
+		}
+	}
+
+	keys, confirmKeys = selector, confirm
+	return nil
+}
+
+// actionNames returns the names of the configurable actions in order
+func actionNames(actions map[string][]keyAction) []string {
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// containsKey reports whether keys includes k
+func containsKey(keys []string, k string) bool {
+	for _, candidate := range keys {
+		if candidate == k {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yagi2/yosegi/internal/config"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// resetKeyBindings restores the default bindings after a test changes them
+func resetKeyBindings(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		keys, confirmKeys = defaultKeys(), defaultConfirmKeys()
+	})
+}
+
+func TestKeyLabel(t *testing.T) {
+	tests := []struct {
+		keys     []string
+		expected string
+	}{
+		{keys: []string{"up", "k"}, expected: "↑/k"},
+		{keys: []string{"left", "right", "pgdown"}, expected: "←/→/pgdn"},
+		{keys: []string{"q", "ctrl+c", "esc"}, expected: "q/esc"},
+		{keys: []string{"ctrl+c"}, expected: "ctrl+c"},
+	}
+
+	for _, tt := range tests {
+		if got := keyLabel(tt.keys); got != tt.expected {
+			t.Errorf("keyLabel(%v) = %q, expected %q", tt.keys, got, tt.expected)
+		}
+	}
+}
+
+func TestApplyKeyBindings(t *testing.T) {
+	resetKeyBindings(t)
+
+	err := ApplyKeyBindings(map[string][]string{
+		"delete": {"x"},
+		"create": {"n", "N"},
+		"move":   {},
+		"quit":   {"esc"},
+		"yes":    {"Y", "y"},
+		"help":   {"f1"},
+	})
+	if err != nil {
+		t.Fatalf("ApplyKeyBindings failed: %v", err)
+	}
+
+	if got := keys.Delete.Keys(); len(got) != 1 || got[0] != "x" {
+		t.Errorf("Expected delete on x, got %v", got)
+	}
+	if got := keys.Quit.Keys(); !containsKey(got, "esc") || !containsKey(got, alwaysQuit) {
+		t.Errorf("Expected quit on esc and ctrl+c, got %v", got)
+	}
+	if keys.Up.Help().Key != "↑/k" {
+		t.Errorf("Expected unchanged actions to keep their defaults, got %q", keys.Up.Help().Key)
+	}
+	if got := confirmKeys.Yes.Help().Key; got != "Y/y" {
+		t.Errorf("Expected confirmation bindings to change, got %q", got)
+	}
+	if keys.Help.Help().Key != "f1" || confirmKeys.Help.Help().Key != "f1" {
+		t.Error("Expected help to be rebound in every view")
+	}
+
+	// The help line follows the bindings, and unbound actions leave it
+	worktrees := []worktree.Worktree{{Path: "/repo", Branch: "main"}}
+	help := NewSelector(worktrees, "Test", "select", true).WithCreate().WithMove().helpView(0)
+	for _, expected := range []string{"x delete", "n/N create", "esc quit"} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("Expected %q in help: %s", expected, help)
+		}
+	}
+	if strings.Contains(help, "move") {
+		t.Errorf("Expected the unbound move action to be left out: %s", help)
+	}
+	if confirm := NewConfirm("Title", "Message").View(); !strings.Contains(confirm, "Y/y yes") {
+		t.Errorf("Expected the confirmation help to follow its bindings:\n%s", confirm)
+	}
+
+	// Rebound keys drive the selector
+	model := NewSelector(worktrees, "Test", "select", true)
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if updated.(SelectorModel).GetResult().Action == "delete" {
+		t.Error("Expected the default delete key to be unbound")
+	}
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if updated.(SelectorModel).GetResult().Action != "delete" {
+		t.Error("Expected x to delete")
+	}
+}
+
+func TestApplyKeyBindingsErrors(t *testing.T) {
+	resetKeyBindings(t)
+
+	tests := []struct {
+		name     string
+		bindings map[string][]string
+		expected string
+	}{
+		{name: "Unknown action", bindings: map[string][]string{"filter": {"/"}}, expected: "unknown action 'filter'"},
+		{name: "Empty key", bindings: map[string][]string{"up": {" "}}, expected: "empty key for action 'up'"},
+		{name: "Conflict with default", bindings: map[string][]string{"delete": {"c"}}, expected: "key 'c' is bound to both 'delete' and 'create' in the selector view"},
+		{name: "Conflict between overrides", bindings: map[string][]string{"yes": {"z"}, "no": {"z"}}, expected: "key 'z' is bound to both 'yes' and 'no' in the confirmation view"},
+		{name: "Always quit", bindings: map[string][]string{"sort": {"ctrl+c"}}, expected: "key 'ctrl+c' is bound to both 'quit' and 'sort'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ApplyKeyBindings(tt.bindings)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+			if keys.Delete.Help().Key != "d" || confirmKeys.Yes.Help().Key != "y" {
+				t.Error("Expected a failed configuration to leave the bindings unchanged")
+			}
+		})
+	}
+
+	// The same key may be used by different views
+	if err := ApplyKeyBindings(map[string][]string{"delete": {"y"}}); err != nil {
+		t.Errorf("Expected keys to be independent between views, got %v", err)
+	}
+}
+
+func TestInitializeKeyBindings(t *testing.T) {
+	resetKeyBindings(t)
+
+	if err := InitializeKeyBindings(&config.Config{}); err != nil {
+		t.Errorf("Expected no bindings to keep the defaults, got %v", err)
+	}
+	if err := InitializeKeyBindings(&config.Config{KeyBindings: map[string][]string{"create": {"n"}}}); err != nil || keys.Create.Help().Key != "n" {
+		t.Errorf("Expected create on n, got %q, %v", keys.Create.Help().Key, err)
+	}
+}
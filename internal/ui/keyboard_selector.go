@@ -6,24 +6,34 @@ import (
 	"os/exec"
 	"strings"
 
-	"github.com/yagi2/yosegi/internal/git"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 // KeyboardSelector provides arrow key navigation without full TUI
 type KeyboardSelector struct {
-	worktrees []git.Worktree
+	worktrees []worktree.Worktree
 	cursor    int
+	offset    int // first worktree shown when the list scrolls
+	width     int // terminal size, zero when unknown
+	height    int
 	input     FileInterface
 	output    FileInterface
 }
 
+// Terminal size assumed when it cannot be detected
+const (
+	defaultTerminalWidth  = 80
+	defaultTerminalHeight = 24
+)
+
 // NewKeyboardSelector creates a new keyboard-based selector
-func NewKeyboardSelector(worktrees []git.Worktree, input, output *os.File) *KeyboardSelector {
+func NewKeyboardSelector(worktrees []worktree.Worktree, input, output *os.File) *KeyboardSelector {
 	return newKeyboardSelectorWithFiles(worktrees, input, output)
 }
 
 // newKeyboardSelectorWithFiles is the testable version that accepts interfaces
-func newKeyboardSelectorWithFiles(worktrees []git.Worktree, input, output FileInterface) *KeyboardSelector {
+func newKeyboardSelectorWithFiles(worktrees []worktree.Worktree, input, output FileInterface) *KeyboardSelector {
 	return &KeyboardSelector{
 		worktrees: worktrees,
 		cursor:    0,
@@ -33,7 +43,7 @@ func newKeyboardSelectorWithFiles(worktrees []git.Worktree, input, output FileIn
 }
 
 // Run starts the keyboard selector
-func (k *KeyboardSelector) Run() (*git.Worktree, error) {
+func (k *KeyboardSelector) Run() (*worktree.Worktree, error) {
 	if len(k.worktrees) == 0 {
 		return nil, fmt.Errorf("no worktrees found")
 	}
@@ -43,6 +53,7 @@ func (k *KeyboardSelector) Run() (*git.Worktree, error) {
 		return nil, fmt.Errorf("failed to set raw mode: %w", err)
 	}
 	defer k.restoreMode()
+	k.width, k.height = k.terminalSize()
 
 	// Initial render
 	k.render()
@@ -55,14 +66,13 @@ func (k *KeyboardSelector) Run() (*git.Worktree, error) {
 		}
 
 		switch key {
-		case "up", "k":
-			if k.cursor > 0 {
-				k.cursor--
-				k.render()
+		case "up", "k", "down", "j", "pgup", "pgdown", "home", "g", "end", "G":
+			aliases := map[string]string{"k": "up", "j": "down", "g": "home", "G": "end"}
+			if alias, ok := aliases[key]; ok {
+				key = alias
 			}
-		case "down", "j":
-			if k.cursor < len(k.worktrees)-1 {
-				k.cursor++
+			if cursor := moveCursor(key, k.cursor, k.listRows(), len(k.worktrees)); cursor != k.cursor {
+				k.cursor = cursor
 				k.render()
 			}
 		case "enter":
@@ -121,6 +131,52 @@ func (k *KeyboardSelector) restoreMode() {
 	_ = cmd.Run() // Explicitly ignore errors during cleanup
 }
 
+// terminalSize asks stty for the terminal's size, returning zeros when it
+// cannot be determined
+func (k *KeyboardSelector) terminalSize() (int, int) {
+	osInput, ok := k.input.(*os.File)
+	if !ok {
+		return 0, 0
+	}
+
+	cmd := exec.Command("stty", "size")
+	cmd.Stdin = osInput
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0
+	}
+
+	var rows, cols int
+	if _, err := fmt.Sscanf(string(output), "%d %d", &rows, &cols); err != nil {
+		return 0, 0
+	}
+	return cols, rows
+}
+
+// size returns the terminal size, falling back to defaults when unknown
+func (k *KeyboardSelector) size() (int, int) {
+	width, height := k.width, k.height
+	if width <= 0 {
+		width = defaultTerminalWidth
+	}
+	if height <= 0 {
+		height = defaultTerminalHeight
+	}
+	return width, height
+}
+
+// listRows returns how many worktrees fit on screen at once
+func (k *KeyboardSelector) listRows() int {
+	_, height := k.size()
+
+	// Title, two separators, help text and the position indicator
+	available := max(height-5, 1)
+	if len(k.worktrees) <= available+1 {
+		return len(k.worktrees)
+	}
+	return available
+}
+
 // readKey reads a single key from input and returns a normalized key name
 func (k *KeyboardSelector) readKey() (string, error) {
 	buf := make([]byte, 4)
@@ -141,15 +197,34 @@ func (k *KeyboardSelector) readKey() (string, error) {
 			return "j", nil
 		case 107: // k
 			return "k", nil
+		case 103: // g
+			return "g", nil
+		case 71: // G
+			return "G", nil
 		case 113: // q
 			return "q", nil
 		}
-	case n == 3 && buf[0] == 27 && buf[1] == 91: // ESC [ sequence
+	case n == 3 && buf[0] == 27 && (buf[1] == 91 || buf[1] == 79): // ESC [ or ESC O sequence
 		switch buf[2] {
 		case 65: // Up arrow
 			return "up", nil
 		case 66: // Down arrow
 			return "down", nil
+		case 72: // Home
+			return "home", nil
+		case 70: // End
+			return "end", nil
+		}
+	case n == 4 && buf[0] == 27 && buf[1] == 91 && buf[3] == 126: // ESC [ n ~ sequence
+		switch buf[2] {
+		case 49, 55: // Home
+			return "home", nil
+		case 52, 56: // End
+			return "end", nil
+		case 53: // Page Up
+			return "pgup", nil
+		case 54: // Page Down
+			return "pgdown", nil
 		}
 	}
 
@@ -158,15 +233,26 @@ func (k *KeyboardSelector) readKey() (string, error) {
 
 // render draws the current state of the selector
 func (k *KeyboardSelector) render() {
+	width, _ := k.size()
+	rows := k.listRows()
+	k.offset = scrollOffset(k.cursor, k.offset, rows, len(k.worktrees))
+
 	// Clear screen and move cursor to top
 	_, _ = fmt.Fprint(k.output, "\033[2J\033[H")
 
 	// Title
 	_, _ = fmt.Fprintf(k.output, "\033[1m🌲 Git Worktrees\033[0m\n")
-	_, _ = fmt.Fprintf(k.output, "%s\n", strings.Repeat("-", 60))
+	_, _ = fmt.Fprintf(k.output, "%s\n", strings.Repeat("-", width))
 
-	// Worktree list
-	for i, wt := range k.worktrees {
+	// Worktree list: a two-column status marker, the branch, two spaces and the path
+	branchWidth := 0
+	for _, wt := range k.worktrees {
+		branchWidth = max(branchWidth, lipgloss.Width(wt.Branch))
+	}
+	branchWidth, pathWidth := listColumns(branchWidth, width, 4)
+
+	for i := k.offset; i < min(k.offset+rows, len(k.worktrees)); i++ {
+		wt := k.worktrees[i]
 		status := "  "
 		if wt.IsCurrent {
 			status = "* "
@@ -177,12 +263,18 @@ func (k *KeyboardSelector) render() {
 			_, _ = fmt.Fprintf(k.output, "\033[7m") // Reverse video
 		}
 
-		_, _ = fmt.Fprintf(k.output, "%s%s (%s)\033[0m\n", status, wt.Path, wt.Branch)
+		branch := truncate(wt.Branch, branchWidth)
+		branch += strings.Repeat(" ", branchWidth-lipgloss.Width(branch))
+		_, _ = fmt.Fprintf(k.output, "%s%s  %s\033[0m\n", status, branch, shortenPathTo(wt.Path, pathWidth))
+	}
+	above, below := k.offset > 0, k.offset+rows < len(k.worktrees)
+	if indicator := positionIndicator(k.cursor, len(k.worktrees), above, below); indicator != "" {
+		_, _ = fmt.Fprintf(k.output, "\033[2m%s\033[0m\n", indicator)
 	}
 
 	// Help text
-	_, _ = fmt.Fprintf(k.output, "%s\n", strings.Repeat("-", 60))
-	_, _ = fmt.Fprintf(k.output, "\033[2m↑/k up  ↓/j down  Enter select  q quit\033[0m\n")
+	_, _ = fmt.Fprintf(k.output, "%s\n", strings.Repeat("-", width))
+	_, _ = fmt.Fprintf(k.output, "\033[2m%s\033[0m\n", truncate("↑/k up  ↓/j down  PgUp/PgDn page  g/G top/bottom  Enter select  q quit", width))
 }
 
 // clearScreen clears the screen
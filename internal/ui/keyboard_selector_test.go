@@ -2,15 +2,18 @@ package ui
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 
-	"github.com/yagi2/yosegi/internal/git"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 func TestNewKeyboardSelector(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/1", Branch: "main", IsCurrent: false},
 	}
 
@@ -40,7 +43,7 @@ func TestNewKeyboardSelector(t *testing.T) {
 }
 
 func TestKeyboardSelectorEmptyWorktrees(t *testing.T) {
-	var worktrees []git.Worktree
+	var worktrees []worktree.Worktree
 	var input, output bytes.Buffer
 
 	selector := newKeyboardSelectorWithFiles(worktrees, &mockFile{&input}, &mockFile{&output})
@@ -52,7 +55,7 @@ func TestKeyboardSelectorEmptyWorktrees(t *testing.T) {
 }
 
 func TestKeyboardSelectorReadKey(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/1", Branch: "main", IsCurrent: false},
 	}
 
@@ -101,6 +104,36 @@ func TestKeyboardSelectorReadKey(t *testing.T) {
 			input:    []byte{27, 91, 66},
 			expected: "down",
 		},
+		{
+			name:     "g key",
+			input:    []byte{103},
+			expected: "g",
+		},
+		{
+			name:     "G key",
+			input:    []byte{71},
+			expected: "G",
+		},
+		{
+			name:     "Home",
+			input:    []byte{27, 91, 72},
+			expected: "home",
+		},
+		{
+			name:     "End",
+			input:    []byte{27, 79, 70},
+			expected: "end",
+		},
+		{
+			name:     "Page Up",
+			input:    []byte{27, 91, 53, 126},
+			expected: "pgup",
+		},
+		{
+			name:     "Page Down",
+			input:    []byte{27, 91, 54, 126},
+			expected: "pgdown",
+		},
 		{
 			name:     "Unknown key",
 			input:    []byte{120}, // 'x'
@@ -130,7 +163,7 @@ func TestKeyboardSelectorReadKey(t *testing.T) {
 }
 
 func TestKeyboardSelectorRender(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/repo/main", Branch: "main", IsCurrent: true},
 		{Path: "/repo/feature", Branch: "feature", IsCurrent: false},
 	}
@@ -152,11 +185,11 @@ func TestKeyboardSelectorRender(t *testing.T) {
 	}
 
 	// Check for worktree entries
-	if !bytes.Contains(output.Bytes(), []byte("* /repo/main (main)")) {
+	if !bytes.Contains(output.Bytes(), []byte("* main     /repo/main")) {
 		t.Error("Expected current worktree to be marked with asterisk")
 	}
 
-	if !bytes.Contains(output.Bytes(), []byte("  /repo/feature (feature)")) {
+	if !bytes.Contains(output.Bytes(), []byte("  feature  /repo/feature")) {
 		t.Error("Expected non-current worktree to be displayed")
 	}
 
@@ -166,8 +199,39 @@ func TestKeyboardSelectorRender(t *testing.T) {
 	}
 }
 
+func TestKeyboardSelectorRenderScrolls(t *testing.T) {
+	var worktrees []worktree.Worktree
+	for i := 0; i < 40; i++ {
+		worktrees = append(worktrees, worktree.Worktree{Path: fmt.Sprintf("/repo/wt-%02d", i), Branch: fmt.Sprintf("branch-%02d", i)})
+	}
+
+	var output bytes.Buffer
+	var input bytes.Buffer
+	selector := newKeyboardSelectorWithFiles(worktrees, &mockFile{&input}, &mockFile{&output})
+	selector.width, selector.height = 40, 12
+	selector.cursor = 30
+	selector.render()
+
+	view := output.String()
+	if !strings.Contains(view, "/repo/wt-30") || strings.Contains(view, "/repo/wt-00") || strings.Contains(view, "/repo/wt-39") {
+		t.Errorf("Expected only the rows around the cursor:\n%s", view)
+	}
+	if !strings.Contains(view, "▲ 31/40 ▼") {
+		t.Errorf("Expected a position indicator:\n%s", view)
+	}
+	if lines := strings.Count(view, "\n"); lines > 12 {
+		t.Errorf("Expected output to fit 12 lines, got %d", lines)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		line = strings.NewReplacer("\033[2J\033[H", "", "\033[1m", "", "\033[0m", "", "\033[2m", "", "\033[7m", "").Replace(line)
+		if lipgloss.Width(line) > 40 {
+			t.Errorf("Line wider than the terminal: %q", line)
+		}
+	}
+}
+
 func TestKeyboardSelectorClearScreen(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/1", Branch: "main", IsCurrent: false},
 	}
 
@@ -184,7 +248,7 @@ func TestKeyboardSelectorClearScreen(t *testing.T) {
 }
 
 func TestKeyboardSelectorSetRawModeFailure(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/1", Branch: "main", IsCurrent: false},
 	}
 
@@ -201,7 +265,7 @@ func TestKeyboardSelectorSetRawModeFailure(t *testing.T) {
 }
 
 func TestKeyboardSelectorRestoreMode(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/1", Branch: "main", IsCurrent: false},
 	}
 
@@ -251,7 +315,7 @@ func (e *errorReader) Write(p []byte) (n int, err error) {
 
 func TestNewKeyboardSelectorWrapper(t *testing.T) {
 	// Test the wrapper function NewKeyboardSelector
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/test/path", Branch: "main", IsCurrent: false},
 	}
 
@@ -274,7 +338,7 @@ func TestNewKeyboardSelectorWrapper(t *testing.T) {
 
 // Test the terminal control commands (stty)
 func TestKeyboardSelectorTerminalCommands(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/1", Branch: "main", IsCurrent: false},
 	}
 
@@ -295,7 +359,7 @@ func TestKeyboardSelectorTerminalCommands(t *testing.T) {
 
 // Benchmark tests
 func BenchmarkKeyboardSelectorRender(b *testing.B) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/repo/main", Branch: "main", IsCurrent: true},
 		{Path: "/repo/feature-1", Branch: "feature-1", IsCurrent: false},
 		{Path: "/repo/feature-2", Branch: "feature-2", IsCurrent: false},
@@ -314,7 +378,7 @@ func BenchmarkKeyboardSelectorRender(b *testing.B) {
 }
 
 func BenchmarkKeyboardSelectorReadKey(b *testing.B) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/1", Branch: "main", IsCurrent: false},
 	}
 
@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// Limits on what the preview pane lists
+const (
+	previewCommits   = 5
+	previewFiles     = 8
+	previewStatFiles = 8
+)
+
+// Preview holds what the preview pane shows about a worktree
+type Preview struct {
+	Commits  []worktree.Commit
+	Status   *worktree.Status
+	Base     string // branch the diffstat compares against, empty when unknown
+	DiffStat string // 'git diff --stat' output against the merge base with Base
+	Err      error
+}
+
+// PreviewFunc loads the preview of a worktree. It runs outside the UI loop,
+// so it may be slow.
+type PreviewFunc func(wt worktree.Worktree) Preview
+
+// GitPreview returns a PreviewFunc that reads a worktree's recent commits,
+// status and changes since it branched off base. Without a base, or when the
+// worktree shares no history with it, the diffstat is left out.
+func GitPreview(ctx context.Context, manager worktree.Manager, base string) PreviewFunc {
+	return func(wt worktree.Worktree) Preview {
+		var p Preview
+		if p.Commits, p.Err = manager.Log(ctx, wt.Path, previewCommits); p.Err != nil {
+			return p
+		}
+		if p.Status, p.Err = manager.Status(ctx, wt.Path); p.Err != nil {
+			return p
+		}
+		if base == "" {
+			return p
+		}
+		mergeBase, err := manager.MergeBase(ctx, wt.Path, base)
+		if err != nil {
+			return p
+		}
+		p.Base = base
+		p.DiffStat, p.Err = manager.Diff(ctx, wt.Path, worktree.DiffOptions{Base: mergeBase, Stat: true})
+		return p
+	}
+}
+
+// previewMsg delivers a loaded preview to the selector
+type previewMsg struct {
+	path    string
+	preview Preview
+}
+
+// loadPreview returns a command loading the preview of wt
+func loadPreview(fn PreviewFunc, wt worktree.Worktree) tea.Cmd {
+	return func() tea.Msg {
+		return previewMsg{path: wt.Path, preview: fn(wt)}
+	}
+}
+
+// renderPreview renders a preview as lines no wider than width, cut to
+// height lines when height is positive
+func renderPreview(p *Preview, width, height int, now time.Time) string {
+	var lines []string
+	heading := func(text string) {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, SubtitleStyle.Render(truncate(text, width)))
+	}
+	add := func(text string) {
+		lines = append(lines, NormalStyle.Render(truncate(text, width)))
+	}
+
+	switch {
+	case p == nil:
+		add("Loading...")
+	case p.Err != nil:
+		lines = append(lines, ErrorStyle.UnsetPadding().Render(truncate(p.Err.Error(), width)))
+	default:
+		heading("Recent commits")
+		for _, c := range p.Commits {
+			add(fmt.Sprintf("%s %s (%s)", c.Hash, c.Subject, relativeTime(c.Date, now)))
+		}
+		if len(p.Commits) == 0 {
+			add("No commits")
+		}
+
+		heading("Status")
+		if p.Status.Clean() {
+			add("Clean")
+		}
+		for i, file := range p.Status.Files {
+			if i == previewFiles {
+				add(fmt.Sprintf("... and %d more", len(p.Status.Files)-previewFiles))
+				break
+			}
+			add(file.Code + " " + file.Path)
+		}
+
+		if p.Base != "" {
+			heading("Changes vs " + p.Base)
+			stat := strings.Split(strings.TrimRight(p.DiffStat, "\n"), "\n")
+			switch {
+			case p.DiffStat == "":
+				add("None")
+			case len(stat) > previewStatFiles+1:
+				// Keep the summary line at the end
+				for _, line := range stat[:previewStatFiles] {
+					add(strings.TrimSpace(line))
+				}
+				add(fmt.Sprintf("... and %d more", len(stat)-1-previewStatFiles))
+				add(strings.TrimSpace(stat[len(stat)-1]))
+			default:
+				for _, line := range stat {
+					add(strings.TrimSpace(line))
+				}
+			}
+		}
+	}
+
+	if height > 0 && len(lines) > height {
+		lines = lines[:height]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncate shortens s to at most width terminal cells, marking the cut with
+// an ellipsis. A width of zero or less leaves s unchanged.
+func truncate(s string, width int) string {
+	if width <= 0 || lipgloss.Width(s) <= width {
+		return s
+	}
+
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := lipgloss.Width(string(r))
+		if used+w > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	return b.String() + "…"
+}
+
+// relativeTime describes how long before now t was, e.g. "3h ago"
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+	return t.Format("2006-01-02")
+}
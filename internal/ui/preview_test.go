@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yagi2/yosegi/pkg/worktree"
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
+)
+
+func TestGitPreview(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("log").Return("abc1234\x00Ada\x001700000000\x00Add login\n")
+	runner.On("status").Return("# branch.head feature\n1 .M N... 100644 100644 100644 aaa bbb app.go\n")
+	runner.On("merge-base", "origin/main", "HEAD").Return("def5678\n")
+	runner.On("diff").Return(" app.go | 2 +-\n 1 file changed, 1 insertion(+), 1 deletion(-)\n")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+	p := GitPreview(context.Background(), manager, "origin/main")(worktree.Worktree{Path: "/repo/feature", Branch: "feature"})
+	if p.Err != nil {
+		t.Fatalf("Unexpected error: %v", p.Err)
+	}
+	if len(p.Commits) != 1 || p.Commits[0].Subject != "Add login" {
+		t.Errorf("Expected recent commits, got %+v", p.Commits)
+	}
+	if p.Status == nil || len(p.Status.Files) != 1 || p.Status.Files[0].Path != "app.go" {
+		t.Errorf("Expected status, got %+v", p.Status)
+	}
+	if p.Base != "origin/main" || !strings.Contains(p.DiffStat, "1 file changed") {
+		t.Errorf("Expected diffstat against origin/main, got %q, %q", p.Base, p.DiffStat)
+	}
+	if !runner.Called("diff", "--no-color", "--no-ext-diff", "--stat", "def5678") {
+		t.Errorf("Expected diff against the merge base, got %+v", runner.Calls())
+	}
+}
+
+func TestGitPreviewWithoutBase(t *testing.T) {
+	runner := worktreetest.NewRunner()
+	runner.On("log").Return("")
+	runner.On("status").Return("# branch.head main\n")
+	runner.On("merge-base").Fail("")
+	manager := worktree.NewManagerWithRunner("/repo", "/repo/.git", runner)
+
+	for _, base := range []string{"", "origin/main"} {
+		p := GitPreview(context.Background(), manager, base)(worktree.Worktree{Path: "/repo"})
+		if p.Err != nil || p.Base != "" || p.DiffStat != "" {
+			t.Errorf("Expected no diffstat for base %q, got %+v", base, p)
+		}
+	}
+	if runner.Called("diff") {
+		t.Error("Expected no diff without a merge base")
+	}
+}
+
+func TestRenderPreview(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	var files []worktree.FileStatus
+	for i := 0; i < previewFiles+2; i++ {
+		files = append(files, worktree.FileStatus{Code: "??", Path: fmt.Sprintf("file%d.txt", i)})
+	}
+	p := &Preview{
+		Commits:  []worktree.Commit{{Hash: "abc1234", Subject: "Add login", Date: now.Add(-3 * time.Hour)}},
+		Status:   &worktree.Status{Untracked: len(files), Files: files},
+		Base:     "origin/main",
+		DiffStat: " app.go | 2 +-\n 1 file changed, 1 insertion(+), 1 deletion(-)\n",
+	}
+
+	view := renderPreview(p, 40, 0, now)
+	for _, expected := range []string{"Recent commits", "abc1234 Add login (3h ago)", "?? file0.txt", "... and 2 more", "Changes vs origin/main", "1 file changed"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected preview to contain %q:\n%s", expected, view)
+		}
+	}
+	if strings.Contains(view, "file9.txt") {
+		t.Errorf("Expected file list to be cut:\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if lipgloss.Width(line) > 40 {
+			t.Errorf("Line wider than the pane: %q", line)
+		}
+	}
+
+	if lines := strings.Split(renderPreview(p, 40, 4, now), "\n"); len(lines) != 4 {
+		t.Errorf("Expected preview cut to 4 lines, got %d", len(lines))
+	}
+
+	clean := renderPreview(&Preview{Status: &worktree.Status{}}, 40, 0, now)
+	if !strings.Contains(clean, "Clean") || !strings.Contains(clean, "No commits") || strings.Contains(clean, "Changes vs") {
+		t.Errorf("Unexpected preview of a clean worktree:\n%s", clean)
+	}
+
+	if !strings.Contains(renderPreview(nil, 40, 0, now), "Loading") {
+		t.Error("Expected a loading message before the preview arrives")
+	}
+	if !strings.Contains(renderPreview(&Preview{Err: errors.New("not a git repository")}, 40, 0, now), "not a git repository") {
+		t.Error("Expected the error to be shown")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		input    string
+		width    int
+		expected string
+	}{
+		{input: "short", width: 10, expected: "short"},
+		{input: "exactly10!", width: 10, expected: "exactly10!"},
+		{input: "much too long", width: 8, expected: "much to…"},
+		{input: "日本語のブランチ", width: 7, expected: "日本語…"},
+		{input: "unbounded", width: 0, expected: "unbounded"},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.input, tt.width); got != tt.expected {
+			t.Errorf("truncate(%q, %d) = %q, expected %q", tt.input, tt.width, got, tt.expected)
+		}
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		ago      time.Duration
+		expected string
+	}{
+		{ago: 10 * time.Second, expected: "just now"},
+		{ago: 5 * time.Minute, expected: "5m ago"},
+		{ago: 3 * time.Hour, expected: "3h ago"},
+		{ago: 50 * time.Hour, expected: "2d ago"},
+		{ago: 60 * 24 * time.Hour, expected: "2024-11-11"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeTime(now.Add(-tt.ago), now); got != tt.expected {
+			t.Errorf("relativeTime(-%v) = %q, expected %q", tt.ago, got, tt.expected)
+		}
+	}
+}
+
+func TestSelectorPreview(t *testing.T) {
+	worktrees := []worktree.Worktree{
+		{Path: "/path/to/main", Branch: "main", IsCurrent: true},
+		{Path: "/path/to/feature", Branch: "feature"},
+	}
+	loaded := make(map[string]int)
+	fn := func(wt worktree.Worktree) Preview {
+		loaded[wt.Path]++
+		return Preview{Status: &worktree.Status{}, Commits: []worktree.Commit{{Hash: "abc1234", Subject: "Work on " + wt.Branch}}}
+	}
+
+	model := NewSelector(worktrees, "Test", "select", false).WithPreview(fn)
+
+	// The highlighted worktree's preview is loaded when the selector starts
+	cmd := model.Init()
+	if cmd == nil {
+		t.Fatal("Expected Init to load the first preview")
+	}
+	updated, _ := model.Update(cmd())
+	model = updated.(SelectorModel)
+	if !strings.Contains(model.View(), "Work on main") {
+		t.Errorf("Expected preview of main:\n%s", model.View())
+	}
+
+	// Moving the cursor loads the next preview once
+	updated, cmd = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if cmd == nil {
+		t.Fatal("Expected moving the cursor to load a preview")
+	}
+	updated, _ = updated.Update(cmd())
+	updated, cmd = updated.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if cmd != nil {
+		t.Error("Expected a loaded preview to be reused")
+	}
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model = updated.(SelectorModel)
+	if !strings.Contains(model.View(), "Work on feature") || loaded["/path/to/feature"] != 1 {
+		t.Errorf("Expected preview of feature loaded once, got %v:\n%s", loaded, model.View())
+	}
+
+	if NewSelector(worktrees, "Test", "select", false).Init() != nil {
+		t.Error("Expected no preview to load when previews are disabled")
+	}
+}
+
+func TestSelectorPreviewLayout(t *testing.T) {
+	worktrees := []worktree.Worktree{
+		{Path: "/home/user/src/project", Branch: "main", IsCurrent: true},
+		{Path: "/home/user/src/project-feature-with-a-rather-long-name", Branch: "feature/with-a-rather-long-name"},
+	}
+	fn := func(wt worktree.Worktree) Preview {
+		return Preview{Status: &worktree.Status{}, Commits: []worktree.Commit{{Hash: "abc1234", Subject: strings.Repeat("long subject ", 20)}}}
+	}
+
+	for _, size := range []tea.WindowSizeMsg{{Width: 140, Height: 30}, {Width: 80, Height: 30}, {Width: 50, Height: 20}} {
+		t.Run(fmt.Sprintf("%dx%d", size.Width, size.Height), func(t *testing.T) {
+			model := NewSelector(worktrees, "Test", "select", false).WithPreview(fn)
+			updated, _ := model.Update(size)
+			updated, _ = updated.Update(model.Init()())
+			view := updated.(SelectorModel).View()
+
+			if width := lipgloss.Width(view); width > size.Width {
+				t.Errorf("Expected view to fit %d columns, got %d:\n%s", size.Width, width, view)
+			}
+			if !strings.Contains(view, "Recent commits") {
+				t.Errorf("Expected the preview pane:\n%s", view)
+			}
+
+			// Side by side, the preview starts on the first worktree's line
+			sideBySide := size.Width-6 >= sideBySideWidth
+			for _, line := range strings.Split(view, "\n") {
+				if strings.Contains(line, "main") {
+					if got := strings.Contains(line, "╭"); got != sideBySide {
+						t.Errorf("Expected side by side layout %v:\n%s", sideBySide, view)
+					}
+					break
+				}
+			}
+		})
+	}
+}
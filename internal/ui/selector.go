@@ -3,118 +3,501 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/yagi2/yosegi/internal/git"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 type keyMap struct {
-	Up     key.Binding
-	Down   key.Binding
-	Enter  key.Binding
-	Quit   key.Binding
-	Delete key.Binding
-	Create key.Binding
-}
-
-var keys = keyMap{
-	Up: key.NewBinding(
-		key.WithKeys("up", "k"),
-		key.WithHelp("↑/k", "move up"),
-	),
-	Down: key.NewBinding(
-		key.WithKeys("down", "j"),
-		key.WithHelp("↓/j", "move down"),
-	),
-	Enter: key.NewBinding(
-		key.WithKeys("enter"),
-		key.WithHelp("enter", "select"),
-	),
-	Quit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c"),
-		key.WithHelp("q", "quit"),
-	),
-	Delete: key.NewBinding(
-		key.WithKeys("d"),
-		key.WithHelp("d", "delete"),
-	),
-	Create: key.NewBinding(
-		key.WithKeys("c"),
-		key.WithHelp("c", "create new"),
-	),
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+	Enter    key.Binding
+	Quit     key.Binding
+	Delete   key.Binding
+	Create   key.Binding
+	Move     key.Binding
+	Sort     key.Binding
+	Group    key.Binding
+	Actions  key.Binding
+	Open     key.Binding
+	Help     key.Binding
 }
 
+// keys are the selector's bindings, changed by ApplyKeyBindings
+var keys = defaultKeys()
+
+// defaultKeys returns the selector's bindings before configuration
+func defaultKeys() keyMap {
+	return keyMap{
+		Up:       newBinding("up", "up", "k"),
+		Down:     newBinding("down", "down", "j"),
+		PageUp:   newBinding("page up", "pgup"),
+		PageDown: newBinding("page down", "pgdown"),
+		Top:      newBinding("top", "home", "g"),
+		Bottom:   newBinding("bottom", "end", "G"),
+		Enter:    newBinding("select", "enter"),
+		Quit:     newBinding("quit", "q", alwaysQuit),
+		Delete:   newBinding("delete", "d"),
+		Create:   newBinding("create", "c"),
+		Move:     newBinding("move", "m"),
+		Sort:     newBinding("sort", "s"),
+		Group:    newBinding("group", "p"),
+		Actions:  newBinding("actions", "a", " "),
+		Open:     newBinding("open", "o"),
+		Help:     newBinding("help", "?"),
+	}
+}
+
+// actions lists the configurable actions of the selector
+func (k *keyMap) actions() []keyAction {
+	return []keyAction{
+		{name: "up", binding: &k.Up, desc: "up"},
+		{name: "down", binding: &k.Down, desc: "down"},
+		{name: "page_up", binding: &k.PageUp, desc: "page up"},
+		{name: "page_down", binding: &k.PageDown, desc: "page down"},
+		{name: "top", binding: &k.Top, desc: "top"},
+		{name: "bottom", binding: &k.Bottom, desc: "bottom"},
+		{name: "select", binding: &k.Enter, desc: "select"},
+		{name: "quit", binding: &k.Quit, desc: "quit", quit: true},
+		{name: "delete", binding: &k.Delete, desc: "delete"},
+		{name: "create", binding: &k.Create, desc: "create"},
+		{name: "move", binding: &k.Move, desc: "move"},
+		{name: "sort", binding: &k.Sort, desc: "sort"},
+		{name: "group", binding: &k.Group, desc: "group"},
+		{name: "actions", binding: &k.Actions, desc: "actions"},
+		{name: "open", binding: &k.Open, desc: "open"},
+		{name: "help", binding: &k.Help, desc: "help"},
+	}
+}
+
+// sideBySideWidth is the inner width from which the preview pane is shown
+// next to the list instead of below it
+const sideBySideWidth = 100
+
 type SelectorModel struct {
-	worktrees    []git.Worktree
+	worktrees    []worktree.Worktree
 	cursor       int
+	offset       int // first line shown when the list scrolls
 	title        string
 	action       string
+	label        string // describes the enter key, and action until one is chosen
+	allowCreate  bool
 	allowDelete  bool
+	allowMove    bool
 	selectedPath string
 	quitting     bool
+	done         bool // an action was chosen or the selector was quit
+
+	// Terminal size from the last tea.WindowSizeMsg, zero until one arrives
+	width  int
+	height int
+
+	preview  PreviewFunc
+	previews map[string]*Preview // loaded previews by worktree path
+
+	sortable bool
+	sort     SortOptions
+	unsorted []worktree.Worktree // in git's order, to sort again from
+
+	reloadFn func() ([]worktree.Worktree, error)
+
+	sessionsFn SessionsFunc
+	sessions   map[string]bool // names of the running multiplexer sessions
+
+	help help.Model // ShowAll is set while the full help overlay is open
+
+	// The actions menu, enabled by WithActions, and the views opened from it
+	actions   *ActionOptions
+	mode      selectorMode
+	menu      int             // highlighted entry of the menu
+	prompt    textinput.Model // destination of a move
+	log       []worktree.Commit
+	status    string // outcome of the last action
+	statusErr bool
 }
 
 type SelectionResult struct {
-	Worktree git.Worktree
-	Action   string // "select", "delete", "create", "quit"
+	Worktree worktree.Worktree
+	Action   string // "select", "delete", "create", "move", "quit"
 }
 
-func NewSelector(worktrees []git.Worktree, title, action string, allowDelete bool) SelectorModel {
+func NewSelector(worktrees []worktree.Worktree, title, action string, allowDelete bool) SelectorModel {
 	return SelectorModel{
 		worktrees:   worktrees,
 		cursor:      0,
 		title:       title,
 		action:      action,
+		label:       action,
 		allowDelete: allowDelete,
+		help:        newHelp(),
+	}
+}
+
+// WithCreate enables the create key, for callers that can create a worktree
+// when it is pressed
+func (m SelectorModel) WithCreate() SelectorModel {
+	m.allowCreate = true
+	return m
+}
+
+// WithMove enables the move key for relocating the highlighted worktree
+func (m SelectorModel) WithMove() SelectorModel {
+	m.allowMove = true
+	return m
+}
+
+// WithReload lets the selector list the worktrees again with fn after an
+// action, so that the list shows its effect
+func (m SelectorModel) WithReload(fn func() ([]worktree.Worktree, error)) SelectorModel {
+	m.reloadFn = fn
+	return m
+}
+
+// WithPreview shows a pane with details of the highlighted worktree, loaded
+// with fn as the cursor moves
+func (m SelectorModel) WithPreview(fn PreviewFunc) SelectorModel {
+	m.preview = fn
+	m.previews = make(map[string]*Preview)
+	return m
+}
+
+// WithSorting orders the list as opts says and enables the keys that change
+// the order and grouping
+func (m SelectorModel) WithSorting(opts SortOptions) SelectorModel {
+	m.sortable = true
+	m.unsorted = m.worktrees
+	m.sort = opts
+	m.worktrees = SortWorktrees(m.unsorted, opts)
+	return m
+}
+
+// sorted orders the list as opts says, keeping the cursor on the highlighted
+// worktree
+func (m SelectorModel) sorted(opts SortOptions) SelectorModel {
+	var highlighted string
+	if len(m.worktrees) > 0 {
+		highlighted = m.worktrees[m.cursor].Path
+	}
+
+	m.sort = opts
+	m.worktrees = SortWorktrees(m.unsorted, opts)
+	for i, wt := range m.worktrees {
+		if wt.Path == highlighted {
+			m.cursor = i
+		}
 	}
+	m.offset = m.scrolled(m.listLines(), m.layout().listRows)
+	return m
 }
 
 func (m SelectorModel) Init() tea.Cmd {
-	return nil
+	return tea.Batch(m.loadPreview(), m.loadSessions())
+}
+
+// loadPreview returns a command loading the highlighted worktree's preview,
+// or nil when it is loaded already or previews are disabled
+func (m SelectorModel) loadPreview() tea.Cmd {
+	if m.preview == nil || len(m.worktrees) == 0 {
+		return nil
+	}
+	wt := m.worktrees[m.cursor]
+	if _, loaded := m.previews[wt.Path]; loaded {
+		return nil
+	}
+	return loadPreview(m.preview, wt)
 }
 
 func (m SelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.offset = m.scrolled(m.listLines(), m.layout().listRows)
+
+	case previewMsg:
+		if m.previews != nil {
+			preview := msg.preview
+			m.previews[msg.path] = &preview
+		}
+
+	case sessionsMsg:
+		m.sessions = msg.sessions
+
+	case actionMsg, reloadMsg, logMsg:
+		return m.updateAction(msg)
+
 	case tea.KeyMsg:
+		if m.help.ShowAll {
+			return m.updateHelp(msg)
+		}
+		if m.mode != modeList {
+			return m.updateMode(msg)
+		}
+		m.status = ""
+
 		switch {
 		case key.Matches(msg, keys.Quit):
-			m.quitting = true
+			m.quitting, m.done = true, true
 			return m, tea.Quit
 
+		case key.Matches(msg, keys.Help):
+			m.help.ShowAll = true
+
 		case key.Matches(msg, keys.Up):
-			if m.cursor > 0 {
-				m.cursor--
-			}
+			return m.moveCursor("up")
 
 		case key.Matches(msg, keys.Down):
-			if m.cursor < len(m.worktrees)-1 {
-				m.cursor++
-			}
+			return m.moveCursor("down")
+
+		case key.Matches(msg, keys.PageUp):
+			return m.moveCursor("pgup")
+
+		case key.Matches(msg, keys.PageDown):
+			return m.moveCursor("pgdown")
+
+		case key.Matches(msg, keys.Top):
+			return m.moveCursor("home")
+
+		case key.Matches(msg, keys.Bottom):
+			return m.moveCursor("end")
 
 		case key.Matches(msg, keys.Enter):
 			if len(m.worktrees) > 0 {
 				m.selectedPath = m.worktrees[m.cursor].Path
-				m.action = "select"
+				m.action, m.done = "select", true
 				return m, tea.Quit
 			}
 
 		case key.Matches(msg, keys.Delete):
 			if m.allowDelete && len(m.worktrees) > 0 {
 				m.selectedPath = m.worktrees[m.cursor].Path
-				m.action = "delete"
+				m.action, m.done = "delete", true
+				return m, tea.Quit
+			}
+
+		case key.Matches(msg, keys.Move):
+			if m.allowMove && len(m.worktrees) > 0 {
+				m.selectedPath = m.worktrees[m.cursor].Path
+				m.action, m.done = "move", true
 				return m, tea.Quit
 			}
 
+		case key.Matches(msg, keys.Sort):
+			if m.sortable {
+				opts := m.sort
+				opts.Order = opts.Order.next()
+				return m.sorted(opts), nil
+			}
+
+		case key.Matches(msg, keys.Group):
+			if m.sortable {
+				opts := m.sort
+				opts.Group = !opts.Group
+				return m.sorted(opts), nil
+			}
+
 		case key.Matches(msg, keys.Create):
-			m.action = "create"
-			return m, tea.Quit
+			if m.allowCreate {
+				m.action, m.done = "create", true
+				return m, tea.Quit
+			}
+
+		case key.Matches(msg, keys.Actions):
+			if m.actions != nil && len(m.worktrees) > 0 {
+				m.mode, m.menu = modeMenu, 0
+			}
+
+		case key.Matches(msg, keys.Open):
+			if m.actions != nil && len(m.worktrees) > 0 {
+				return openEditor(m, m.worktrees[m.cursor])
+			}
 		}
 	}
 
 	return m, nil
 }
 
+// updateHelp handles keys while the full help overlay is open: the help key
+// or esc closes it, and quitting still works
+func (m SelectorModel) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		m.quitting, m.done = true, true
+		return m, tea.Quit
+	case key.Matches(msg, keys.Help), msg.Type == tea.KeyEsc:
+		m.help.ShowAll = false
+	}
+	return m, nil
+}
+
+// ShortHelp returns the bindings shown in the help line, leaving out
+// actions that are disabled in this selector
+func (m SelectorModel) ShortHelp() []key.Binding {
+	switch m.mode {
+	case modeMenu:
+		return []key.Binding{keys.Up, keys.Down, withHelpDesc(keys.Enter, "run"), closeKey}
+	case modeLog:
+		return []key.Binding{closeKey}
+	}
+
+	bindings := []key.Binding{keys.Up, keys.Down, withHelpDesc(keys.Enter, m.label)}
+	bindings = append(bindings, m.actionBindings()...)
+	return append(bindings, keys.Help, keys.Quit)
+}
+
+// FullHelp returns every binding available in this selector, by column
+func (m SelectorModel) FullHelp() [][]key.Binding {
+	columns := [][]key.Binding{
+		{keys.Up, keys.Down, keys.PageUp, keys.PageDown, keys.Top, keys.Bottom},
+		append([]key.Binding{withHelpDesc(keys.Enter, m.label)}, m.actionBindings()...),
+	}
+	return append(columns, []key.Binding{keys.Help, keys.Quit})
+}
+
+// actionBindings returns the bindings of the optional actions that are
+// enabled in this selector
+func (m SelectorModel) actionBindings() []key.Binding {
+	var bindings []key.Binding
+	if m.allowCreate {
+		bindings = append(bindings, keys.Create)
+	}
+	if m.allowDelete {
+		bindings = append(bindings, keys.Delete)
+	}
+	if m.allowMove {
+		bindings = append(bindings, keys.Move)
+	}
+	if m.sortable {
+		bindings = append(bindings, keys.Sort, keys.Group)
+	}
+	if m.actions != nil {
+		bindings = append(bindings, keys.Actions, keys.Open)
+	}
+	return bindings
+}
+
+// moveCursor applies a navigation key, scrolls the list to keep the cursor
+// in view and loads the preview of the newly highlighted worktree
+func (m SelectorModel) moveCursor(key string) (tea.Model, tea.Cmd) {
+	rows := m.layout().listRows
+	m.cursor = moveCursor(key, m.cursor, rows, len(m.worktrees))
+	m.offset = m.scrolled(m.listLines(), rows)
+	return m, m.loadPreview()
+}
+
+// listLine is a line of the list: the worktree at index, or the heading of a
+// group of branches when index is -1
+type listLine struct {
+	index int
+	group string
+}
+
+// listLines returns the lines of the list, with a heading before each group
+// when grouping by prefix. Branches without a prefix get no heading.
+func (m SelectorModel) listLines() []listLine {
+	lines := make([]listLine, 0, len(m.worktrees))
+	group := ""
+	for i, wt := range m.worktrees {
+		if m.sort.Group {
+			if g := branchGroup(wt.Branch); g != group {
+				group = g
+				lines = append(lines, listLine{index: -1, group: g})
+			}
+		}
+		lines = append(lines, listLine{index: i})
+	}
+	return lines
+}
+
+// scrolled returns the first line to show in a window of rows lines so that
+// the cursor stays in view, along with its group's heading when the cursor is
+// on the first worktree of a group
+func (m SelectorModel) scrolled(lines []listLine, rows int) int {
+	line := 0
+	for i, l := range lines {
+		if l.index == m.cursor {
+			line = i
+		}
+	}
+
+	offset := scrollOffset(line, m.offset, rows, len(lines))
+	if offset == line && line > 0 && lines[line-1].index < 0 {
+		offset--
+	}
+	return offset
+}
+
+// selectorLayout is how the terminal is divided between list and preview
+type selectorLayout struct {
+	innerWidth int // inside the border, zero until the terminal size is known
+	listWidth  int
+	listRows   int // worktrees shown at once, zero for all of them
+	bodyRows   int // rows between the title and the help, zero for no limit
+	sideBySide bool
+	paneWidth  int
+	paneHeight int // lines of preview content, zero for no limit
+}
+
+// layout divides the terminal between the list and the preview pane
+func (m SelectorModel) layout() selectorLayout {
+	var l selectorLayout
+	if m.width <= 0 {
+		l.paneWidth = 60
+		return l
+	}
+
+	// The border and its padding take 6 columns and 4 rows
+	l.innerWidth = max(m.width-6, 20)
+	innerHeight := max(m.height-4, 0)
+
+	l.listWidth = l.innerWidth
+	l.paneWidth = l.innerWidth
+	l.sideBySide = m.preview != nil && l.innerWidth >= sideBySideWidth
+	if l.sideBySide {
+		l.listWidth = l.innerWidth * 45 / 100
+		l.paneWidth = l.innerWidth - l.listWidth - 2
+	}
+	if innerHeight == 0 {
+		return l
+	}
+
+	// Rows left after the title, the blank line and the help text
+	available := innerHeight - 3 - lipgloss.Height(m.footerView(l.innerWidth))
+	l.bodyRows = max(available, 1)
+	total := len(m.listLines())
+
+	listSpace := available
+	if m.preview != nil && !l.sideBySide {
+		// Stacked, the list gets at most half and the preview the rest
+		listSpace = max(available/2, 4)
+	}
+	l.listRows = total
+	if total > listSpace {
+		l.listRows = max(listSpace-1, 1) // one line for the position indicator
+	}
+
+	if m.preview != nil {
+		// The preview's border takes 2 rows
+		l.paneHeight = max(available-2, 3)
+		if !l.sideBySide {
+			used := l.listRows
+			if l.listRows < total {
+				used++
+			}
+			l.paneHeight = max(available-used-2, 3)
+		}
+	}
+	return l
+}
+
 func (m SelectorModel) View() string {
 	if m.quitting && m.selectedPath == "" {
 		return ""
@@ -124,58 +507,175 @@ func (m SelectorModel) View() string {
 
 	// Title
 	b.WriteString(TitleStyle.Render(fmt.Sprintf("🌲 %s", m.title)))
+	if m.sortable {
+		order := m.sort.Order.label()
+		if m.sort.Group {
+			order += ", grouped"
+		}
+		b.WriteString(SubtitleStyle.Render(" · " + order))
+	}
 	b.WriteString("\n\n")
 
 	if len(m.worktrees) == 0 {
 		b.WriteString(ErrorStyle.Render("No worktrees found"))
 		b.WriteString("\n\n")
-		b.WriteString(HelpStyle.Render("Press q to quit"))
+		b.WriteString(HelpStyle.Render(fmt.Sprintf("Press %s to quit", keys.Quit.Help().Key)))
+		return BorderStyle.Render(b.String())
+	}
+
+	l := m.layout()
+	var overlay string
+	switch {
+	case m.help.ShowAll:
+		overlay = m.renderHelpOverlay(l.innerWidth)
+	case m.mode == modeMenu:
+		overlay = m.renderMenu(l.innerWidth)
+	case m.mode == modeLog:
+		overlay = m.renderLog(l.innerWidth, l.bodyRows)
+	}
+	if overlay != "" {
+		b.WriteString(overlay)
+		b.WriteString("\n\n")
+		b.WriteString(m.footerView(l.innerWidth))
 		return BorderStyle.Render(b.String())
 	}
 
-	// Worktree list
-	for i, worktree := range m.worktrees {
+	lines := m.listLines()
+	offset := m.scrolled(lines, l.listRows)
+	list := m.renderList(lines, l.listWidth, offset, l.listRows)
+	above, below := offset > 0, l.listRows > 0 && offset+l.listRows < len(lines)
+	if indicator := positionIndicator(m.cursor, len(m.worktrees), above, below); indicator != "" {
+		list += "\n" + SubtitleStyle.Render(indicator)
+	}
+
+	switch {
+	case m.preview == nil:
+		b.WriteString(list)
+	case l.sideBySide:
+		list = lipgloss.NewStyle().Width(l.listWidth).Render(list)
+		pane := m.renderPreviewPane(l.paneWidth, l.paneHeight)
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, list, "  ", pane))
+	default:
+		b.WriteString(list)
+		b.WriteString("\n")
+		b.WriteString(m.renderPreviewPane(l.paneWidth, l.paneHeight))
+	}
+	b.WriteString("\n\n")
+
+	// Action status and help text
+	b.WriteString(m.footerView(l.innerWidth))
+
+	return BorderStyle.Render(b.String())
+}
+
+// renderList renders rows of the list's lines from offset, or all of them
+// when rows is zero, fitting them into width cells when width is positive
+func (m SelectorModel) renderList(lines []listLine, width, offset, rows int) string {
+	branches := make([]string, len(m.worktrees))
+	branchWidth := 0
+	for i, wt := range m.worktrees {
+		branches[i] = fmt.Sprintf("%s %s", GetBranchIcon(), wt.Branch)
+		if wt.Locked {
+			branches[i] += " " + GetLockIcon()
+		}
+		if m.hasSession(wt) {
+			branches[i] += " " + GetSessionIcon()
+		}
+		branchWidth = max(branchWidth, lipgloss.Width(branches[i]))
+	}
+
+	// Each line has the status icon and the padding of two styled cells
+	branchWidth, pathWidth := listColumns(branchWidth, width, 7+lipgloss.Width(GetPathIcon()+" "))
+
+	end := len(lines)
+	if rows > 0 {
+		end = min(offset+rows, end)
+	}
+
+	var b strings.Builder
+	for n := offset; n < end; n++ {
+		if n > offset {
+			b.WriteString("\n")
+		}
+		i := lines[n].index
+		if i < 0 {
+			b.WriteString(SubtitleStyle.Render(truncate(lines[n].group, width)))
+			continue
+		}
+
+		wt := m.worktrees[i]
 		var line strings.Builder
 
 		// Status icon
-		icon := GetStatusIcon(worktree.IsCurrent)
-		if worktree.IsCurrent {
+		icon := GetStatusIcon(wt.IsCurrent)
+		if wt.IsCurrent {
 			line.WriteString(CurrentItemStyle.Render(icon + " "))
 		} else {
 			line.WriteString(NormalItemStyle.Render(icon + " "))
 		}
 
 		// Branch and path
-		branchInfo := fmt.Sprintf("%s %s", GetBranchIcon(), worktree.Branch)
-		pathInfo := fmt.Sprintf("%s %s", GetPathIcon(), shortenPath(worktree.Path))
+		branchInfo := truncate(branches[i], branchWidth)
+		branchInfo += strings.Repeat(" ", branchWidth-lipgloss.Width(branchInfo))
+		path := shortenPath(wt.Path)
+		if pathWidth > 0 {
+			path = shortenPathTo(wt.Path, pathWidth)
+		}
+		pathInfo := fmt.Sprintf("%s %s", GetPathIcon(), path)
 
-		content := fmt.Sprintf("%-30s %s", branchInfo, pathInfo)
+		content := branchInfo + " " + pathInfo
 
 		if i == m.cursor {
 			line.WriteString(SelectedItemStyle.Render(content))
-		} else if worktree.IsCurrent {
+		} else if wt.IsCurrent {
 			line.WriteString(CurrentItemStyle.Render(content))
 		} else {
 			line.WriteString(NormalItemStyle.Render(content))
 		}
 
 		b.WriteString(line.String())
-		b.WriteString("\n")
 	}
+	return b.String()
+}
 
-	// Help text
-	b.WriteString("\n")
-	helpText := []string{
-		"↑/k up", "↓/j down", "enter " + m.action, "c create",
-	}
-	if m.allowDelete {
-		helpText = append(helpText, "d delete")
+// renderPreviewPane renders the highlighted worktree's preview in a box of
+// the given outer width, with at most height lines of content
+func (m SelectorModel) renderPreviewPane(width, height int) string {
+	// The box's border and padding take 4 columns
+	contentWidth := max(width-4, 10)
+	content := renderPreview(m.previews[m.worktrees[m.cursor].Path], contentWidth, height, time.Now())
+	return PreviewStyle.Width(contentWidth + 2).Render(content)
+}
+
+// helpView renders the help line, cut to width when it is positive
+func (m SelectorModel) helpView(width int) string {
+	h := m.help
+	h.ShowAll = false
+	h.Width = width
+	return HelpStyle.Render(h.View(m))
+}
+
+// renderHelpOverlay renders every binding in a box shown in place of the list
+func (m SelectorModel) renderHelpOverlay(width int) string {
+	h := m.help
+	if width > 0 {
+		// The box's border and padding take 4 columns
+		h.Width = width - 4
 	}
-	helpText = append(helpText, "q quit")
+	return PreviewStyle.Render(SubtitleStyle.Render("Keys") + "\n\n" + h.FullHelpView(m.FullHelp()))
+}
 
-	b.WriteString(HelpStyle.Render(strings.Join(helpText, " • ")))
+// finished reports whether an action was chosen or the selector was quit,
+// with the result ready in GetResult
+func (m SelectorModel) finished() bool {
+	return m.done
+}
 
-	return BorderStyle.Render(b.String())
+// resumed returns the selector ready for another action once its result
+// has been handled without ending the program
+func (m SelectorModel) resumed() SelectorModel {
+	m.done, m.quitting, m.selectedPath, m.action = false, false, "", m.label
+	return m
 }
 
 func (m SelectorModel) GetResult() SelectionResult {
@@ -202,8 +702,18 @@ func (m SelectorModel) GetResult() SelectionResult {
 
 // shortenPath shortens a path for display
 func shortenPath(path string) string {
-	if len(path) <= 50 {
+	return shortenPathTo(path, 50)
+}
+
+// shortenPathTo keeps the end of a path, which tells worktrees apart, so that
+// it fits in width characters
+func shortenPathTo(path string, width int) string {
+	runes := []rune(path)
+	if len(runes) <= width {
 		return path
 	}
-	return "..." + path[len(path)-47:]
+	if width <= 3 {
+		return string(runes[len(runes)-width:])
+	}
+	return "..." + string(runes[len(runes)-width+3:])
 }
@@ -1,22 +1,24 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/yagi2/yosegi/internal/git"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 func TestNewSelector(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/to/main", Branch: "main", Commit: "abc123", IsCurrent: true},
 		{Path: "/path/to/feature", Branch: "feature", Commit: "def456", IsCurrent: false},
 	}
 
 	tests := []struct {
 		name        string
-		worktrees   []git.Worktree
+		worktrees   []worktree.Worktree
 		title       string
 		action      string
 		allowDelete bool
@@ -37,7 +39,7 @@ func TestNewSelector(t *testing.T) {
 		},
 		{
 			name:        "Empty worktree list",
-			worktrees:   []git.Worktree{},
+			worktrees:   []worktree.Worktree{},
 			title:       "Empty List",
 			action:      "select",
 			allowDelete: false,
@@ -80,7 +82,7 @@ func TestNewSelector(t *testing.T) {
 }
 
 func TestSelectorInit(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/to/main", Branch: "main", Commit: "abc123", IsCurrent: true},
 	}
 
@@ -93,7 +95,7 @@ func TestSelectorInit(t *testing.T) {
 }
 
 func TestSelectorUpdate(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/to/main", Branch: "main", Commit: "abc123", IsCurrent: true},
 		{Path: "/path/to/feature1", Branch: "feature1", Commit: "def456", IsCurrent: false},
 		{Path: "/path/to/feature2", Branch: "feature2", Commit: "ghi789", IsCurrent: false},
@@ -237,7 +239,7 @@ func TestSelectorUpdate(t *testing.T) {
 }
 
 func TestSelectorUpdateWithEmptyList(t *testing.T) {
-	model := NewSelector([]git.Worktree{}, "Empty", "select", false)
+	model := NewSelector([]worktree.Worktree{}, "Empty", "select", false)
 
 	// Test Enter with empty list
 	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
@@ -257,7 +259,7 @@ func TestSelectorUpdateWithEmptyList(t *testing.T) {
 }
 
 func TestSelectorView(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/to/main", Branch: "main", Commit: "abc123", IsCurrent: true},
 		{Path: "/path/to/feature", Branch: "feature", Commit: "def456", IsCurrent: false},
 	}
@@ -295,7 +297,7 @@ func TestSelectorView(t *testing.T) {
 		},
 		{
 			name:  "Empty worktree list",
-			model: NewSelector([]git.Worktree{}, "Empty", "select", false),
+			model: NewSelector([]worktree.Worktree{}, "Empty", "select", false),
 			expectedContains: []string{
 				"🌲 Empty",
 				"No worktrees found",
@@ -336,7 +338,7 @@ func TestSelectorView(t *testing.T) {
 }
 
 func TestSelectorGetResult(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/to/main", Branch: "main", Commit: "abc123", IsCurrent: true},
 		{Path: "/path/to/feature", Branch: "feature", Commit: "def456", IsCurrent: false},
 	}
@@ -484,7 +486,7 @@ func TestKeyMappings(t *testing.T) {
 }
 
 func TestSelectorWithCurrentWorktree(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/to/main", Branch: "main", Commit: "abc123", IsCurrent: false},
 		{Path: "/path/to/feature", Branch: "feature", Commit: "def456", IsCurrent: true},
 	}
@@ -504,9 +506,9 @@ func TestSelectorWithCurrentWorktree(t *testing.T) {
 
 // Benchmark tests
 func BenchmarkSelectorView(b *testing.B) {
-	worktrees := make([]git.Worktree, 100)
+	worktrees := make([]worktree.Worktree, 100)
 	for i := 0; i < 100; i++ {
-		worktrees[i] = git.Worktree{
+		worktrees[i] = worktree.Worktree{
 			Path:      "/path/to/worktree" + string(rune(i)),
 			Branch:    "branch" + string(rune(i)),
 			Commit:    "commit" + string(rune(i)),
@@ -523,7 +525,7 @@ func BenchmarkSelectorView(b *testing.B) {
 }
 
 func BenchmarkSelectorUpdate(b *testing.B) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/to/main", Branch: "main", Commit: "abc123", IsCurrent: true},
 		{Path: "/path/to/feature", Branch: "feature", Commit: "def456", IsCurrent: false},
 	}
@@ -537,3 +539,158 @@ func BenchmarkSelectorUpdate(b *testing.B) {
 		model = newModel.(SelectorModel)
 	}
 }
+
+func TestSelectorMoveKey(t *testing.T) {
+	worktrees := []worktree.Worktree{
+		{Path: "/path/to/main", Branch: "main", IsCurrent: true},
+		{Path: "/path/to/feature", Branch: "feature"},
+	}
+	moveKey := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}}
+
+	// Move is ignored unless enabled
+	model := NewSelector(worktrees, "Test", "select", true)
+	updated, cmd := model.Update(moveKey)
+	if cmd != nil {
+		t.Error("Expected move key to be ignored when move is disabled")
+	}
+	if strings.Contains(updated.(SelectorModel).View(), "m move") {
+		t.Error("Expected help to omit move when disabled")
+	}
+
+	model = NewSelector(worktrees, "Test", "select", true).WithMove()
+	if !strings.Contains(model.View(), "m move") {
+		t.Error("Expected help to show move when enabled")
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updated, cmd = updated.(SelectorModel).Update(moveKey)
+	if cmd == nil {
+		t.Fatal("Expected move key to quit the selector")
+	}
+
+	result := updated.(SelectorModel).GetResult()
+	if result.Action != "move" {
+		t.Errorf("Expected action 'move', got '%s'", result.Action)
+	}
+	if result.Worktree.Path != "/path/to/feature" {
+		t.Errorf("Expected highlighted worktree to be selected, got '%s'", result.Worktree.Path)
+	}
+}
+
+func TestSelectorLockBadge(t *testing.T) {
+	worktrees := []worktree.Worktree{
+		{Path: "/path/to/main", Branch: "main", IsCurrent: true},
+		{Path: "/path/to/locked", Branch: "locked", Locked: true},
+	}
+
+	view := NewSelector(worktrees, "Test", "select", false).View()
+	if strings.Count(view, GetLockIcon()) != 1 {
+		t.Errorf("Expected exactly one lock badge in view:\n%s", view)
+	}
+}
+
+func TestSelectorScrolling(t *testing.T) {
+	var worktrees []worktree.Worktree
+	for i := 0; i < 40; i++ {
+		worktrees = append(worktrees, worktree.Worktree{Path: fmt.Sprintf("/repo/wt-%02d", i), Branch: fmt.Sprintf("branch-%02d", i)})
+	}
+
+	var model tea.Model = NewSelector(worktrees, "Test", "select", false)
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+
+	view := model.View()
+	if !strings.Contains(view, "/repo/wt-00") || strings.Contains(view, "/repo/wt-39") {
+		t.Errorf("Expected the top of the list:\n%s", view)
+	}
+	if !strings.Contains(view, "1/40 ▼") {
+		t.Errorf("Expected a position indicator:\n%s", view)
+	}
+	if height := lipgloss.Height(view); height > 20 {
+		t.Errorf("Expected view to fit 20 rows, got %d", height)
+	}
+
+	steps := []struct {
+		key      tea.KeyMsg
+		expected string
+		cursor   int
+	}{
+		{key: tea.KeyMsg{Type: tea.KeyEnd}, expected: "/repo/wt-39", cursor: 39},
+		{key: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}}, expected: "/repo/wt-00", cursor: 0},
+		{key: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}}, expected: "/repo/wt-39", cursor: 39},
+		{key: tea.KeyMsg{Type: tea.KeyHome}, expected: "/repo/wt-00", cursor: 0},
+		{key: tea.KeyMsg{Type: tea.KeyPgDown}, expected: "/repo/wt-08", cursor: 8},
+		{key: tea.KeyMsg{Type: tea.KeyPgDown}, expected: "/repo/wt-16", cursor: 16},
+		{key: tea.KeyMsg{Type: tea.KeyPgUp}, expected: "/repo/wt-08", cursor: 8},
+	}
+	for _, step := range steps {
+		model, _ = model.Update(step.key)
+		view := model.View()
+		if !strings.Contains(view, step.expected) {
+			t.Errorf("After %s expected %s in view:\n%s", step.key, step.expected, view)
+		}
+		if cursor := model.(SelectorModel).cursor; cursor != step.cursor {
+			t.Errorf("After %s expected cursor %d, got %d", step.key, step.cursor, cursor)
+		}
+	}
+
+	// Shrinking the terminal keeps the cursor in view
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 60, Height: 14})
+	if view := model.View(); !strings.Contains(view, "/repo/wt-39") || lipgloss.Width(view) > 60 {
+		t.Errorf("Expected the cursor's row in a view fitting 60 columns:\n%s", view)
+	}
+}
+
+func TestSelectorHelp(t *testing.T) {
+	worktrees := []worktree.Worktree{
+		{Path: "/repo/main", Branch: "main", IsCurrent: true},
+		{Path: "/repo/feature", Branch: "feature"},
+	}
+
+	// Actions the caller cannot handle are neither shown nor triggered
+	plain := NewSelector(worktrees, "Test", "select", false)
+	if view := plain.View(); strings.Contains(view, "create") || strings.Contains(view, "delete") {
+		t.Errorf("Expected help without create and delete:\n%s", view)
+	}
+	updated, _ := plain.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if updated.(SelectorModel).GetResult().Action == "create" {
+		t.Error("Expected create to be disabled by default")
+	}
+
+	model := NewSelector(worktrees, "Test", "open", true).WithCreate()
+	if view := model.View(); !strings.Contains(view, "enter open") || !strings.Contains(view, "c create") || !strings.Contains(view, "? help") {
+		t.Errorf("Expected the help line to follow the enabled actions:\n%s", view)
+	}
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if updated.(SelectorModel).GetResult().Action != "create" {
+		t.Error("Expected create when enabled")
+	}
+
+	// ? opens the full help in place of the list
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	view := updated.View()
+	for _, expected := range []string{"Keys", "pgup", "page up", "home/g", "end/G", "bottom", "delete"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected %q in the help overlay:\n%s", expected, view)
+		}
+	}
+	if strings.Contains(view, "/repo/feature") {
+		t.Errorf("Expected the overlay to replace the list:\n%s", view)
+	}
+
+	// Keys other than closing and quitting are ignored while it is open
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if updated.(SelectorModel).cursor != 0 {
+		t.Error("Expected navigation to be ignored while help is open")
+	}
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(SelectorModel).help.ShowAll || !strings.Contains(updated.View(), "/repo/feature") {
+		t.Error("Expected esc to close the help overlay")
+	}
+
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	_, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if cmd == nil {
+		t.Error("Expected quit to work while help is open")
+	}
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yagi2/yosegi/internal/launch"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// SessionsFunc returns the names of the running multiplexer sessions
+type SessionsFunc func() (map[string]bool, error)
+
+// sessionsMsg delivers the running sessions
+type sessionsMsg struct {
+	sessions map[string]bool
+}
+
+// WithSessions marks worktrees whose multiplexer session, named by
+// launch.SessionName, is running. Sessions are listed when the selector
+// starts and whenever the list is reloaded.
+func (m SelectorModel) WithSessions(fn SessionsFunc) SelectorModel {
+	m.sessionsFn = fn
+	return m
+}
+
+// loadSessions returns a command listing the running sessions, or nil when
+// sessions are not shown. A multiplexer that fails to answer marks none.
+func (m SelectorModel) loadSessions() tea.Cmd {
+	if m.sessionsFn == nil {
+		return nil
+	}
+	fn := m.sessionsFn
+	return func() tea.Msg {
+		sessions, err := fn()
+		if err != nil {
+			return sessionsMsg{}
+		}
+		return sessionsMsg{sessions: sessions}
+	}
+}
+
+// hasSession reports whether wt's session is running
+func (m SelectorModel) hasSession(wt worktree.Worktree) bool {
+	return m.sessions[launch.SessionName(wt)]
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+func TestSelectorSessions(t *testing.T) {
+	worktrees := []worktree.Worktree{
+		{Path: "/repo", Branch: "main", IsCurrent: true},
+		{Path: "/work/login", Branch: "feature/login"},
+	}
+	running := map[string]bool{"feature-login-df7c7a": true}
+	model := NewSelector(worktrees, "Test", "select", false).
+		WithSessions(func() (map[string]bool, error) { return running, nil }).
+		WithReload(func() ([]worktree.Worktree, error) { return worktrees, nil })
+
+	if strings.Contains(model.View(), GetSessionIcon()) {
+		t.Error("Expected no session marked before they are listed")
+	}
+
+	// Sessions are listed when the selector starts
+	updated, _ := model.Update(model.Init()())
+	if view := updated.View(); strings.Count(view, GetSessionIcon()) != 1 {
+		t.Errorf("Expected the feature/login session to be marked:\n%s", view)
+	}
+	if !updated.(SelectorModel).hasSession(worktrees[1]) || updated.(SelectorModel).hasSession(worktrees[0]) {
+		t.Error("Expected only feature/login to have a session")
+	}
+
+	// and again after the list is reloaded
+	running = map[string]bool{"main": true}
+	updated, cmd := updated.Update(reloadMsg{worktrees: worktrees})
+	if cmd == nil {
+		t.Fatal("Expected the sessions to be listed again")
+	}
+	updated, _ = updated.Update(cmd())
+	if m := updated.(SelectorModel); !m.hasSession(worktrees[0]) || m.hasSession(worktrees[1]) {
+		t.Errorf("Expected the reloaded sessions, got %v", m.sessions)
+	}
+}
+
+func TestSelectorSessionsError(t *testing.T) {
+	worktrees := []worktree.Worktree{{Path: "/work/login", Branch: "feature/login"}}
+	model := NewSelector(worktrees, "Test", "select", false).
+		WithSessions(func() (map[string]bool, error) { return nil, errors.New("tmux failed") })
+
+	updated, _ := model.Update(model.Init()())
+	if strings.Contains(updated.View(), GetSessionIcon()) || strings.Contains(updated.View(), "tmux failed") {
+		t.Errorf("Expected a failing multiplexer to mark no sessions:\n%s", updated.View())
+	}
+
+	// Without WithSessions nothing is listed
+	if cmd := NewSelector(worktrees, "Test", "select", false).loadSessions(); cmd != nil {
+		t.Error("Expected no sessions to be loaded without WithSessions")
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/yagi2/yosegi/internal/git"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 // FileInterface defines the interface for file operations needed by the selector
@@ -19,12 +19,12 @@ type FileInterface interface {
 
 // SimpleSelectWorktree displays a numbered list of worktrees and prompts for selection
 // This is used when full TUI is not available (e.g., in command substitution)
-func SimpleSelectWorktree(worktrees []git.Worktree, output *os.File, input *os.File) (*git.Worktree, error) {
+func SimpleSelectWorktree(worktrees []worktree.Worktree, output *os.File, input *os.File) (*worktree.Worktree, error) {
 	return simpleSelectWorktreeWithFiles(worktrees, output, input)
 }
 
 // simpleSelectWorktreeWithFiles is the testable version that accepts interfaces
-func simpleSelectWorktreeWithFiles(worktrees []git.Worktree, output FileInterface, input FileInterface) (*git.Worktree, error) {
+func simpleSelectWorktreeWithFiles(worktrees []worktree.Worktree, output FileInterface, input FileInterface) (*worktree.Worktree, error) {
 	if len(worktrees) == 0 {
 		return nil, fmt.Errorf("no worktrees found")
 	}
@@ -7,11 +7,11 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/yagi2/yosegi/internal/git"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 func TestSimpleSelectWorktreeEmptyList(t *testing.T) {
-	var worktrees []git.Worktree
+	var worktrees []worktree.Worktree
 	var output bytes.Buffer
 	var input bytes.Buffer
 
@@ -29,7 +29,7 @@ func TestSimpleSelectWorktreeEmptyList(t *testing.T) {
 }
 
 func TestSimpleSelectWorktreeValidSelection(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/1", Branch: "main", IsCurrent: true},
 		{Path: "/path/2", Branch: "feature", IsCurrent: false},
 		{Path: "/path/3", Branch: "hotfix", IsCurrent: false},
@@ -121,7 +121,7 @@ func TestSimpleSelectWorktreeValidSelection(t *testing.T) {
 }
 
 func TestSimpleSelectWorktreeInvalidInput(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/1", Branch: "main", IsCurrent: false},
 		{Path: "/path/2", Branch: "feature", IsCurrent: false},
 	}
@@ -175,7 +175,7 @@ func TestSimpleSelectWorktreeInvalidInput(t *testing.T) {
 }
 
 func TestSimpleSelectWorktreeDisplayFormat(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/repo/main", Branch: "main", IsCurrent: true},
 		{Path: "/repo/feature", Branch: "feature-branch", IsCurrent: false},
 	}
@@ -214,7 +214,7 @@ func TestSimpleSelectWorktreeDisplayFormat(t *testing.T) {
 }
 
 func TestSimpleSelectWorktreeIOError(t *testing.T) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/path/1", Branch: "main", IsCurrent: false},
 	}
 
@@ -253,7 +253,7 @@ func (m *mockStringReader) Write(p []byte) (n int, err error) {
 
 func TestSimpleSelectWorktreeWrapper(t *testing.T) {
 	// Test the wrapper function SimpleSelectWorktree
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/test/path", Branch: "main", IsCurrent: false},
 	}
 
@@ -270,7 +270,7 @@ func TestSimpleSelectWorktreeWrapper(t *testing.T) {
 
 // Benchmark tests
 func BenchmarkSimpleSelectWorktreeDisplay(b *testing.B) {
-	worktrees := []git.Worktree{
+	worktrees := []worktree.Worktree{
 		{Path: "/repo/main", Branch: "main", IsCurrent: true},
 		{Path: "/repo/feature-1", Branch: "feature-1", IsCurrent: false},
 		{Path: "/repo/feature-2", Branch: "feature-2", IsCurrent: false},
@@ -5,11 +5,11 @@ import (
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/yagi2/yosegi/internal/git"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 // SmartSelectWorktree selects the best UI method based on TTY capabilities
-func SmartSelectWorktree(worktrees []git.Worktree) (*git.Worktree, error) {
+func SmartSelectWorktree(worktrees []worktree.Worktree) (*worktree.Worktree, error) {
 	if len(worktrees) == 0 {
 		return nil, fmt.Errorf("no worktrees found")
 	}
@@ -30,7 +30,7 @@ func SmartSelectWorktree(worktrees []git.Worktree) (*git.Worktree, error) {
 }
 
 // bubbleTeaSelector uses the full Bubble Tea TUI
-func bubbleTeaSelector(worktrees []git.Worktree) (*git.Worktree, error) {
+func bubbleTeaSelector(worktrees []worktree.Worktree) (*worktree.Worktree, error) {
 	model := NewSelector(worktrees, "Git Worktrees", "select", false)
 	program := tea.NewProgram(model)
 
@@ -47,7 +47,7 @@ func bubbleTeaSelector(worktrees []git.Worktree) (*git.Worktree, error) {
 }
 
 // keyboardSelector uses the custom keyboard interface
-func keyboardSelector(worktrees []git.Worktree) (*git.Worktree, error) {
+func keyboardSelector(worktrees []worktree.Worktree) (*worktree.Worktree, error) {
 	input, output, cleanup, err := GetTTYFiles(BasicTTYControl)
 	if err != nil {
 		// Fallback to simple selector if TTY setup fails
@@ -60,7 +60,7 @@ func keyboardSelector(worktrees []git.Worktree) (*git.Worktree, error) {
 }
 
 // fallbackSelector uses the simple numbered interface
-func fallbackSelector(worktrees []git.Worktree) (*git.Worktree, error) {
+func fallbackSelector(worktrees []worktree.Worktree) (*worktree.Worktree, error) {
 	// Return first non-current worktree automatically
 	for _, wt := range worktrees {
 		if !wt.IsCurrent {
@@ -3,12 +3,12 @@ package ui
 import (
 	"testing"
 
-	"github.com/yagi2/yosegi/internal/git"
+	"github.com/yagi2/yosegi/pkg/worktree"
 )
 
 func TestSmartSelectWorktreeEmptyList(t *testing.T) {
 	// Test with empty worktree list
-	var worktrees []git.Worktree
+	var worktrees []worktree.Worktree
 
 	result, err := SmartSelectWorktree(worktrees)
 
@@ -26,19 +26,19 @@ func TestSmartSelectWorktreeEmptyList(t *testing.T) {
 func TestFallbackSelector(t *testing.T) {
 	tests := []struct {
 		name      string
-		worktrees []git.Worktree
+		worktrees []worktree.Worktree
 		expectErr bool
 		expectIdx int
 	}{
 		{
 			name:      "Empty worktrees",
-			worktrees: []git.Worktree{},
+			worktrees: []worktree.Worktree{},
 			expectErr: true,
 			expectIdx: -1,
 		},
 		{
 			name: "Single non-current worktree",
-			worktrees: []git.Worktree{
+			worktrees: []worktree.Worktree{
 				{Path: "/path/1", Branch: "main", IsCurrent: false},
 			},
 			expectErr: false,
@@ -46,7 +46,7 @@ func TestFallbackSelector(t *testing.T) {
 		},
 		{
 			name: "Single current worktree",
-			worktrees: []git.Worktree{
+			worktrees: []worktree.Worktree{
 				{Path: "/path/1", Branch: "main", IsCurrent: true},
 			},
 			expectErr: false,
@@ -54,7 +54,7 @@ func TestFallbackSelector(t *testing.T) {
 		},
 		{
 			name: "Mixed worktrees - first non-current",
-			worktrees: []git.Worktree{
+			worktrees: []worktree.Worktree{
 				{Path: "/path/1", Branch: "main", IsCurrent: false},
 				{Path: "/path/2", Branch: "feature", IsCurrent: true},
 			},
@@ -63,7 +63,7 @@ func TestFallbackSelector(t *testing.T) {
 		},
 		{
 			name: "Mixed worktrees - current first",
-			worktrees: []git.Worktree{
+			worktrees: []worktree.Worktree{
 				{Path: "/path/1", Branch: "main", IsCurrent: true},
 				{Path: "/path/2", Branch: "feature", IsCurrent: false},
 			},
@@ -72,7 +72,7 @@ func TestFallbackSelector(t *testing.T) {
 		},
 		{
 			name: "All current worktrees",
-			worktrees: []git.Worktree{
+			worktrees: []worktree.Worktree{
 				{Path: "/path/1", Branch: "main", IsCurrent: true},
 				{Path: "/path/2", Branch: "feature", IsCurrent: true},
 			},
@@ -81,7 +81,7 @@ func TestFallbackSelector(t *testing.T) {
 		},
 		{
 			name: "Multiple non-current worktrees",
-			worktrees: []git.Worktree{
+			worktrees: []worktree.Worktree{
 				{Path: "/path/1", Branch: "main", IsCurrent: false},
 				{Path: "/path/2", Branch: "feature", IsCurrent: false},
 				{Path: "/path/3", Branch: "hotfix", IsCurrent: false},
@@ -128,7 +128,7 @@ func TestFallbackSelector(t *testing.T) {
 func TestFallbackSelectorLogic(t *testing.T) {
 	// Test specific logic cases
 	t.Run("PreferNonCurrent", func(t *testing.T) {
-		worktrees := []git.Worktree{
+		worktrees := []worktree.Worktree{
 			{Path: "/current", Branch: "main", IsCurrent: true},
 			{Path: "/non-current-1", Branch: "feature-1", IsCurrent: false},
 			{Path: "/non-current-2", Branch: "feature-2", IsCurrent: false},
@@ -150,7 +150,7 @@ func TestFallbackSelectorLogic(t *testing.T) {
 	})
 
 	t.Run("AllCurrentFallsBackToFirst", func(t *testing.T) {
-		worktrees := []git.Worktree{
+		worktrees := []worktree.Worktree{
 			{Path: "/first", Branch: "main", IsCurrent: true},
 			{Path: "/second", Branch: "feature", IsCurrent: true},
 		}
@@ -168,8 +168,8 @@ func TestFallbackSelectorLogic(t *testing.T) {
 }
 
 // Test helper function to create sample worktrees
-func createSampleWorktrees() []git.Worktree {
-	return []git.Worktree{
+func createSampleWorktrees() []worktree.Worktree {
+	return []worktree.Worktree{
 		{Path: "/repo/main", Branch: "main", IsCurrent: true},
 		{Path: "/repo/feature-1", Branch: "feature-1", IsCurrent: false},
 		{Path: "/repo/feature-2", Branch: "feature-2", IsCurrent: false},
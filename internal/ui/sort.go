@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+// SortOrder is an order of the worktree list
+type SortOrder string
+
+const (
+	SortDefault  SortOrder = ""         // git's order, which is creation order
+	SortFrecency SortOrder = "frecency" // most often and recently chosen first
+	SortBranch   SortOrder = "branch"   // by branch name
+	SortPath     SortOrder = "path"     // by worktree path
+	SortCommit   SortOrder = "commit"   // most recent last commit first
+)
+
+// sortOrders is the cycle the sort key steps through
+var sortOrders = []SortOrder{SortDefault, SortFrecency, SortBranch, SortPath, SortCommit}
+
+// ParseSortOrder parses a sort order from configuration. "git" and an empty
+// string both mean git's order.
+func ParseSortOrder(s string) (SortOrder, error) {
+	switch order := SortOrder(strings.ToLower(strings.TrimSpace(s))); order {
+	case "git":
+		return SortDefault, nil
+	case SortDefault, SortFrecency, SortBranch, SortPath, SortCommit:
+		return order, nil
+	}
+	return SortDefault, fmt.Errorf("unknown sort order '%s' (expected git, frecency, branch, path or commit)", s)
+}
+
+// label describes the order for the selector's title
+func (o SortOrder) label() string {
+	switch o {
+	case SortFrecency:
+		return "by frecency"
+	case SortBranch:
+		return "by branch"
+	case SortPath:
+		return "by path"
+	case SortCommit:
+		return "by last commit"
+	}
+	return "git order"
+}
+
+// next returns the order after o in the sort key's cycle
+func (o SortOrder) next() SortOrder {
+	for i, order := range sortOrders {
+		if order == o {
+			return sortOrders[(i+1)%len(sortOrders)]
+		}
+	}
+	return SortDefault
+}
+
+// SortOptions controls how the worktree list is ordered
+type SortOptions struct {
+	Order SortOrder
+	Group bool // group branches by prefix, such as "feature/"
+
+	// CommitTimes holds the date of each worktree's commit by hash, as
+	// returned by Manager.CommitTimes, for SortCommit
+	CommitTimes map[string]time.Time
+
+	// Frecency holds the score of each chosen worktree by path, for
+	// SortFrecency. Worktrees without a score keep git's order after them.
+	Frecency map[string]float64
+}
+
+// SortWorktrees returns a sorted copy of worktrees. The sort is stable, so
+// worktrees that compare equal keep git's order. When grouping, branches
+// without a prefix come first, then each prefix's group in name order.
+func SortWorktrees(worktrees []worktree.Worktree, opts SortOptions) []worktree.Worktree {
+	sorted := append([]worktree.Worktree(nil), worktrees...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if opts.Group {
+			if ga, gb := branchGroup(a.Branch), branchGroup(b.Branch); ga != gb {
+				return ga < gb
+			}
+		}
+
+		switch opts.Order {
+		case SortFrecency:
+			return opts.Frecency[a.Path] > opts.Frecency[b.Path]
+		case SortBranch:
+			return a.Branch < b.Branch
+		case SortPath:
+			return a.Path < b.Path
+		case SortCommit:
+			return opts.CommitTimes[a.Commit].After(opts.CommitTimes[b.Commit])
+		}
+		return false
+	})
+	return sorted
+}
+
+// branchGroup returns the prefix a branch is grouped under, such as
+// "feature/" for "feature/login", or "" for a branch without one
+func branchGroup(branch string) string {
+	if i := strings.Index(branch, "/"); i > 0 {
+		return branch[:i+1]
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+func TestParseSortOrder(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    SortOrder
+		expectError bool
+	}{
+		{input: "", expected: SortDefault},
+		{input: "git", expected: SortDefault},
+		{input: "branch", expected: SortBranch},
+		{input: " Path ", expected: SortPath},
+		{input: "commit", expected: SortCommit},
+		{input: "frecency", expected: SortFrecency},
+		{input: "size", expectError: true},
+	}
+
+	for _, tt := range tests {
+		order, err := ParseSortOrder(tt.input)
+		if (err != nil) != tt.expectError {
+			t.Errorf("ParseSortOrder(%q) error = %v, expectError %v", tt.input, err, tt.expectError)
+		}
+		if order != tt.expected {
+			t.Errorf("ParseSortOrder(%q) = %q, expected %q", tt.input, order, tt.expected)
+		}
+	}
+}
+
+func TestSortOrderNext(t *testing.T) {
+	order := SortDefault
+	var seen []string
+	for range sortOrders {
+		order = order.next()
+		seen = append(seen, order.label())
+	}
+	if got := strings.Join(seen, ", "); got != "by frecency, by branch, by path, by last commit, git order" {
+		t.Errorf("Unexpected cycle: %s", got)
+	}
+}
+
+func TestSortWorktrees(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	worktrees := []worktree.Worktree{
+		{Path: "/repo/main", Branch: "main", Commit: "aaa"},
+		{Path: "/repo/login", Branch: "feature/login", Commit: "bbb"},
+		{Path: "/repo/crash", Branch: "fix/crash", Commit: "ccc"},
+		{Path: "/repo/api", Branch: "feature/api", Commit: "ddd"},
+		{Path: "/repo/develop", Branch: "develop", Commit: "eee"},
+	}
+	times := map[string]time.Time{
+		"aaa": now.Add(-5 * time.Hour),
+		"bbb": now.Add(-1 * time.Hour),
+		"ccc": now.Add(-3 * time.Hour),
+		"ddd": now.Add(-2 * time.Hour),
+		"eee": now.Add(-4 * time.Hour),
+	}
+	scores := map[string]float64{"/repo/crash": 8, "/repo/develop": 0.5}
+
+	tests := []struct {
+		name     string
+		opts     SortOptions
+		expected string
+	}{
+		{name: "Git order", opts: SortOptions{}, expected: "main feature/login fix/crash feature/api develop"},
+		{name: "Branch", opts: SortOptions{Order: SortBranch}, expected: "develop feature/api feature/login fix/crash main"},
+		{name: "Path", opts: SortOptions{Order: SortPath}, expected: "feature/api fix/crash develop feature/login main"},
+		{name: "Commit", opts: SortOptions{Order: SortCommit, CommitTimes: times}, expected: "feature/login feature/api fix/crash develop main"},
+		{name: "Frecency", opts: SortOptions{Order: SortFrecency, Frecency: scores}, expected: "fix/crash develop main feature/login feature/api"},
+		{name: "Grouped git order", opts: SortOptions{Group: true}, expected: "main develop feature/login feature/api fix/crash"},
+		{name: "Grouped by branch", opts: SortOptions{Order: SortBranch, Group: true}, expected: "develop main feature/api feature/login fix/crash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var branches []string
+			for _, wt := range SortWorktrees(worktrees, tt.opts) {
+				branches = append(branches, wt.Branch)
+			}
+			if got := strings.Join(branches, " "); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if worktrees[0].Branch != "main" || worktrees[4].Branch != "develop" {
+		t.Error("Expected the input to be left in git's order")
+	}
+}
+
+func TestBranchGroup(t *testing.T) {
+	tests := map[string]string{
+		"main":                "",
+		"feature/login":       "feature/",
+		"release/1.0/hotfix":  "release/",
+		"(detached)":          "",
+		"/leading-slash-only": "",
+	}
+
+	for branch, expected := range tests {
+		if got := branchGroup(branch); got != expected {
+			t.Errorf("branchGroup(%q) = %q, expected %q", branch, got, expected)
+		}
+	}
+}
+
+func TestSelectorSorting(t *testing.T) {
+	worktrees := []worktree.Worktree{
+		{Path: "/repo/main", Branch: "main", IsCurrent: true},
+		{Path: "/repo/login", Branch: "feature/login"},
+		{Path: "/repo/crash", Branch: "fix/crash"},
+		{Path: "/repo/api", Branch: "feature/api"},
+	}
+
+	model := NewSelector(worktrees, "Test", "select", false).WithSorting(SortOptions{Order: SortBranch})
+	if model.worktrees[0].Branch != "feature/api" || !strings.Contains(model.View(), "by branch") {
+		t.Errorf("Expected the configured order:\n%s", model.View())
+	}
+
+	// The cursor stays on the highlighted worktree as the order changes
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	model = updated.(SelectorModel)
+	if model.sort.Order != SortPath || model.worktrees[model.cursor].Branch != "feature/login" {
+		t.Errorf("Expected path order with the cursor on feature/login, got %s at %d", model.sort.Order, model.cursor)
+	}
+	if !strings.Contains(model.View(), "by path") {
+		t.Errorf("Expected the order in the title:\n%s", model.View())
+	}
+
+	// Grouping adds a heading before each prefix
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	model = updated.(SelectorModel)
+	view := model.View()
+	if !strings.Contains(view, "grouped") || !hasLine(view, "feature/") || !hasLine(view, "fix/") {
+		t.Errorf("Expected group headings:\n%s", view)
+	}
+	if model.worktrees[0].Branch != "main" {
+		t.Errorf("Expected ungrouped branches first, got %+v", model.worktrees)
+	}
+
+	// Without sorting enabled, the keys do nothing
+	plain := NewSelector(worktrees, "Test", "select", false)
+	updated, _ = plain.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if updated.(SelectorModel).worktrees[1].Branch != "feature/login" || strings.Contains(plain.View(), "s sort") {
+		t.Error("Expected git's order and no sort help without sorting enabled")
+	}
+}
+
+func TestSelectorGroupedScrolling(t *testing.T) {
+	var worktrees []worktree.Worktree
+	for _, prefix := range []string{"feature", "fix"} {
+		for i := 0; i < 10; i++ {
+			name := prefix + "/" + string(rune('a'+i))
+			worktrees = append(worktrees, worktree.Worktree{Path: "/repo/" + name, Branch: name})
+		}
+	}
+
+	var model tea.Model = NewSelector(worktrees, "Test", "select", false).WithSorting(SortOptions{Group: true})
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 80, Height: 16})
+
+	// Moving onto the first worktree of a group brings its heading into view
+	for i := 0; i < 10; i++ {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	view := model.View()
+	if !hasLine(view, "fix/") || !strings.Contains(view, "11/20") {
+		t.Errorf("Expected the fix/ heading and the cursor's position:\n%s", view)
+	}
+}
+
+// hasLine reports whether a line of a bordered view holds just text
+func hasLine(view, text string) bool {
+	for _, line := range strings.Split(view, "\n") {
+		if strings.Trim(line, "│ ") == text {
+			return true
+		}
+	}
+	return false
+}
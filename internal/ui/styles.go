@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/yagi2/yosegi/internal/config"
 )
@@ -95,6 +96,11 @@ var (
 			BorderForeground(Primary).
 			Padding(1, 2)
 
+	PreviewStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(Muted).
+			Padding(0, 1)
+
 	InputStyle = lipgloss.NewStyle().
 			Foreground(Text).
 			Background(lipgloss.Color("#374151")).
@@ -102,6 +108,19 @@ var (
 			Margin(0, 1)
 )
 
+// newHelp returns a help view styled with the theme
+func newHelp() help.Model {
+	h := help.New()
+	h.Styles.ShortKey = lipgloss.NewStyle().Foreground(Secondary)
+	h.Styles.ShortDesc = lipgloss.NewStyle().Foreground(Muted)
+	h.Styles.ShortSeparator = lipgloss.NewStyle().Foreground(Muted)
+	h.Styles.Ellipsis = lipgloss.NewStyle().Foreground(Muted)
+	h.Styles.FullKey = h.Styles.ShortKey
+	h.Styles.FullDesc = h.Styles.ShortDesc
+	h.Styles.FullSeparator = h.Styles.ShortSeparator
+	return h
+}
+
 // GetStatusIcon returns an icon based on status
 func GetStatusIcon(isCurrent bool) string {
 	if isCurrent {
@@ -110,6 +129,17 @@ func GetStatusIcon(isCurrent bool) string {
 	return "○"
 }
 
+// GetLockIcon returns the badge shown next to locked worktrees
+func GetLockIcon() string {
+	return "🔒"
+}
+
+// GetSessionIcon returns the badge shown next to worktrees with a running
+// multiplexer session
+func GetSessionIcon() string {
+	return "💻"
+}
+
 // GetBranchIcon returns an icon for branch
 func GetBranchIcon() string {
 	return ""
@@ -228,6 +228,22 @@ func TestGetPathIcon(t *testing.T) {
 	}
 }
 
+func TestGetLockIcon(t *testing.T) {
+	icon := GetLockIcon()
+	expected := "🔒"
+	if icon != expected {
+		t.Errorf("Expected lock icon %s, got %s", expected, icon)
+	}
+}
+
+func TestGetSessionIcon(t *testing.T) {
+	icon := GetSessionIcon()
+	expected := "💻"
+	if icon != expected {
+		t.Errorf("Expected session icon %s, got %s", expected, icon)
+	}
+}
+
 func TestStylesCreation(t *testing.T) {
 	// Test that all style variables are properly initialized
 	styles := []struct {
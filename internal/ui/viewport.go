@@ -0,0 +1,83 @@
+package ui
+
+import "fmt"
+
+// scrollOffset returns the first row to show so that cursor stays within a
+// window of rows lines, moving offset as little as possible. A window of zero
+// rows shows the whole list.
+func scrollOffset(cursor, offset, rows, total int) int {
+	if rows <= 0 || total <= rows {
+		return 0
+	}
+	if cursor < offset {
+		offset = cursor
+	}
+	if cursor >= offset+rows {
+		offset = cursor - rows + 1
+	}
+	return max(min(offset, total-rows), 0)
+}
+
+// pageSize is how far paging moves the cursor in a window of rows lines,
+// keeping one row of context
+func pageSize(rows, total int) int {
+	if rows <= 0 {
+		rows = total
+	}
+	return max(rows-1, 1)
+}
+
+// moveCursor returns the cursor position after a navigation key: "up",
+// "down", "pgup", "pgdown", "home" or "end". Other keys leave it unchanged.
+func moveCursor(key string, cursor, rows, total int) int {
+	if total == 0 {
+		return 0
+	}
+	switch key {
+	case "up":
+		cursor--
+	case "down":
+		cursor++
+	case "pgup":
+		cursor -= pageSize(rows, total)
+	case "pgdown":
+		cursor += pageSize(rows, total)
+	case "home":
+		cursor = 0
+	case "end":
+		cursor = total - 1
+	}
+	return max(min(cursor, total-1), 0)
+}
+
+// positionIndicator shows the cursor's position among total entries, e.g.
+// "▲ 12/42 ▼", with arrows for the parts of the list scrolled out of view
+// above and below. It is empty when the whole list is shown.
+func positionIndicator(cursor, total int, above, below bool) string {
+	if !above && !below {
+		return ""
+	}
+
+	indicator := fmt.Sprintf("%d/%d", cursor+1, total)
+	if above {
+		indicator = "▲ " + indicator
+	} else {
+		indicator = "  " + indicator
+	}
+	if below {
+		indicator += " ▼"
+	}
+	return indicator
+}
+
+// listColumns splits width between a branch column, sized to the widest
+// branch but at most half of width, and a path column taking what is left
+// after overhead, the width of everything else on a line. Without a width,
+// the branch column fits its content and the path width is zero.
+func listColumns(branchWidth, width, overhead int) (int, int) {
+	if width <= 0 {
+		return branchWidth, 0
+	}
+	branchWidth = min(branchWidth, max(width/2, 10))
+	return branchWidth, max(width-overhead-branchWidth, 10)
+}
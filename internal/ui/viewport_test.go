@@ -0,0 +1,94 @@
+package ui
+
+import "testing"
+
+func TestScrollOffset(t *testing.T) {
+	tests := []struct {
+		name                        string
+		cursor, offset, rows, total int
+		expected                    int
+	}{
+		{name: "Everything fits", cursor: 5, offset: 3, rows: 10, total: 8, expected: 0},
+		{name: "No limit", cursor: 30, offset: 0, rows: 0, total: 40, expected: 0},
+		{name: "Cursor in view", cursor: 12, offset: 10, rows: 5, total: 40, expected: 10},
+		{name: "Cursor below view", cursor: 20, offset: 10, rows: 5, total: 40, expected: 16},
+		{name: "Cursor above view", cursor: 3, offset: 10, rows: 5, total: 40, expected: 3},
+		{name: "Shrunk window keeps the end filled", cursor: 39, offset: 38, rows: 5, total: 40, expected: 35},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scrollOffset(tt.cursor, tt.offset, tt.rows, tt.total); got != tt.expected {
+				t.Errorf("scrollOffset() = %d, expected %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMoveCursor(t *testing.T) {
+	tests := []struct {
+		key                 string
+		cursor, rows, total int
+		expected            int
+	}{
+		{key: "up", cursor: 0, rows: 10, total: 40, expected: 0},
+		{key: "down", cursor: 0, rows: 10, total: 40, expected: 1},
+		{key: "down", cursor: 39, rows: 10, total: 40, expected: 39},
+		{key: "pgdown", cursor: 0, rows: 10, total: 40, expected: 9},
+		{key: "pgdown", cursor: 35, rows: 10, total: 40, expected: 39},
+		{key: "pgup", cursor: 20, rows: 10, total: 40, expected: 11},
+		{key: "pgup", cursor: 3, rows: 10, total: 40, expected: 0},
+		{key: "pgdown", cursor: 0, rows: 0, total: 5, expected: 4},
+		{key: "home", cursor: 20, rows: 10, total: 40, expected: 0},
+		{key: "end", cursor: 0, rows: 10, total: 40, expected: 39},
+		{key: "x", cursor: 7, rows: 10, total: 40, expected: 7},
+		{key: "down", cursor: 0, rows: 10, total: 0, expected: 0},
+	}
+
+	for _, tt := range tests {
+		if got := moveCursor(tt.key, tt.cursor, tt.rows, tt.total); got != tt.expected {
+			t.Errorf("moveCursor(%q, %d) = %d, expected %d", tt.key, tt.cursor, got, tt.expected)
+		}
+	}
+}
+
+func TestPositionIndicator(t *testing.T) {
+	tests := []struct {
+		cursor, total int
+		above, below  bool
+		expected      string
+	}{
+		{cursor: 0, total: 5, expected: ""},
+		{cursor: 0, total: 40, below: true, expected: "  1/40 ▼"},
+		{cursor: 15, total: 40, above: true, below: true, expected: "▲ 16/40 ▼"},
+		{cursor: 39, total: 40, above: true, expected: "▲ 40/40"},
+	}
+
+	for _, tt := range tests {
+		if got := positionIndicator(tt.cursor, tt.total, tt.above, tt.below); got != tt.expected {
+			t.Errorf("positionIndicator(%d, %d, %v, %v) = %q, expected %q", tt.cursor, tt.total, tt.above, tt.below, got, tt.expected)
+		}
+	}
+}
+
+func TestListColumns(t *testing.T) {
+	tests := []struct {
+		name                     string
+		branchWidth, width       int
+		expectBranch, expectPath int
+	}{
+		{name: "Unknown width", branchWidth: 25, width: 0, expectBranch: 25, expectPath: 0},
+		{name: "Narrow branches", branchWidth: 8, width: 80, expectBranch: 8, expectPath: 68},
+		{name: "Long branches capped at half", branchWidth: 60, width: 80, expectBranch: 40, expectPath: 36},
+		{name: "Tiny terminal", branchWidth: 30, width: 12, expectBranch: 10, expectPath: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			branch, path := listColumns(tt.branchWidth, tt.width, 4)
+			if branch != tt.expectBranch || path != tt.expectPath {
+				t.Errorf("listColumns() = %d, %d, expected %d, %d", branch, path, tt.expectBranch, tt.expectPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package worktree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// bareDirName is the directory holding the repository in the bare layout
+const bareDirName = ".bare"
+
+// CloneResult describes a repository set up by CloneBare
+type CloneResult struct {
+	Repository   Repository
+	Branch       string // default branch of the remote
+	WorktreePath string // initial worktree, empty when the remote has no commits
+}
+
+// CloneBare clones url into dir using the "bare clone + worktrees" layout:
+// the repository lives in dir/.bare, dir/.git points at it so git commands
+// work from dir, and the default branch is checked out in dir/<branch>.
+// Unlike a plain 'git clone --bare', remote-tracking branches are fetched and
+// the local branches track them, so that upstreams and 'git fetch' behave as
+// in a regular clone.
+// dir is removed again if any step fails.
+func CloneBare(ctx context.Context, url, dir string) (*CloneResult, error) {
+	// Validate inputs for security
+	if err := validateCloneURL(url); err != nil {
+		return nil, fmt.Errorf("invalid repository URL: %w", err)
+	}
+	if err := validatePath(dir); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if entries, err := os.ReadDir(absDir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("destination '%s' already exists and is not empty", absDir)
+	}
+
+	result, err := cloneBare(ctx, url, absDir)
+	if err != nil {
+		_ = os.RemoveAll(absDir) // Leave nothing half-initialised behind
+		return nil, err
+	}
+	return result, nil
+}
+
+// cloneBare performs the clone steps for CloneBare
+func cloneBare(ctx context.Context, url, dir string) (*CloneResult, error) {
+	bareDir := filepath.Join(dir, bareDirName)
+
+	if _, err := gitOutput(ctx, "", nil, "clone", "--bare", "--quiet", "--", url, bareDir); err != nil {
+		return nil, fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".git"), []byte("gitdir: ./"+bareDirName+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write .git file: %w", err)
+	}
+
+	// A bare clone maps remote branches straight onto local ones and has no
+	// fetch refspec, so restore the one a regular clone would have
+	if _, err := gitOutput(ctx, bareDir, nil, "config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+		return nil, fmt.Errorf("failed to configure fetch refspec: %w", err)
+	}
+	if _, err := gitOutput(ctx, bareDir, nil, "fetch", "--quiet", "origin"); err != nil {
+		return nil, fmt.Errorf("failed to fetch from origin: %w", err)
+	}
+
+	// The local branches the bare clone made track nothing; give each its
+	// remote branch as upstream, so that sync and pull work in worktrees on
+	// them and a branch deleted on the remote shows as gone
+	heads, err := gitOutput(ctx, bareDir, nil, "for-each-ref", "--format=%(refname:short)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	for _, branch := range strings.Fields(heads) {
+		if _, err := gitOutput(ctx, bareDir, nil, "branch", "--set-upstream-to=origin/"+branch, branch); err != nil {
+			return nil, fmt.Errorf("failed to set upstream of '%s': %w", branch, err)
+		}
+	}
+
+	result := &CloneResult{
+		Repository: Repository{Root: bareDir, CommonDir: bareDir, Bare: true},
+	}
+
+	// HEAD of a bare clone names the remote's default branch
+	output, err := gitOutput(ctx, bareDir, nil, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine default branch: %w", err)
+	}
+	result.Branch = strings.TrimSpace(output)
+
+	m := &manager{repoRoot: bareDir, commonDir: bareDir}
+	if !m.branchExists(ctx, result.Branch) {
+		return result, nil // Empty repository, nothing to check out yet
+	}
+
+	worktreePath := filepath.Join(dir, strings.ReplaceAll(result.Branch, "/", "-"))
+	if err := m.Create(ctx, CreateOptions{Path: worktreePath, Branch: result.Branch}); err != nil {
+		return nil, err
+	}
+	result.WorktreePath = worktreePath
+
+	return result, nil
+}
+
+// validateCloneURL ensures the repository URL is safe to pass to 'git clone'
+func validateCloneURL(url string) error {
+	if url == "" {
+		return errors.New("URL cannot be empty")
+	}
+	if strings.HasPrefix(url, "-") {
+		return errors.New("URL cannot start with a dash")
+	}
+	for _, char := range dangerousShellChars {
+		if strings.Contains(url, char) {
+			return fmt.Errorf("URL contains dangerous character: %s", char)
+		}
+	}
+	return nil
+}
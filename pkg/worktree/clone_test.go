@@ -0,0 +1,119 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCloneBare(t *testing.T) {
+	origin := initTestRepo(t)
+	runTestGit(t, origin, "branch", "feature")
+	dir := filepath.Join(filepath.Dir(origin), "clone")
+
+	result, err := CloneBare(context.Background(), origin, dir)
+	if err != nil {
+		t.Fatalf("CloneBare failed: %v", err)
+	}
+
+	bareDir := filepath.Join(dir, ".bare")
+	if result.Repository.Root != bareDir || !result.Repository.Bare {
+		t.Errorf("Expected bare repository at %s, got %+v", bareDir, result.Repository)
+	}
+	if result.Branch != "main" {
+		t.Errorf("Expected default branch main, got %s", result.Branch)
+	}
+	if result.WorktreePath != filepath.Join(dir, "main") {
+		t.Errorf("Expected worktree at %s, got %s", filepath.Join(dir, "main"), result.WorktreePath)
+	}
+	if _, err := os.Stat(filepath.Join(result.WorktreePath, "README.md")); err != nil {
+		t.Errorf("Expected checked out file in worktree: %v", err)
+	}
+
+	refspec := strings.TrimSpace(runTestGit(t, bareDir, "config", "remote.origin.fetch"))
+	if refspec != "+refs/heads/*:refs/remotes/origin/*" {
+		t.Errorf("Unexpected fetch refspec %q", refspec)
+	}
+	upstream := strings.TrimSpace(runTestGit(t, result.WorktreePath, "rev-parse", "--abbrev-ref", "@{upstream}"))
+	if upstream != "origin/main" {
+		t.Errorf("Expected upstream origin/main, got %s", upstream)
+	}
+
+	// Other branches the clone made track their remote branch too
+	if upstream := strings.TrimSpace(runTestGit(t, bareDir, "rev-parse", "--abbrev-ref", "feature@{upstream}")); upstream != "origin/feature" {
+		t.Errorf("Expected feature to track origin/feature, got %s", upstream)
+	}
+
+	// The container directory resolves to the bare repository
+	repo, err := DiscoverRepository(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("DiscoverRepository failed: %v", err)
+	}
+	if repo.Root != bareDir || !repo.Bare {
+		t.Errorf("Expected bare repository at %s, got %+v", bareDir, repo)
+	}
+
+	m := &manager{repoRoot: bareDir, commonDir: bareDir}
+	worktrees, err := m.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(worktrees) != 2 || !worktrees[0].Bare {
+		t.Fatalf("Expected bare entry followed by one worktree, got %+v", worktrees)
+	}
+	if visible := WithoutBare(worktrees); len(visible) != 1 || visible[0].Path != result.WorktreePath {
+		t.Errorf("Expected only %s to be visible, got %+v", result.WorktreePath, visible)
+	}
+
+	if err := m.Remove(context.Background(), bareDir, RemoveOptions{Force: true}); err == nil || !strings.Contains(err.Error(), "bare repository") {
+		t.Errorf("Expected bare repository removal to be refused, got %v", err)
+	}
+}
+
+func TestCloneBareErrors(t *testing.T) {
+	origin := initTestRepo(t)
+	parent := filepath.Dir(origin)
+
+	// A non-empty destination is refused and left untouched
+	if _, err := CloneBare(context.Background(), origin, origin); err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Errorf("Expected non-empty destination error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(origin, "README.md")); err != nil {
+		t.Errorf("Existing destination should be left untouched: %v", err)
+	}
+
+	// A failed clone leaves nothing behind
+	dir := filepath.Join(parent, "missing-clone")
+	if _, err := CloneBare(context.Background(), filepath.Join(parent, "does-not-exist"), dir); err == nil {
+		t.Error("Expected error when cloning a missing repository")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be cleaned up, got %v", dir, err)
+	}
+}
+
+func TestValidateCloneURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "HTTPS URL", url: "https://github.com/user/repo.git", wantErr: false},
+		{name: "SCP-like URL", url: "git@github.com:user/repo.git", wantErr: false},
+		{name: "Local path", url: "/srv/git/repo", wantErr: false},
+		{name: "Empty URL", url: "", wantErr: true},
+		{name: "Option injection", url: "--upload-pack=evil", wantErr: true},
+		{name: "Shell metacharacters", url: "https://example.com/repo;rm", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCloneURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCloneURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
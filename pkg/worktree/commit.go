@@ -0,0 +1,118 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DiffStat counts the changes between a worktree and a base commit
+type DiffStat struct {
+	Files      int
+	Insertions int
+	Deletions  int
+}
+
+// shortstatPart matches one count of 'git diff --shortstat', e.g. "3 insertions(+)"
+var shortstatPart = regexp.MustCompile(`(\d+) (files? changed|insertions?\(\+\)|deletions?\(-\))`)
+
+// DiffStat counts the files and lines of tracked files in the worktree at
+// path that differ from base
+func (m *manager) DiffStat(ctx context.Context, path, base string) (*DiffStat, error) {
+	// Validate inputs for security
+	if err := validatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	if err := validateBranchName(base); err != nil {
+		return nil, fmt.Errorf("invalid base: %w", err)
+	}
+
+	output, err := m.gitOutput(ctx, path, nil, "diff", "--shortstat", base, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff worktree: %w", err)
+	}
+	return parseShortstat(output), nil
+}
+
+// parseShortstat parses the summary line printed by 'git diff --shortstat'
+func parseShortstat(output string) *DiffStat {
+	stat := &DiffStat{}
+	for _, match := range shortstatPart.FindAllStringSubmatch(output, -1) {
+		count, _ := strconv.Atoi(match[1])
+		switch {
+		case strings.HasPrefix(match[2], "file"):
+			stat.Files = count
+		case strings.HasPrefix(match[2], "insertion"):
+			stat.Insertions = count
+		case strings.HasPrefix(match[2], "deletion"):
+			stat.Deletions = count
+		}
+	}
+	return stat
+}
+
+// CommitAll commits every change in the worktree at path, including untracked
+// files, and reports whether there was anything to commit
+func (m *manager) CommitAll(ctx context.Context, path, message string) (bool, error) {
+	// Validate input for security
+	if err := validatePath(path); err != nil {
+		return false, fmt.Errorf("invalid path: %w", err)
+	}
+
+	status, err := m.gitOutput(ctx, path, nil, "status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("failed to get worktree status: %w", err)
+	}
+	if strings.TrimSpace(status) == "" {
+		return false, nil
+	}
+
+	if _, err := m.gitOutput(ctx, path, nil, "add", "--all"); err != nil {
+		return false, fmt.Errorf("failed to stage changes: %w", err)
+	}
+	if _, err := m.gitOutput(ctx, path, nil, "commit", "--quiet", "-m", message); err != nil {
+		return false, fmt.Errorf("failed to commit changes: %w", err)
+	}
+	return true, nil
+}
+
+// Merge merges branch into the branch checked out in the worktree at path.
+// A merge that conflicts is aborted so the worktree is left as it was.
+func (m *manager) Merge(ctx context.Context, path, branch string) error {
+	// Validate inputs for security
+	if err := validatePath(path); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	if err := validateBranchName(branch); err != nil {
+		return fmt.Errorf("invalid branch name: %w", err)
+	}
+
+	// git reports conflicts on stdout
+	output, err := m.gitOutput(ctx, path, nil, "merge", "--no-edit", branch)
+	if err != nil {
+		if strings.Contains(output, "CONFLICT") || strings.Contains(output, "Automatic merge failed") {
+			if _, abortErr := m.gitOutput(ctx, path, nil, "merge", "--abort"); abortErr != nil {
+				return fmt.Errorf("%w, and aborting the merge failed: %v", &BranchError{Branch: branch, Err: ErrMergeConflict}, abortErr)
+			}
+			return &BranchError{Branch: branch, Err: ErrMergeConflict}
+		}
+		return fmt.Errorf("failed to merge '%s': %w", branch, err)
+	}
+	return nil
+}
+
+// ResolveCommit returns the full hash of the commit ref points to
+func (m *manager) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	// Validate input for security
+	if err := validateBranchName(ref); err != nil {
+		return "", fmt.Errorf("invalid ref: %w", err)
+	}
+
+	output, err := m.gitOutput(ctx, m.repoRoot, nil, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("'%s' is not a commit", ref)
+	}
+	return strings.TrimSpace(output), nil
+}
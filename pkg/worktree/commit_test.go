@@ -0,0 +1,109 @@
+package worktree
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseShortstat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected DiffStat
+	}{
+		{input: "", expected: DiffStat{}},
+		{input: " 3 files changed, 10 insertions(+), 2 deletions(-)\n", expected: DiffStat{Files: 3, Insertions: 10, Deletions: 2}},
+		{input: " 1 file changed, 1 insertion(+)\n", expected: DiffStat{Files: 1, Insertions: 1}},
+		{input: " 1 file changed, 4 deletions(-)\n", expected: DiffStat{Files: 1, Deletions: 4}},
+	}
+
+	for _, tt := range tests {
+		if got := parseShortstat(tt.input); *got != tt.expected {
+			t.Errorf("parseShortstat(%q) = %+v, want %+v", tt.input, *got, tt.expected)
+		}
+	}
+}
+
+func TestManagerCommitAndMerge(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+	ctx := context.Background()
+
+	base, err := m.ResolveCommit(ctx, "main")
+	if err != nil || len(base) != 40 {
+		t.Fatalf("ResolveCommit failed: %q, %v", base, err)
+	}
+	if _, err := m.ResolveCommit(ctx, "missing"); err == nil {
+		t.Error("Expected unknown ref to fail")
+	}
+
+	wtPath := filepath.Join(filepath.Dir(repoDir), "task")
+	if err := m.Create(ctx, CreateOptions{Path: wtPath, Branch: "task", CreateBranch: true, Base: base}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if committed, err := m.CommitAll(ctx, wtPath, "nothing"); err != nil || committed {
+		t.Errorf("Expected nothing to commit, got %v, %v", committed, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(wtPath, "README.md"), []byte("hello\nworld\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(wtPath, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	committed, err := m.CommitAll(ctx, wtPath, "task result")
+	if err != nil || !committed {
+		t.Fatalf("Expected changes to be committed, got %v, %v", committed, err)
+	}
+	if subject := runTestGit(t, wtPath, "log", "-1", "--format=%s"); subject != "task result" {
+		t.Errorf("Expected commit 'task result', got %q", subject)
+	}
+
+	stat, err := m.DiffStat(ctx, wtPath, base)
+	if err != nil {
+		t.Fatalf("DiffStat failed: %v", err)
+	}
+	if *stat != (DiffStat{Files: 2, Insertions: 2}) {
+		t.Errorf("Unexpected diff stat: %+v", *stat)
+	}
+
+	if err := m.Merge(ctx, repoDir, "task"); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "new.txt")); err != nil {
+		t.Errorf("Expected merged file in main worktree: %v", err)
+	}
+}
+
+func TestManagerMergeConflict(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+	ctx := context.Background()
+
+	wtPath := filepath.Join(filepath.Dir(repoDir), "task")
+	if err := m.Create(ctx, CreateOptions{Path: wtPath, Branch: "task", CreateBranch: true}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Change the same line on both branches
+	for dir, content := range map[string]string{wtPath: "task\n", repoDir: "main\n"} {
+		if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to modify file: %v", err)
+		}
+		if _, err := m.CommitAll(ctx, dir, "change"); err != nil {
+			t.Fatalf("CommitAll failed: %v", err)
+		}
+	}
+
+	err := m.Merge(ctx, repoDir, "task")
+	var branchErr *BranchError
+	if !errors.Is(err, ErrMergeConflict) || !errors.As(err, &branchErr) || branchErr.Branch != "task" {
+		t.Fatalf("Expected ErrMergeConflict for task, got %v", err)
+	}
+	if status := runTestGit(t, repoDir, "status", "--porcelain"); status != "" {
+		t.Errorf("Expected conflicting merge to be aborted, got status %q", status)
+	}
+}
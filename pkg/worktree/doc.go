@@ -0,0 +1,30 @@
+// Package worktree manages git worktrees. It is the library the yosegi command
+// is built on, and can be used by other tools that need to list, create,
+// remove or move worktrees without parsing git's porcelain output themselves.
+//
+//	manager, err := worktree.NewManagerAt(ctx, "/path/to/repo")
+//	if err != nil {
+//		return err
+//	}
+//	err = manager.Create(ctx, worktree.CreateOptions{Path: "../feature", Branch: "feature", CreateBranch: true})
+//	if errors.Is(err, worktree.ErrBranchExists) {
+//		// check out the existing branch instead
+//	}
+//
+// Every git command runs through a Runner. The worktreetest package scripts
+// git's responses so code using a Manager can be tested without a repository.
+//
+// # Compatibility
+//
+// The package follows semantic versioning together with the yosegi module:
+// within a major version, exported identifiers are not removed and their
+// signatures and documented behaviour do not change incompatibly. Within that
+// promise:
+//
+//   - Methods may be added to Manager. Obtain managers from NewManager,
+//     NewManagerAt or NewManagerWithRunner rather than implementing the interface.
+//   - Fields may be added to structs such as Worktree, Status and the option
+//     types. Use keyed composite literals.
+//   - Error message text may change. Test for conditions with errors.Is and
+//     errors.As against the sentinel errors, WorktreeError and BranchError.
+package worktree
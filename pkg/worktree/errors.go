@@ -0,0 +1,64 @@
+package worktree
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Conditions reported by Manager methods. Use errors.Is to test for them, and
+// errors.As with *WorktreeError or *BranchError to find the affected worktree or branch.
+var (
+	ErrDirtyWorktree   = errors.New("worktree contains uncommitted changes")
+	ErrLocked          = errors.New("worktree is locked")
+	ErrMissing         = errors.New("worktree is missing")
+	ErrBranchNotMerged = errors.New("branch is not fully merged")
+	ErrBranchNotFound  = errors.New("branch not found")
+	ErrBranchExists    = errors.New("branch already exists")
+	ErrMergeConflict   = errors.New("branch does not merge cleanly")
+)
+
+// WorktreeError describes a failed operation on the worktree at Path
+type WorktreeError struct {
+	Path   string
+	Err    error  // one of the sentinel errors above
+	Detail string // extra context such as the lock reason
+}
+
+func (e *WorktreeError) Error() string {
+	// "worktree is locked" becomes "worktree at '/path' is locked"
+	msg := fmt.Sprintf("worktree at '%s' %s", e.Path, strings.TrimPrefix(e.Err.Error(), "worktree "))
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	return msg
+}
+
+func (e *WorktreeError) Unwrap() error {
+	return e.Err
+}
+
+// BranchError describes a failed operation on Branch
+type BranchError struct {
+	Branch string
+	Err    error // one of the sentinel errors above
+}
+
+func (e *BranchError) Error() string {
+	// "branch not found" becomes "branch 'name' not found"
+	return fmt.Sprintf("branch '%s' %s", e.Branch, strings.TrimPrefix(e.Err.Error(), "branch "))
+}
+
+func (e *BranchError) Unwrap() error {
+	return e.Err
+}
+
+// gitMessage reduces git's output to its first line, without the
+// "fatal:"/"error:" prefix, for use in error messages
+func gitMessage(output string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	for _, prefix := range []string{"fatal: ", "error: "} {
+		line = strings.TrimPrefix(line, prefix)
+	}
+	return strings.TrimSpace(line)
+}
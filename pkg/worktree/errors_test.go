@@ -0,0 +1,142 @@
+package worktree
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTypedErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+		expected string
+	}{
+		{
+			name:     "Dirty worktree",
+			err:      &WorktreeError{Path: "/tmp/wt", Err: ErrDirtyWorktree},
+			sentinel: ErrDirtyWorktree,
+			expected: "worktree at '/tmp/wt' contains uncommitted changes",
+		},
+		{
+			name:     "Locked worktree with reason",
+			err:      &WorktreeError{Path: "/tmp/wt", Err: ErrLocked, Detail: "on usb drive"},
+			sentinel: ErrLocked,
+			expected: "worktree at '/tmp/wt' is locked (on usb drive)",
+		},
+		{
+			name:     "Branch not merged",
+			err:      &BranchError{Branch: "feature", Err: ErrBranchNotMerged},
+			sentinel: ErrBranchNotMerged,
+			expected: "branch 'feature' is not fully merged",
+		},
+		{
+			name:     "Branch not found",
+			err:      &BranchError{Branch: "feature", Err: ErrBranchNotFound},
+			sentinel: ErrBranchNotFound,
+			expected: "branch 'feature' not found",
+		},
+		{
+			name:     "Branch exists",
+			err:      &BranchError{Branch: "feature", Err: ErrBranchExists},
+			sentinel: ErrBranchExists,
+			expected: "branch 'feature' already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Error() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, tt.err.Error())
+			}
+
+			wrapped := errors.Join(errors.New("context"), tt.err)
+			if !errors.Is(wrapped, tt.sentinel) {
+				t.Errorf("Expected errors.Is to find %v", tt.sentinel)
+			}
+		})
+	}
+}
+
+func TestGitMessage(t *testing.T) {
+	tests := map[string]string{
+		"fatal: cannot move a locked working tree, lock reason: busy\nuse 'move -f -f' to override": "cannot move a locked working tree, lock reason: busy",
+		"error: branch 'x' not found.\n": "branch 'x' not found.",
+		"":                               "",
+	}
+
+	for input, expected := range tests {
+		if got := gitMessage(input); got != expected {
+			t.Errorf("gitMessage(%q) = %q, want %q", input, got, expected)
+		}
+	}
+
+	if reason := lockReasonFromOutput("fatal: cannot move a locked working tree, lock reason: busy\n"); reason != "busy" {
+		t.Errorf("Expected lock reason 'busy', got %q", reason)
+	}
+}
+
+func TestManagerTypedErrors(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+	ctx := context.Background()
+
+	wtPath := filepath.Join(filepath.Dir(repoDir), "feature")
+	if err := m.Create(ctx, CreateOptions{Path: wtPath, Branch: "feature", CreateBranch: true}); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+
+	if err := m.Create(ctx, CreateOptions{Path: filepath.Join(filepath.Dir(repoDir), "other"), Branch: "feature", CreateBranch: true}); !errors.Is(err, ErrBranchExists) {
+		t.Errorf("Expected ErrBranchExists, got %v", err)
+	}
+
+	// Uncommitted changes block a normal removal
+	if err := os.WriteFile(filepath.Join(wtPath, "notes.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	err := m.Remove(ctx, wtPath, RemoveOptions{})
+	var wtErr *WorktreeError
+	if !errors.Is(err, ErrDirtyWorktree) || !errors.As(err, &wtErr) || wtErr.Path != wtPath {
+		t.Errorf("Expected ErrDirtyWorktree for %s, got %v", wtPath, err)
+	}
+
+	// Locked worktrees report their lock reason
+	if err := m.Lock(ctx, wtPath, "in use"); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	err = m.Remove(ctx, wtPath, RemoveOptions{Force: true})
+	if !errors.Is(err, ErrLocked) || !errors.As(err, &wtErr) || wtErr.Detail != "in use" {
+		t.Errorf("Expected ErrLocked with reason, got %v", err)
+	}
+	if err := m.Move(ctx, wtPath, filepath.Join(filepath.Dir(repoDir), "moved"), MoveOptions{}); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked from Move, got %v", err)
+	}
+	if err := m.Lock(ctx, wtPath, ""); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked when locking twice, got %v", err)
+	}
+	if err := m.Unlock(ctx, wtPath); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+
+	// A branch with commits that are not merged anywhere needs force to delete
+	runTestGit(t, wtPath, "add", "notes.txt")
+	runTestGit(t, wtPath, "commit", "-q", "-m", "wip")
+	if err := m.Remove(ctx, wtPath, RemoveOptions{}); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	err = m.DeleteBranch(ctx, "feature", false)
+	var branchErr *BranchError
+	if !errors.Is(err, ErrBranchNotMerged) || !errors.As(err, &branchErr) || branchErr.Branch != "feature" {
+		t.Errorf("Expected ErrBranchNotMerged for feature, got %v", err)
+	}
+	if err := m.DeleteBranch(ctx, "missing", false); !errors.Is(err, ErrBranchNotFound) {
+		t.Errorf("Expected ErrBranchNotFound, got %v", err)
+	}
+	if err := m.RenameBranch(ctx, "feature", "main"); !errors.Is(err, ErrBranchExists) {
+		t.Errorf("Expected ErrBranchExists from RenameBranch, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PruneEntry describes a stale worktree entry found by 'git worktree prune'
+type PruneEntry struct {
+	Name   string // administrative entry under .git/worktrees
+	Reason string
+}
+
+// RepairEntry describes an inconsistency handled by 'git worktree repair'
+type RepairEntry struct {
+	Path   string
+	Reason string
+	Failed bool // true when git could not repair the entry
+}
+
+// WorktreeProblem describes a worktree whose files on disk are inconsistent
+type WorktreeProblem struct {
+	Worktree Worktree
+	Problem  string
+}
+
+// Prune removes stale worktree entries, or only reports them when dryRun is set.
+// Locked worktrees are never pruned.
+func (m *manager) Prune(ctx context.Context, dryRun bool) ([]PruneEntry, error) {
+	args := []string{"worktree", "prune", "-v"}
+	if dryRun {
+		args = append(args, "-n")
+	}
+
+	output, err := m.gitCombined(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune worktrees: %s", output)
+	}
+
+	return parsePruneOutput(output), nil
+}
+
+// Repair fixes worktree administrative files, for example after a worktree was
+// moved by hand. paths lists the new locations of moved worktrees.
+func (m *manager) Repair(ctx context.Context, paths []string) ([]RepairEntry, error) {
+	// Validate inputs for security
+	for _, path := range paths {
+		if err := validatePath(path); err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+	}
+
+	args := append([]string{"worktree", "repair"}, paths...)
+	// git exits non-zero when any single entry could not be repaired, so the
+	// parsed report is returned whenever it contains something
+	output, err := m.gitCombined(ctx, args...)
+	entries := parseRepairOutput(output)
+	if err != nil && len(entries) == 0 {
+		return nil, fmt.Errorf("failed to repair worktrees: %s", output)
+	}
+
+	return entries, nil
+}
+
+// Diagnose reports linked worktrees whose directory is missing or whose .git
+// file does not point to an existing gitdir
+func (m *manager) Diagnose(ctx context.Context) ([]WorktreeProblem, error) {
+	worktrees, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []WorktreeProblem
+	for i, wt := range worktrees {
+		// The main worktree owns the repository and cannot be stale
+		if i == 0 || wt.Bare {
+			continue
+		}
+
+		if problem := diagnoseWorktree(wt); problem != "" {
+			if wt.Locked {
+				problem += " (locked, will not be pruned)"
+			}
+			problems = append(problems, WorktreeProblem{Worktree: wt, Problem: problem})
+		}
+	}
+
+	return problems, nil
+}
+
+// diagnoseWorktree checks a single linked worktree on disk and describes the first problem found
+func diagnoseWorktree(wt Worktree) string {
+	if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+		return "directory is missing (deleted or moved)"
+	}
+
+	gitFile := filepath.Join(wt.Path, ".git")
+	info, err := os.Stat(gitFile)
+	if os.IsNotExist(err) {
+		return ".git file is missing"
+	}
+	if err != nil || info.IsDir() {
+		return ""
+	}
+
+	content, err := os.ReadFile(gitFile)
+	if err != nil {
+		return fmt.Sprintf(".git file cannot be read: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "gitdir:") {
+		return ".git file is not a gitdir reference"
+	}
+
+	gitdir := filepath.FromSlash(strings.TrimSpace(strings.TrimPrefix(string(content), "gitdir:")))
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(wt.Path, gitdir)
+	}
+	if _, err := os.Stat(gitdir); os.IsNotExist(err) {
+		return fmt.Sprintf(".git file points to non-existent gitdir '%s'", gitdir)
+	}
+
+	return ""
+}
+
+// parsePruneOutput parses 'git worktree prune -v' lines such as
+// "Removing worktrees/feature: gitdir file points to non-existent location"
+func parsePruneOutput(output string) []PruneEntry {
+	var entries []PruneEntry
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		rest, found := strings.CutPrefix(line, "Removing ")
+		if !found {
+			continue
+		}
+
+		name, reason, _ := strings.Cut(rest, ": ")
+		entries = append(entries, PruneEntry{
+			Name:   strings.TrimPrefix(name, "worktrees/"),
+			Reason: reason,
+		})
+	}
+	return entries
+}
+
+// parseRepairOutput parses 'git worktree repair' lines such as
+// "repair: gitdir incorrect: /repo/.git/worktrees/feature/gitdir" and
+// "error: unable to locate repository; .git file does not reference a repository: /path/.git"
+func parseRepairOutput(output string) []RepairEntry {
+	var entries []RepairEntry
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		failed := false
+		rest, found := strings.CutPrefix(line, "repair: ")
+		if !found {
+			rest, found = strings.CutPrefix(line, "error: ")
+			failed = true
+		}
+		if !found {
+			continue
+		}
+
+		// The path is the last ": "-separated field
+		idx := strings.LastIndex(rest, ": ")
+		if idx == -1 {
+			entries = append(entries, RepairEntry{Reason: rest, Failed: failed})
+			continue
+		}
+		entries = append(entries, RepairEntry{
+			Path:   rest[idx+2:],
+			Reason: rest[:idx],
+			Failed: failed,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,140 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePruneOutput(t *testing.T) {
+	output := `Removing worktrees/b: gitdir file points to non-existent location
+Removing worktrees/a: gitdir file points to non-existent location
+`
+
+	entries := parsePruneOutput(output)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Name != "b" || entries[0].Reason != "gitdir file points to non-existent location" {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Name != "a" {
+		t.Errorf("Expected second entry 'a', got '%s'", entries[1].Name)
+	}
+
+	if entries := parsePruneOutput(""); len(entries) != 0 {
+		t.Errorf("Expected no entries for empty output, got %+v", entries)
+	}
+}
+
+func TestParseRepairOutput(t *testing.T) {
+	output := `repair: gitdir incorrect: /tmp/pr/r/.git/worktrees/b/gitdir
+error: unable to locate repository; .git file does not reference a repository: /tmp/pr/c/.git
+repair: .git file broken: /tmp/pr/c
+`
+
+	entries := parseRepairOutput(output)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+
+	expected := []RepairEntry{
+		{Path: "/tmp/pr/r/.git/worktrees/b/gitdir", Reason: "gitdir incorrect"},
+		{Path: "/tmp/pr/c/.git", Reason: "unable to locate repository; .git file does not reference a repository", Failed: true},
+		{Path: "/tmp/pr/c", Reason: ".git file broken"},
+	}
+	for i, want := range expected {
+		if entries[i] != want {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, want, entries[i])
+		}
+	}
+}
+
+func TestManagerPruneDiagnoseRepair(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+	base := filepath.Dir(repoDir)
+
+	for _, name := range []string{"deleted", "moved", "broken", "locked"} {
+		if err := m.Create(context.Background(), CreateOptions{Path: filepath.Join(base, name), Branch: name, CreateBranch: true}); err != nil {
+			t.Fatalf("Failed to add worktree %s: %v", name, err)
+		}
+	}
+	if err := m.Lock(context.Background(), filepath.Join(base, "locked"), "keep"); err != nil {
+		t.Fatalf("Failed to lock worktree: %v", err)
+	}
+
+	// Break the worktrees behind git's back
+	if err := os.RemoveAll(filepath.Join(base, "deleted")); err != nil {
+		t.Fatalf("Failed to delete worktree: %v", err)
+	}
+	if err := os.Rename(filepath.Join(base, "moved"), filepath.Join(base, "moved-by-hand")); err != nil {
+		t.Fatalf("Failed to move worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "broken", ".git"), []byte("gitdir: /nonexistent/gitdir\n"), 0644); err != nil {
+		t.Fatalf("Failed to break .git file: %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(base, "locked")); err != nil {
+		t.Fatalf("Failed to delete locked worktree: %v", err)
+	}
+
+	problems, err := m.Diagnose(context.Background())
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	found := map[string]string{}
+	for _, problem := range problems {
+		found[filepath.Base(problem.Worktree.Path)] = problem.Problem
+	}
+	if !strings.Contains(found["deleted"], "missing") || !strings.Contains(found["moved"], "missing") {
+		t.Errorf("Expected missing directories to be reported, got %v", found)
+	}
+	if !strings.Contains(found["broken"], "non-existent gitdir") {
+		t.Errorf("Expected broken .git file to be reported, got %v", found)
+	}
+	if !strings.Contains(found["locked"], "locked") {
+		t.Errorf("Expected locked worktree to be flagged, got %v", found)
+	}
+
+	entries, err := m.Repair(context.Background(), []string{filepath.Join(base, "moved-by-hand")})
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if len(entries) == 0 {
+		t.Error("Expected repair to report fixed entries")
+	}
+
+	stale, err := m.Prune(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Prune dry-run failed: %v", err)
+	}
+	if len(stale) != 1 || stale[0].Name != "deleted" {
+		t.Fatalf("Expected only 'deleted' to be stale, got %+v", stale)
+	}
+
+	if _, err := m.Prune(context.Background(), false); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	worktrees, err := m.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	for _, wt := range worktrees {
+		if filepath.Base(wt.Path) == "deleted" {
+			t.Error("Expected deleted worktree to be pruned")
+		}
+	}
+	if len(worktrees) != 4 {
+		t.Errorf("Expected main, moved, broken and locked worktrees to remain, got %d", len(worktrees))
+	}
+}
+
+func TestManagerRepairValidation(t *testing.T) {
+	m := &manager{repoRoot: "/tmp"}
+
+	if _, err := m.Repair(context.Background(), []string{"/tmp/a;rm"}); err == nil || !strings.Contains(err.Error(), "invalid path") {
+		t.Errorf("Expected invalid path error, got: %v", err)
+	}
+}
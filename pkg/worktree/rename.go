@@ -0,0 +1,110 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RenameOptions describes a branch rename for a worktree
+type RenameOptions struct {
+	Path           string // worktree whose branch is renamed
+	OldBranch      string
+	NewBranch      string
+	NewPath        string // when set, the worktree is moved here after the rename
+	UpdateUpstream bool   // point the upstream at a remote branch with the new name
+}
+
+// RenameResult reports what became of the upstream in a rename
+type RenameResult struct {
+	// Upstream is the remote branch the upstream now follows, such as
+	// origin/feature/new, when it was retargeted
+	Upstream string
+	// MissingUpstream is the remote branch with the new name when it does
+	// not exist, in which case the upstream was left alone
+	MissingUpstream string
+}
+
+// RenameBranch renames a local branch, carrying over its configuration
+func (m *manager) RenameBranch(ctx context.Context, oldBranch, newBranch string) error {
+	// Validate inputs for security
+	if err := validateBranchName(oldBranch); err != nil {
+		return fmt.Errorf("invalid branch name: %w", err)
+	}
+
+	if err := validateBranchName(newBranch); err != nil {
+		return fmt.Errorf("invalid new branch name: %w", err)
+	}
+
+	if m.branchExists(ctx, newBranch) {
+		return &BranchError{Branch: newBranch, Err: ErrBranchExists}
+	}
+
+	if _, err := m.gitOutput(ctx, m.repoRoot, nil, "branch", "-m", oldBranch, newBranch); err != nil {
+		return fmt.Errorf("failed to rename branch: %w", err)
+	}
+	return nil
+}
+
+// Rename renames a worktree's branch, optionally retargets its upstream and
+// moves its directory. Completed steps are rolled back if a later one fails.
+// The upstream is only retargeted when the remote has a branch with the new
+// name, since status and pull would otherwise follow a ref that is missing.
+func (m *manager) Rename(ctx context.Context, opts RenameOptions) (*RenameResult, error) {
+	if opts.NewPath != "" {
+		if err := validatePath(opts.Path); err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+		if err := validatePath(opts.NewPath); err != nil {
+			return nil, fmt.Errorf("invalid destination path: %w", err)
+		}
+	}
+
+	if err := m.RenameBranch(ctx, opts.OldBranch, opts.NewBranch); err != nil {
+		return nil, err
+	}
+
+	// rollback undoes the branch rename; upstream settings travel with it.
+	// It must still run when the failure was a cancellation.
+	rollback := func(cause error, oldMerge string) error {
+		ctx := context.WithoutCancel(ctx)
+		if oldMerge != "" {
+			_, _ = m.gitOutput(ctx, m.repoRoot, nil, "config", fmt.Sprintf("branch.%s.merge", opts.NewBranch), oldMerge)
+		}
+		if _, err := m.gitOutput(ctx, m.repoRoot, nil, "branch", "-m", opts.NewBranch, opts.OldBranch); err != nil {
+			return fmt.Errorf("%w (rollback failed, branch is still named '%s': %v)", cause, opts.NewBranch, err)
+		}
+		return fmt.Errorf("%w (changes rolled back)", cause)
+	}
+
+	result := &RenameResult{}
+	oldMerge := ""
+	if opts.UpdateUpstream {
+		merge, err := m.gitOutput(ctx, m.repoRoot, nil, "config", "--get", fmt.Sprintf("branch.%s.merge", opts.NewBranch))
+		merge = strings.TrimSpace(merge)
+		remote, _ := m.gitOutput(ctx, m.repoRoot, nil, "config", "--get", fmt.Sprintf("branch.%s.remote", opts.NewBranch))
+		remote = strings.TrimSpace(remote)
+
+		// Only retarget upstreams that followed the old branch name on a remote
+		if err == nil && merge == "refs/heads/"+opts.OldBranch && remote != "" && remote != "." {
+			upstream := remote + "/" + opts.NewBranch
+			if _, err := m.gitOutput(ctx, m.repoRoot, nil, "rev-parse", "--verify", "--quiet", "refs/remotes/"+upstream); err != nil {
+				result.MissingUpstream = upstream
+			} else {
+				if _, err := m.gitOutput(ctx, m.repoRoot, nil, "config", fmt.Sprintf("branch.%s.merge", opts.NewBranch), "refs/heads/"+opts.NewBranch); err != nil {
+					return nil, rollback(fmt.Errorf("failed to update upstream: %w", err), "")
+				}
+				oldMerge = merge
+				result.Upstream = upstream
+			}
+		}
+	}
+
+	if opts.NewPath != "" {
+		if err := m.Move(ctx, opts.Path, opts.NewPath, MoveOptions{}); err != nil {
+			return nil, rollback(err, oldMerge)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,142 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestManagerRenameBranchValidation(t *testing.T) {
+	m := &manager{repoRoot: "/tmp"}
+
+	if err := m.RenameBranch(context.Background(), "feature", "bad;name"); err == nil || !strings.Contains(err.Error(), "invalid new branch name") {
+		t.Errorf("Expected invalid new branch name error, got: %v", err)
+	}
+
+	if err := m.RenameBranch(context.Background(), "-x", "feature"); err == nil || !strings.Contains(err.Error(), "invalid branch name") {
+		t.Errorf("Expected invalid branch name error, got: %v", err)
+	}
+}
+
+func TestManagerRename(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+
+	oldPath := filepath.Join(filepath.Dir(repoDir), "feature-old")
+	newPath := filepath.Join(filepath.Dir(repoDir), "feature-new")
+	if err := m.Create(context.Background(), CreateOptions{Path: oldPath, Branch: "feature/old", CreateBranch: true}); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+
+	// Simulate an upstream that follows the branch name, with the new name
+	// already pushed
+	runTestGit(t, repoDir, "config", "branch.feature/old.remote", "origin")
+	runTestGit(t, repoDir, "config", "branch.feature/old.merge", "refs/heads/feature/old")
+	runTestGit(t, repoDir, "update-ref", "refs/remotes/origin/feature/new", "HEAD")
+
+	result, err := m.Rename(context.Background(), RenameOptions{
+		Path:           oldPath,
+		OldBranch:      "feature/old",
+		NewBranch:      "feature/new",
+		NewPath:        newPath,
+		UpdateUpstream: true,
+	})
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+
+	if branch := runTestGit(t, newPath, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feature/new" {
+		t.Errorf("Expected branch 'feature/new', got '%s'", branch)
+	}
+	if merge := runTestGit(t, repoDir, "config", "--get", "branch.feature/new.merge"); merge != "refs/heads/feature/new" {
+		t.Errorf("Expected upstream to follow the new name, got '%s'", merge)
+	}
+	if result.Upstream != "origin/feature/new" || result.MissingUpstream != "" {
+		t.Errorf("Expected the retargeted upstream to be reported, got %+v", result)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("Expected old path to be gone, got: %v", err)
+	}
+}
+
+func TestManagerRenameRollback(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+
+	wtPath := filepath.Join(filepath.Dir(repoDir), "feature")
+	blocked := filepath.Join(filepath.Dir(repoDir), "blocked")
+	if err := m.Create(context.Background(), CreateOptions{Path: wtPath, Branch: "feature", CreateBranch: true}); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+	if err := os.Mkdir(blocked, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	runTestGit(t, repoDir, "config", "branch.feature.remote", "origin")
+	runTestGit(t, repoDir, "config", "branch.feature.merge", "refs/heads/feature")
+	runTestGit(t, repoDir, "update-ref", "refs/remotes/origin/renamed", "HEAD")
+
+	_, err := m.Rename(context.Background(), RenameOptions{
+		Path:           wtPath,
+		OldBranch:      "feature",
+		NewBranch:      "renamed",
+		NewPath:        blocked,
+		UpdateUpstream: true,
+	})
+	if err == nil {
+		t.Fatal("Expected rename to fail when the destination exists")
+	}
+	if !strings.Contains(err.Error(), "rolled back") {
+		t.Errorf("Expected rollback to be reported, got: %v", err)
+	}
+
+	if branch := runTestGit(t, wtPath, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feature" {
+		t.Errorf("Expected branch name to be restored, got '%s'", branch)
+	}
+	if merge := runTestGit(t, repoDir, "config", "--get", "branch.feature.merge"); merge != "refs/heads/feature" {
+		t.Errorf("Expected upstream to be restored, got '%s'", merge)
+	}
+}
+
+func TestManagerRenameWithoutRemoteBranch(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+
+	wtPath := filepath.Join(filepath.Dir(repoDir), "feature")
+	if err := m.Create(context.Background(), CreateOptions{Path: wtPath, Branch: "feature", CreateBranch: true}); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+	runTestGit(t, repoDir, "config", "branch.feature.remote", "origin")
+	runTestGit(t, repoDir, "config", "branch.feature.merge", "refs/heads/feature")
+
+	// origin has no branch named renamed, so the upstream is left alone
+	result, err := m.Rename(context.Background(), RenameOptions{
+		Path:           wtPath,
+		OldBranch:      "feature",
+		NewBranch:      "renamed",
+		UpdateUpstream: true,
+	})
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if merge := runTestGit(t, repoDir, "config", "--get", "branch.renamed.merge"); merge != "refs/heads/feature" {
+		t.Errorf("Expected the upstream to be left alone, got '%s'", merge)
+	}
+	if result.Upstream != "" || result.MissingUpstream != "origin/renamed" {
+		t.Errorf("Expected the missing remote branch to be reported, got %+v", result)
+	}
+}
+
+func TestManagerRenameExistingBranch(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+
+	runTestGit(t, repoDir, "branch", "taken")
+
+	err := m.RenameBranch(context.Background(), "main", "taken")
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected already exists error, got: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package worktree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Repository describes where a git repository lives on disk
+type Repository struct {
+	Root      string // main worktree path, or the repository directory when bare
+	CommonDir string // git directory shared by all worktrees
+	Bare      bool
+}
+
+// DiscoverRepository locates the repository containing startPath. It asks git
+// itself so that GIT_DIR/GIT_WORK_TREE, submodules, relative gitdir files and
+// bare repositories are handled, and falls back to FindGitRoot when git
+// cannot answer (for example when the git binary is unavailable).
+func DiscoverRepository(ctx context.Context, startPath string) (*Repository, error) {
+	absStart, err := filepath.Abs(startPath)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, gitErr := discoverWithGit(ctx, absStart)
+	if gitErr == nil {
+		return repo, nil
+	}
+
+	root, err := FindGitRoot(absStart)
+	if err != nil {
+		return nil, err
+	}
+	return &Repository{Root: root, CommonDir: filepath.Join(root, ".git")}, nil
+}
+
+// discoverWithGit resolves the repository layout with 'git rev-parse
+// --git-common-dir --show-toplevel'. Only bare repositories, where there is
+// no top level, and worktrees whose common dir is not a .git directory need
+// another git command.
+func discoverWithGit(ctx context.Context, startPath string) (*Repository, error) {
+	output, err := gitOutput(ctx, startPath, nil, "rev-parse", "--git-common-dir", "--show-toplevel")
+	if err != nil {
+		return discoverBare(ctx, startPath)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("unexpected rev-parse output: %q", output)
+	}
+	commonDir := absGitPath(startPath, lines[0])
+	topLevel := filepath.Clean(filepath.FromSlash(strings.TrimSpace(lines[1])))
+
+	// The common dir of a regular repository is the .git directory of its
+	// main worktree, whichever of its worktrees startPath is in
+	if filepath.Base(commonDir) == ".git" {
+		return &Repository{Root: filepath.Dir(commonDir), CommonDir: commonDir}, nil
+	}
+
+	// Submodules and linked worktrees of bare repositories keep the common
+	// dir elsewhere, so ask git for the main worktree entry, which is listed
+	// first and marked when it is bare
+	output, err = gitOutput(ctx, startPath, nil, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	worktrees, err := parseWorktreeList(output)
+	if err != nil {
+		return nil, err
+	}
+	// git lists a submodule's git directory as its main worktree
+	if len(worktrees) == 0 || (!worktrees[0].Bare && filepath.Clean(worktrees[0].Path) == commonDir) {
+		return &Repository{Root: topLevel, CommonDir: commonDir}, nil
+	}
+
+	mainWorktree := worktrees[0]
+	return &Repository{Root: mainWorktree.Path, CommonDir: commonDir, Bare: mainWorktree.Bare}, nil
+}
+
+// discoverBare resolves a bare repository, which has no top level
+func discoverBare(ctx context.Context, startPath string) (*Repository, error) {
+	output, err := gitOutput(ctx, startPath, nil, "rev-parse", "--git-common-dir", "--is-bare-repository")
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 || strings.TrimSpace(lines[1]) != "true" {
+		return nil, errors.New("not a git repository")
+	}
+	commonDir := absGitPath(startPath, lines[0])
+	return &Repository{Root: commonDir, CommonDir: commonDir, Bare: true}, nil
+}
+
+// absGitPath resolves a path printed by rev-parse; older git versions print
+// it relative to the working directory
+func absGitPath(startPath, path string) string {
+	path = filepath.FromSlash(strings.TrimSpace(path))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(startPath, path)
+	}
+	return filepath.Clean(path)
+}
+
+// MainWorktreePath returns the path of the main worktree (or the bare repository)
+func (m *manager) MainWorktreePath() string {
+	return m.repoRoot
+}
+
+// CommonDir returns the git directory shared by all worktrees
+func (m *manager) CommonDir() string {
+	if m.commonDir == "" {
+		return filepath.Join(m.repoRoot, ".git")
+	}
+	return m.commonDir
+}
+
+// GitDir returns the administrative directory git keeps for the worktree at
+// path, where per-worktree state such as HEAD and the index is stored
+func (m *manager) GitDir(ctx context.Context, path string) (string, error) {
+	// Validate input for security
+	if err := validatePath(path); err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+
+	output, err := m.gitOutput(ctx, path, nil, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory of '%s': %w", path, err)
+	}
+	return strings.TrimSpace(output), nil
+}
@@ -0,0 +1,189 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverRepository(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+
+	wtPath := filepath.Join(filepath.Dir(repoDir), "feature")
+	if err := m.Create(context.Background(), CreateOptions{Path: wtPath, Branch: "feature", CreateBranch: true}); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+
+	nested := filepath.Join(repoDir, "sub", "dir")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create nested dir: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		start string
+	}{
+		{name: "Main worktree", start: repoDir},
+		{name: "Nested directory", start: nested},
+		{name: "Linked worktree", start: wtPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := DiscoverRepository(context.Background(), tt.start)
+			if err != nil {
+				t.Fatalf("DiscoverRepository failed: %v", err)
+			}
+			if repo.Root != repoDir {
+				t.Errorf("Expected root %s, got %s", repoDir, repo.Root)
+			}
+			if repo.CommonDir != filepath.Join(repoDir, ".git") {
+				t.Errorf("Expected common dir %s, got %s", filepath.Join(repoDir, ".git"), repo.CommonDir)
+			}
+			if repo.Bare {
+				t.Error("Expected non-bare repository")
+			}
+		})
+	}
+}
+
+func TestDiscoverRepositoryBare(t *testing.T) {
+	repoDir := initTestRepo(t)
+	bareDir := filepath.Join(filepath.Dir(repoDir), "bare.git")
+	runTestGit(t, filepath.Dir(repoDir), "clone", "-q", "--bare", repoDir, bareDir)
+
+	repo, err := DiscoverRepository(context.Background(), bareDir)
+	if err != nil {
+		t.Fatalf("DiscoverRepository failed: %v", err)
+	}
+	if !repo.Bare {
+		t.Error("Expected bare repository")
+	}
+	if repo.Root != bareDir || repo.CommonDir != bareDir {
+		t.Errorf("Expected root and common dir %s, got %+v", bareDir, repo)
+	}
+
+	// Linked worktrees of a bare repository resolve to the bare directory
+	wtPath := filepath.Join(filepath.Dir(repoDir), "bare-main")
+	runTestGit(t, bareDir, "worktree", "add", "-q", wtPath, "main")
+
+	repo, err = DiscoverRepository(context.Background(), wtPath)
+	if err != nil {
+		t.Fatalf("DiscoverRepository failed: %v", err)
+	}
+	if repo.Root != bareDir || !repo.Bare {
+		t.Errorf("Expected linked worktree to resolve to bare repo %s, got %+v", bareDir, repo)
+	}
+}
+
+func TestDiscoverRepositorySubmodule(t *testing.T) {
+	repoDir := initTestRepo(t)
+	libDir := filepath.Join(filepath.Dir(repoDir), "lib")
+	runTestGit(t, filepath.Dir(repoDir), "clone", "-q", repoDir, libDir)
+	runTestGit(t, repoDir, "-c", "protocol.file.allow=always", "submodule", "add", "-q", libDir, "lib")
+
+	// A submodule's git directory lives inside the superproject's
+	subDir := filepath.Join(repoDir, "lib")
+	repo, err := DiscoverRepository(context.Background(), subDir)
+	if err != nil {
+		t.Fatalf("DiscoverRepository failed: %v", err)
+	}
+	if repo.Root != subDir || repo.Bare {
+		t.Errorf("Expected the submodule %s as root, got %+v", subDir, repo)
+	}
+	if expected := filepath.Join(repoDir, ".git", "modules", "lib"); repo.CommonDir != expected {
+		t.Errorf("Expected common dir %s, got %s", expected, repo.CommonDir)
+	}
+}
+
+func TestDiscoverRepositoryFallback(t *testing.T) {
+	// A bare .git directory is not a valid repository for git itself, so
+	// discovery falls back to walking the directory tree
+	repoDir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+
+	repo, err := DiscoverRepository(context.Background(), repoDir)
+	if err != nil {
+		t.Fatalf("DiscoverRepository failed: %v", err)
+	}
+
+	expected, err := filepath.Abs(repoDir)
+	if err != nil {
+		t.Fatalf("Failed to resolve path: %v", err)
+	}
+	if repo.Root != expected {
+		t.Errorf("Expected root %s, got %s", expected, repo.Root)
+	}
+	if repo.CommonDir != filepath.Join(expected, ".git") {
+		t.Errorf("Expected common dir %s, got %s", filepath.Join(expected, ".git"), repo.CommonDir)
+	}
+}
+
+func TestFindGitRootRelativeGitdir(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "yosegi-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir) // Ignore cleanup errors
+	}()
+
+	mainRepo := filepath.Join(tmpDir, "main")
+	if err := os.MkdirAll(filepath.Join(mainRepo, ".git", "worktrees", "wt"), 0755); err != nil {
+		t.Fatalf("Failed to create main repo: %v", err)
+	}
+	worktreeDir := filepath.Join(tmpDir, "wt")
+	if err := os.MkdirAll(worktreeDir, 0755); err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	gitContent := "gitdir: ../main/.git/worktrees/wt\n"
+	if err := os.WriteFile(filepath.Join(worktreeDir, ".git"), []byte(gitContent), 0644); err != nil {
+		t.Fatalf("Failed to write .git file: %v", err)
+	}
+
+	root, err := FindGitRoot(worktreeDir)
+	if err != nil {
+		t.Fatalf("FindGitRoot failed: %v", err)
+	}
+	if root != mainRepo {
+		t.Errorf("Expected root %s, got %s", mainRepo, root)
+	}
+}
+
+func TestManagerRepositoryAccessors(t *testing.T) {
+	m := &manager{repoRoot: "/repo", commonDir: "/repo.git"}
+	if m.MainWorktreePath() != "/repo" {
+		t.Errorf("Expected main worktree path /repo, got %s", m.MainWorktreePath())
+	}
+	if m.CommonDir() != "/repo.git" {
+		t.Errorf("Expected common dir /repo.git, got %s", m.CommonDir())
+	}
+
+	m = &manager{repoRoot: "/repo"}
+	if m.CommonDir() != filepath.Join("/repo", ".git") {
+		t.Errorf("Expected default common dir, got %s", m.CommonDir())
+	}
+}
+
+func TestManagerGitDir(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+	ctx := context.Background()
+
+	wtPath := filepath.Join(filepath.Dir(repoDir), "feature")
+	if err := m.Create(ctx, CreateOptions{Path: wtPath, Branch: "feature", CreateBranch: true}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	gitDir, err := m.GitDir(ctx, repoDir)
+	if err != nil || gitDir != filepath.Join(repoDir, ".git") {
+		t.Errorf("Expected main git dir, got %q, %v", gitDir, err)
+	}
+
+	gitDir, err = m.GitDir(ctx, wtPath)
+	if err != nil || filepath.Dir(gitDir) != filepath.Join(repoDir, ".git", "worktrees") {
+		t.Errorf("Expected linked worktree admin dir, got %q, %v", gitDir, err)
+	}
+}
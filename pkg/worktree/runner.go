@@ -0,0 +1,209 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Runner executes git commands. Every git invocation made by this package goes
+// through a Runner so that tests can script git's behaviour and calls can be traced.
+type Runner interface {
+	// Run executes git with args in dir, adding env to the process environment,
+	// and returns what git wrote to stdout and stderr. The child process is
+	// stopped when ctx is cancelled.
+	Run(ctx context.Context, dir string, env []string, args ...string) (stdout, stderr string, err error)
+}
+
+// ExecRunner runs the git binary found in PATH
+type ExecRunner struct{}
+
+var (
+	_ Runner = ExecRunner{}
+	_ Runner = (*TraceRunner)(nil)
+)
+
+// Run executes git as a child process. Unless ctx is marked with WithInteractive,
+// git is not allowed to prompt for credentials, so it fails instead of hanging.
+func (ExecRunner) Run(ctx context.Context, dir string, env []string, args ...string) (string, string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Don't wait forever for pipes held open by helpers git spawned (e.g. ssh)
+	cmd.WaitDelay = time.Second
+
+	// Untranslated messages let the package recognise error conditions
+	env = append([]string{"LC_ALL=C"}, env...)
+	if !IsInteractive(ctx) {
+		env = append([]string{"GIT_TERMINAL_PROMPT=0"}, env...)
+	}
+	cmd.Env = append(os.Environ(), env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+// TraceRunner logs every git invocation made through the wrapped Runner
+// together with its duration and outcome
+type TraceRunner struct {
+	runner Runner
+	out    io.Writer
+	mu     sync.Mutex
+}
+
+// NewTraceRunner wraps runner so that each invocation is logged to out
+func NewTraceRunner(runner Runner, out io.Writer) *TraceRunner {
+	return &TraceRunner{runner: runner, out: out}
+}
+
+// Run executes git through the wrapped Runner and logs the call
+func (t *TraceRunner) Run(ctx context.Context, dir string, env []string, args ...string) (string, string, error) {
+	start := time.Now()
+	stdout, stderr, err := t.runner.Run(ctx, dir, env, args...)
+	elapsed := time.Since(start)
+
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, _ = fmt.Fprintf(t.out, "trace: git %s (dir=%s) %s [%s]\n", strings.Join(args, " "), dir, elapsed.Round(time.Microsecond), status)
+	return stdout, stderr, err
+}
+
+// interactiveKey marks contexts whose git calls may prompt the user
+type interactiveKey struct{}
+
+// WithInteractive returns a context whose git calls may prompt on the terminal,
+// for example for credentials during a clone
+func WithInteractive(ctx context.Context) context.Context {
+	return context.WithValue(ctx, interactiveKey{}, true)
+}
+
+// IsInteractive reports whether git calls made with ctx may prompt the user
+func IsInteractive(ctx context.Context) bool {
+	interactive, _ := ctx.Value(interactiveKey{}).(bool)
+	return interactive
+}
+
+var (
+	defaultsMu     sync.RWMutex
+	defaultRunner  Runner = ExecRunner{}
+	defaultTimeout        = time.Minute
+)
+
+// SetDefaultRunner replaces the Runner used by NewManager, repository discovery
+// and cloning, for example to enable tracing
+func SetDefaultRunner(runner Runner) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	defaultRunner = runner
+}
+
+// DefaultRunner returns the Runner used when none is injected
+func DefaultRunner() Runner {
+	defaultsMu.RLock()
+	defer defaultsMu.RUnlock()
+	return defaultRunner
+}
+
+// SetDefaultTimeout sets how long each git command run by managers created
+// with NewManager may take. Zero or a negative value disables the timeout.
+func SetDefaultTimeout(timeout time.Duration) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	defaultTimeout = timeout
+}
+
+// DefaultTimeout returns the timeout applied to each git command
+func DefaultTimeout() time.Duration {
+	defaultsMu.RLock()
+	defer defaultsMu.RUnlock()
+	return defaultTimeout
+}
+
+// contextError explains why git did not finish when ctx ended before it did
+func contextError(ctx context.Context, args []string) error {
+	command := strings.Join(args, " ")
+	if len(args) > 2 {
+		command = strings.Join(args[:2], " ")
+	}
+
+	switch err := ctx.Err(); {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("git %s timed out: %w", command, err)
+	case err != nil:
+		return fmt.Errorf("git %s was cancelled: %w", command, err)
+	}
+	return nil
+}
+
+// runGit runs git and returns stdout. On failure the error includes git's stderr output.
+func runGit(ctx context.Context, runner Runner, dir string, env []string, args ...string) (string, error) {
+	stdout, stderr, err := runner.Run(ctx, dir, env, args...)
+	if err != nil {
+		if ctxErr := contextError(ctx, args); ctxErr != nil {
+			return stdout, ctxErr
+		}
+		if msg := strings.TrimSpace(stderr); msg != "" {
+			return stdout, fmt.Errorf("%w: %s", err, msg)
+		}
+		return stdout, err
+	}
+	return stdout, nil
+}
+
+// gitOutput runs git with the default Runner in dir with extra environment
+// variables and returns stdout
+func gitOutput(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	return runGit(ctx, DefaultRunner(), dir, env, args...)
+}
+
+// git returns the Runner injected into the manager, or the default one
+func (m *manager) git() Runner {
+	if m.runner != nil {
+		return m.runner
+	}
+	return DefaultRunner()
+}
+
+// withTimeout bounds a single git command by the manager's timeout
+func (m *manager) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.timeout > 0 {
+		return context.WithTimeout(ctx, m.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// gitOutput runs git through the manager's Runner and returns stdout
+func (m *manager) gitOutput(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	return runGit(ctx, m.git(), dir, env, args...)
+}
+
+// gitCombined runs git in the repository root through the manager's Runner and
+// returns stdout followed by stderr, for commands that report on either stream.
+// When the command times out or is cancelled, the output explains why instead.
+func (m *manager) gitCombined(ctx context.Context, args ...string) (string, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stdout, stderr, err := m.git().Run(ctx, m.repoRoot, nil, args...)
+	if ctxErr := contextError(ctx, args); err != nil && ctxErr != nil {
+		return ctxErr.Error(), ctxErr
+	}
+	return stdout + stderr, err
+}
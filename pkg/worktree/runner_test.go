@@ -0,0 +1,221 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yagi2/yosegi/pkg/worktree/worktreetest"
+)
+
+func TestExecRunner(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	stdout, _, err := ExecRunner{}.Run(context.Background(), "", nil, "--version")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.HasPrefix(stdout, "git version") {
+		t.Errorf("Unexpected output: %q", stdout)
+	}
+
+	_, stderr, err := ExecRunner{}.Run(context.Background(), t.TempDir(), []string{"GIT_CEILING_DIRECTORIES=/"}, "rev-parse", "--git-dir")
+	if err == nil {
+		t.Fatal("Expected error outside a repository")
+	}
+	if !strings.Contains(stderr, "not a git repository") {
+		t.Errorf("Expected stderr to be captured, got %q", stderr)
+	}
+}
+
+func TestTraceRunner(t *testing.T) {
+	fake := worktreetest.NewRunner()
+	fake.On("status").Return("clean")
+	fake.On("fetch").Fail("fatal: unable to access remote")
+
+	var out bytes.Buffer
+	runner := NewTraceRunner(fake, &out)
+
+	stdout, _, err := runner.Run(context.Background(), "/repo", nil, "status", "--short")
+	if err != nil || stdout != "clean" {
+		t.Fatalf("Expected wrapped result, got %q, %v", stdout, err)
+	}
+	if _, _, err := runner.Run(context.Background(), "/repo", nil, "fetch"); err == nil {
+		t.Fatal("Expected wrapped error")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 trace lines, got %q", out.String())
+	}
+	if !strings.Contains(lines[0], "git status --short (dir=/repo)") || !strings.HasSuffix(lines[0], "[ok]") {
+		t.Errorf("Unexpected trace line: %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "git fetch") || !strings.Contains(lines[1], worktreetest.ErrExit.Error()) {
+		t.Errorf("Unexpected trace line: %q", lines[1])
+	}
+}
+
+func TestSetDefaultRunner(t *testing.T) {
+	original := DefaultRunner()
+	defer SetDefaultRunner(original)
+
+	fake := worktreetest.NewRunner()
+	fake.On("rev-parse").Return("abc\n")
+	SetDefaultRunner(fake)
+
+	if _, err := gitOutput(context.Background(), "/repo", nil, "rev-parse", "HEAD"); err != nil {
+		t.Fatalf("gitOutput failed: %v", err)
+	}
+	if !fake.Called("rev-parse", "HEAD") {
+		t.Error("Expected the default runner to be used")
+	}
+
+	// A manager without an injected runner follows the default
+	m := &manager{repoRoot: "/repo"}
+	if m.git() != Runner(fake) {
+		t.Error("Expected manager to fall back to the default runner")
+	}
+}
+
+func TestRunGit(t *testing.T) {
+	fake := worktreetest.NewRunner()
+	fake.On("ok").Return("out")
+	fake.On("fail").Fail("  fatal: boom \n")
+
+	output, err := runGit(context.Background(), fake, "", nil, "ok")
+	if err != nil || output != "out" {
+		t.Errorf("Expected stdout, got %q, %v", output, err)
+	}
+
+	_, err = runGit(context.Background(), fake, "", nil, "fail")
+	if !errors.Is(err, worktreetest.ErrExit) {
+		t.Errorf("Expected wrapped exit error, got %v", err)
+	}
+	if err == nil || !strings.HasSuffix(err.Error(), ": fatal: boom") {
+		t.Errorf("Expected trimmed stderr in error, got %v", err)
+	}
+}
+
+func TestManagerGitCombined(t *testing.T) {
+	fake := worktreetest.NewRunner()
+	fake.On("worktree", "prune").Return("out\n").Fail("err\n")
+	m := NewManagerWithRunner("/repo", "/repo/.git", fake).(*manager)
+
+	output, err := m.gitCombined(context.Background(), "worktree", "prune")
+	if err == nil {
+		t.Error("Expected error")
+	}
+	if output != "out\nerr\n" {
+		t.Errorf("Expected stdout followed by stderr, got %q", output)
+	}
+	if calls := fake.Calls(); len(calls) != 1 || calls[0].Dir != "/repo" {
+		t.Errorf("Expected call in repository root, got %+v", calls)
+	}
+}
+
+func TestExecRunnerTerminalPrompt(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	// A shell alias prints the environment git passes to its children
+	args := []string{"-c", "alias.printenv=!env", "printenv"}
+
+	stdout, _, err := ExecRunner{}.Run(context.Background(), t.TempDir(), nil, args...)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(stdout, "GIT_TERMINAL_PROMPT=0") {
+		t.Error("Expected prompts to be disabled for non-interactive calls")
+	}
+	if !strings.Contains(stdout, "LC_ALL=C") {
+		t.Error("Expected git to run in the C locale")
+	}
+
+	stdout, _, err = ExecRunner{}.Run(WithInteractive(context.Background()), t.TempDir(), nil, args...)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if strings.Contains(stdout, "GIT_TERMINAL_PROMPT=0") {
+		t.Error("Expected prompts to be allowed for interactive calls")
+	}
+}
+
+func TestExecRunnerCancellation(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := ExecRunner{}.Run(ctx, t.TempDir(), nil, "-c", "alias.hang=!sleep 10", "hang")
+	if err == nil {
+		t.Fatal("Expected the hung command to be stopped")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancellation to stop git promptly, took %s", elapsed)
+	}
+}
+
+func TestManagerTimeout(t *testing.T) {
+	fake := worktreetest.NewRunner()
+	fake.On("worktree", "list").Hang()
+	fake.On("worktree", "remove").Hang()
+	m := &manager{repoRoot: "/repo", runner: fake, timeout: 10 * time.Millisecond}
+
+	_, err := m.List(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "git worktree list timed out") {
+		t.Errorf("Expected timeout message, got %v", err)
+	}
+
+	// Combined-output commands explain the timeout instead of git's output
+	err = m.Remove(context.Background(), "/tmp/test", RemoveOptions{})
+	if err == nil || !strings.Contains(err.Error(), "git worktree remove timed out") {
+		t.Errorf("Expected timeout message, got %v", err)
+	}
+}
+
+func TestManagerCancellation(t *testing.T) {
+	fake := worktreetest.NewRunner()
+	fake.On("worktree", "list").Hang()
+	m := &manager{repoRoot: "/repo", runner: fake}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.List(ctx)
+		done <- err
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "was cancelled") {
+			t.Errorf("Expected cancellation error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("List did not return after cancellation")
+	}
+}
+
+func TestSetDefaultTimeout(t *testing.T) {
+	original := DefaultTimeout()
+	defer SetDefaultTimeout(original)
+
+	SetDefaultTimeout(5 * time.Second)
+	if DefaultTimeout() != 5*time.Second {
+		t.Errorf("Expected 5s, got %s", DefaultTimeout())
+	}
+}
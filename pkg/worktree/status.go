@@ -0,0 +1,246 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status summarises the state of a worktree's checkout
+type Status struct {
+	Branch     string // empty when HEAD is detached
+	Upstream   string // empty when the branch has no upstream
+	Ahead      int    // commits not yet on the upstream
+	Behind     int    // upstream commits not yet on the branch
+	Staged     int    // files with changes in the index
+	Modified   int    // files with changes not yet staged
+	Untracked  int
+	Conflicted int
+	Files      []FileStatus // changed files in the order git reports them
+}
+
+// FileStatus is one changed file, as listed by 'git status --short'
+type FileStatus struct {
+	Code string // two-letter index and worktree state, e.g. " M", "A ", "??"
+	Path string
+}
+
+// Commit summarises one commit in a worktree's history
+type Commit struct {
+	Hash    string // abbreviated
+	Author  string
+	Date    time.Time
+	Subject string
+}
+
+// Clean reports whether the worktree has no uncommitted changes or untracked files
+func (s Status) Clean() bool {
+	return s.Staged == 0 && s.Modified == 0 && s.Untracked == 0 && s.Conflicted == 0
+}
+
+// Status reports the branch, upstream divergence and uncommitted changes of
+// the worktree at path
+func (m *manager) Status(ctx context.Context, path string) (*Status, error) {
+	// Validate input for security
+	if err := validatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	output, err := m.gitOutput(ctx, path, nil, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree status: %w", err)
+	}
+
+	return parseStatus(output)
+}
+
+// DiffOptions controls what Diff compares
+type DiffOptions struct {
+	Base string // commit or branch to compare against, HEAD when empty
+	Stat bool   // summarise changes per file instead of showing the patch
+}
+
+// Diff returns the changes in the worktree at path, staged or not, relative
+// to opts.Base. Untracked files are not included.
+func (m *manager) Diff(ctx context.Context, path string, opts DiffOptions) (string, error) {
+	// Validate inputs for security
+	if err := validatePath(path); err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+
+	base := opts.Base
+	if base == "" {
+		base = "HEAD"
+	} else if err := validateBranchName(base); err != nil {
+		return "", fmt.Errorf("invalid base: %w", err)
+	}
+
+	args := []string{"diff", "--no-color", "--no-ext-diff"}
+	if opts.Stat {
+		args = append(args, "--stat")
+	}
+	args = append(args, base, "--")
+
+	output, err := m.gitOutput(ctx, path, nil, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff worktree: %w", err)
+	}
+	return output, nil
+}
+
+// parseStatus parses the output of 'git status --porcelain=v2 --branch'
+func parseStatus(output string) (*Status, error) {
+	status := &Status{}
+
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "#":
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "branch.head":
+				if fields[2] != "(detached)" {
+					status.Branch = fields[2]
+				}
+			case "branch.upstream":
+				status.Upstream = fields[2]
+			case "branch.ab":
+				if len(fields) != 4 {
+					return nil, fmt.Errorf("malformed branch.ab line: %q", line)
+				}
+				ahead, err := strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+				if err != nil {
+					return nil, fmt.Errorf("malformed branch.ab line: %q", line)
+				}
+				behind, err := strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
+				if err != nil {
+					return nil, fmt.Errorf("malformed branch.ab line: %q", line)
+				}
+				status.Ahead, status.Behind = ahead, behind
+			}
+		case "1", "2":
+			// XY holds the index and worktree state, '.' meaning unchanged
+			if len(fields) < 2 || len(fields[1]) != 2 {
+				return nil, fmt.Errorf("malformed change line: %q", line)
+			}
+			if fields[1][0] != '.' {
+				status.Staged++
+			}
+			if fields[1][1] != '.' {
+				status.Modified++
+			}
+			status.Files = append(status.Files, FileStatus{Code: strings.ReplaceAll(fields[1], ".", " "), Path: statusPath(line, fields[0])})
+		case "u":
+			status.Conflicted++
+			status.Files = append(status.Files, FileStatus{Code: fields[1], Path: statusPath(line, fields[0])})
+		case "?":
+			status.Untracked++
+			status.Files = append(status.Files, FileStatus{Code: "??", Path: statusPath(line, fields[0])})
+		}
+	}
+
+	return status, nil
+}
+
+// statusPath returns the path of a porcelain v2 entry. Paths may contain
+// spaces, so they are found by counting the fields before them; a rename
+// ("2") shows the new path.
+func statusPath(line, kind string) string {
+	before := map[string]int{"1": 8, "2": 9, "u": 10, "?": 1}[kind]
+	parts := strings.SplitN(line, " ", before+1)
+	if len(parts) <= before {
+		return ""
+	}
+	path, _, _ := strings.Cut(parts[before], "\t")
+	return path
+}
+
+// Log returns up to limit of the most recent commits checked out in the
+// worktree at path, newest first
+func (m *manager) Log(ctx context.Context, path string, limit int) ([]Commit, error) {
+	// Validate input for security
+	if err := validatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	output, err := m.gitOutput(ctx, path, nil, "log", "-n", strconv.Itoa(limit), "--format=%h%x00%an%x00%at%x00%s")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return parseLog(output), nil
+}
+
+// parseLog parses NUL-separated hash, author, timestamp and subject lines
+func parseLog(output string) []Commit {
+	var commits []Commit
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		seconds, _ := strconv.ParseInt(fields[2], 10, 64)
+		commits = append(commits, Commit{Hash: fields[0], Author: fields[1], Date: time.Unix(seconds, 0), Subject: fields[3]})
+	}
+	return commits
+}
+
+// CommitTimes returns the committer date of each of the given commits, keyed
+// by full hash. Empty commits are skipped.
+func (m *manager) CommitTimes(ctx context.Context, commits []string) (map[string]time.Time, error) {
+	args := []string{"show", "--no-patch", "--format=%H %ct", "--end-of-options"}
+	for _, commit := range commits {
+		if commit == "" {
+			continue
+		}
+		// Validate input for security
+		if err := validateBranchName(commit); err != nil {
+			return nil, fmt.Errorf("invalid commit: %w", err)
+		}
+		args = append(args, commit)
+	}
+
+	times := make(map[string]time.Time)
+	if len(args) == 4 {
+		return times, nil
+	}
+
+	output, err := m.gitOutput(ctx, m.repoRoot, nil, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit dates: %w", err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		hash, date, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		seconds, _ := strconv.ParseInt(date, 10, 64)
+		times[hash] = time.Unix(seconds, 0)
+	}
+	return times, nil
+}
+
+// MergeBase returns the best common ancestor of ref and HEAD of the worktree
+// at path, the point to compare against to see only the branch's own changes
+func (m *manager) MergeBase(ctx context.Context, path, ref string) (string, error) {
+	// Validate inputs for security
+	if err := validatePath(path); err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+	if err := validateBranchName(ref); err != nil {
+		return "", fmt.Errorf("invalid ref: %w", err)
+	}
+
+	output, err := m.gitOutput(ctx, path, nil, "merge-base", ref, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("no common ancestor with '%s'", ref)
+	}
+	return strings.TrimSpace(output), nil
+}
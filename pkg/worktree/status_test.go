@@ -0,0 +1,212 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    Status
+		expectClean bool
+		expectError bool
+	}{
+		{
+			name:        "Clean branch without upstream",
+			input:       "# branch.oid abc123\n# branch.head main\n",
+			expected:    Status{Branch: "main"},
+			expectClean: true,
+		},
+		{
+			name: "Diverged with changes",
+			input: "# branch.oid abc123\n# branch.head feature/x\n# branch.upstream origin/feature/x\n# branch.ab +2 -1\n" +
+				"1 M. N... 100644 100644 100644 aaa bbb staged.go\n" +
+				"1 .M N... 100644 100644 100644 aaa bbb modified.go\n" +
+				"1 MM N... 100644 100644 100644 aaa bbb both.go\n" +
+				"2 R. N... 100644 100644 100644 aaa bbb R100 new.go\told.go\n" +
+				"u UU N... 100644 100644 100644 100644 aaa bbb ccc conflict.go\n" +
+				"? my notes.txt\n",
+			expected: Status{
+				Branch: "feature/x", Upstream: "origin/feature/x", Ahead: 2, Behind: 1,
+				Staged: 3, Modified: 2, Untracked: 1, Conflicted: 1,
+				Files: []FileStatus{
+					{Code: "M ", Path: "staged.go"},
+					{Code: " M", Path: "modified.go"},
+					{Code: "MM", Path: "both.go"},
+					{Code: "R ", Path: "new.go"},
+					{Code: "UU", Path: "conflict.go"},
+					{Code: "??", Path: "my notes.txt"},
+				},
+			},
+		},
+		{
+			name:        "Detached HEAD",
+			input:       "# branch.oid abc123\n# branch.head (detached)\n",
+			expected:    Status{},
+			expectClean: true,
+		},
+		{
+			name:        "Malformed divergence",
+			input:       "# branch.ab +x -1\n",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := parseStatus(tt.input)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseStatus() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+
+			if !reflect.DeepEqual(*status, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *status)
+			}
+			if status.Clean() != tt.expectClean {
+				t.Errorf("Clean() = %v, want %v", status.Clean(), tt.expectClean)
+			}
+		})
+	}
+}
+
+func TestManagerStatus(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+
+	status, err := m.Status(context.Background(), repoDir)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Branch != "main" || !status.Clean() {
+		t.Errorf("Expected clean main, got %+v", status)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, "notes.txt"), []byte("untracked\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	status, err = m.Status(context.Background(), repoDir)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Untracked != 1 || status.Clean() {
+		t.Errorf("Expected one untracked file, got %+v", status)
+	}
+
+	if _, err := m.Status(context.Background(), "/tmp/x;rm -rf /"); err == nil {
+		t.Error("Expected invalid path to be rejected")
+	}
+}
+
+func TestManagerDiff(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+	ctx := context.Background()
+
+	if diff, err := m.Diff(ctx, repoDir, DiffOptions{}); err != nil || diff != "" {
+		t.Fatalf("Expected empty diff for a clean worktree, got %q, %v", diff, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	diff, err := m.Diff(ctx, repoDir, DiffOptions{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !strings.Contains(diff, "-hello") || !strings.Contains(diff, "+changed") {
+		t.Errorf("Expected patch for README.md, got %q", diff)
+	}
+
+	stat, err := m.Diff(ctx, repoDir, DiffOptions{Base: "main", Stat: true})
+	if err != nil {
+		t.Fatalf("Diff with stat failed: %v", err)
+	}
+	if !strings.Contains(stat, "README.md") || strings.Contains(stat, "+changed") {
+		t.Errorf("Expected per-file summary, got %q", stat)
+	}
+
+	if _, err := m.Diff(ctx, repoDir, DiffOptions{Base: "--output=/tmp/x"}); err == nil {
+		t.Error("Expected option-like base to be rejected")
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	output := "abc1234\x00Ada\x001700000000\x00Fix: handle a\x00b\nbad line\ndef5678\x00Bob\x001600000000\x00Initial\n"
+
+	commits := parseLog(output)
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 commits, got %+v", commits)
+	}
+	if commits[0].Hash != "abc1234" || commits[0].Author != "Ada" || commits[0].Subject != "Fix: handle a\x00b" || commits[0].Date.Unix() != 1700000000 {
+		t.Errorf("Unexpected first commit: %+v", commits[0])
+	}
+	if commits[1].Subject != "Initial" {
+		t.Errorf("Unexpected second commit: %+v", commits[1])
+	}
+}
+
+func TestManagerLogAndMergeBase(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+	ctx := context.Background()
+
+	initial := runTestGit(t, repoDir, "rev-parse", "HEAD")
+	runTestGit(t, repoDir, "branch", "base")
+	runTestGit(t, repoDir, "commit", "-q", "--allow-empty", "-m", "second")
+
+	commits, err := m.Log(ctx, repoDir, 5)
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "second" || commits[1].Subject != "initial" {
+		t.Errorf("Expected newest commit first, got %+v", commits)
+	}
+	if commits, _ := m.Log(ctx, repoDir, 1); len(commits) != 1 {
+		t.Errorf("Expected limit to be applied, got %+v", commits)
+	}
+
+	mergeBase, err := m.MergeBase(ctx, repoDir, "base")
+	if err != nil || mergeBase != initial {
+		t.Errorf("Expected merge base %s, got %q, %v", initial, mergeBase, err)
+	}
+	if _, err := m.MergeBase(ctx, repoDir, "missing"); err == nil {
+		t.Error("Expected unknown ref to fail")
+	}
+}
+
+func TestManagerCommitTimes(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+	ctx := context.Background()
+
+	initial := runTestGit(t, repoDir, "rev-parse", "HEAD")
+	runTestGit(t, repoDir, "commit", "-q", "--allow-empty", "-m", "second")
+	second := runTestGit(t, repoDir, "rev-parse", "HEAD")
+
+	times, err := m.CommitTimes(ctx, []string{initial, "", second, initial})
+	if err != nil {
+		t.Fatalf("CommitTimes failed: %v", err)
+	}
+	if len(times) != 2 || times[initial].IsZero() || times[second].IsZero() {
+		t.Errorf("Expected dates of both commits, got %v", times)
+	}
+
+	if times, err := m.CommitTimes(ctx, nil); err != nil || len(times) != 0 {
+		t.Errorf("Expected no dates without commits, got %v, %v", times, err)
+	}
+	if _, err := m.CommitTimes(ctx, []string{"0000000000000000000000000000000000000000"}); err == nil {
+		t.Error("Expected unknown commit to fail")
+	}
+	if _, err := m.CommitTimes(ctx, []string{"--output=x"}); err == nil {
+		t.Error("Expected option-like commit to be rejected")
+	}
+}
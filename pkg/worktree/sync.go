@@ -0,0 +1,143 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SyncOutcome describes what Sync did to a worktree
+type SyncOutcome string
+
+const (
+	SyncUpdated    SyncOutcome = "updated"     // fast-forwarded or rebased
+	SyncUpToDate   SyncOutcome = "up to date"  // nothing to do
+	SyncDiverged   SyncOutcome = "diverged"    // has local and upstream commits, left alone
+	SyncDirty      SyncOutcome = "dirty"       // has uncommitted changes, skipped
+	SyncNoUpstream SyncOutcome = "no upstream" // branch does not track a remote branch
+	SyncDetached   SyncOutcome = "detached"    // HEAD is detached, skipped
+	SyncConflict   SyncOutcome = "conflict"    // rebase conflicted and was aborted
+	SyncLocked     SyncOutcome = "locked"      // worktree is locked; for callers that skip it
+)
+
+// SyncOptions controls how Sync updates a worktree
+type SyncOptions struct {
+	RebaseOnto string // also rebase the branch onto this ref when set
+}
+
+// SyncResult reports the outcome of Sync for one worktree
+type SyncResult struct {
+	Outcome SyncOutcome
+	Pulled  int  // upstream commits fast-forwarded onto the branch
+	Rebased bool // whether the branch was rebased onto opts.RebaseOnto
+}
+
+// Fetch updates the remote-tracking branches of remote, pruning deleted ones
+func (m *manager) Fetch(ctx context.Context, remote string) error {
+	// Validate input for security
+	if err := validateBranchName(remote); err != nil {
+		return fmt.Errorf("invalid remote: %w", err)
+	}
+
+	if output, err := m.gitCombined(ctx, "fetch", "--quiet", "--prune", remote); err != nil {
+		return fmt.Errorf("failed to fetch from '%s': %s", remote, gitMessage(output))
+	}
+	return nil
+}
+
+// DefaultBranch returns the remote-tracking branch remote's HEAD points to,
+// e.g. "origin/main"
+func (m *manager) DefaultBranch(ctx context.Context, remote string) (string, error) {
+	// Validate input for security
+	if err := validateBranchName(remote); err != nil {
+		return "", fmt.Errorf("invalid remote: %w", err)
+	}
+
+	output, err := m.gitOutput(ctx, m.repoRoot, nil, "symbolic-ref", "--quiet", "--short", "refs/remotes/"+remote+"/HEAD")
+	if err != nil {
+		return "", fmt.Errorf("default branch of '%s' is unknown (set it with 'git remote set-head %s --auto')", remote, remote)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Sync brings the branch checked out at path up to date: it is fast-forwarded
+// to its upstream when it is clean and only behind, then rebased onto
+// opts.RebaseOnto when set. Worktrees that cannot be updated safely are left
+// as they are and reported through the outcome. Fetch first to see new commits.
+func (m *manager) Sync(ctx context.Context, path string, opts SyncOptions) (*SyncResult, error) {
+	// Validate inputs for security
+	if err := validatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	if opts.RebaseOnto != "" {
+		if err := validateBranchName(opts.RebaseOnto); err != nil {
+			return nil, fmt.Errorf("invalid base: %w", err)
+		}
+	}
+
+	status, err := m.Status(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case status.Branch == "":
+		return &SyncResult{Outcome: SyncDetached}, nil
+	case !status.Clean():
+		return &SyncResult{Outcome: SyncDirty}, nil
+	case status.Ahead > 0 && status.Behind > 0:
+		return &SyncResult{Outcome: SyncDiverged}, nil
+	case status.Upstream == "" && opts.RebaseOnto == "":
+		return &SyncResult{Outcome: SyncNoUpstream}, nil
+	}
+
+	result := &SyncResult{Outcome: SyncUpToDate}
+	if status.Behind > 0 {
+		if _, err := m.gitOutput(ctx, path, nil, "merge", "--ff-only", "--quiet", "@{upstream}"); err != nil {
+			return nil, fmt.Errorf("failed to fast-forward '%s': %w", status.Branch, err)
+		}
+		result.Outcome, result.Pulled = SyncUpdated, status.Behind
+	}
+
+	if opts.RebaseOnto != "" {
+		rebased, err := m.rebase(ctx, path, status.Branch, opts.RebaseOnto)
+		if err != nil {
+			return nil, err
+		}
+		if rebased == SyncConflict {
+			result.Outcome = SyncConflict
+		} else if rebased == SyncUpdated {
+			result.Outcome, result.Rebased = SyncUpdated, true
+		}
+	}
+	return result, nil
+}
+
+// rebase rebases the branch at path onto base, aborting when it conflicts,
+// and reports whether the branch moved
+func (m *manager) rebase(ctx context.Context, path, branch, base string) (SyncOutcome, error) {
+	before, err := m.gitOutput(ctx, path, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD of '%s': %w", branch, err)
+	}
+
+	// git reports conflicts on stdout and the failed pick on stderr
+	output, err := m.gitOutput(ctx, path, nil, "rebase", "--quiet", base)
+	if err != nil {
+		if strings.Contains(output, "CONFLICT") || strings.Contains(err.Error(), "could not apply") {
+			if _, abortErr := m.gitOutput(ctx, path, nil, "rebase", "--abort"); abortErr != nil {
+				return "", fmt.Errorf("%w, and aborting the rebase failed: %v", &BranchError{Branch: branch, Err: ErrMergeConflict}, abortErr)
+			}
+			return SyncConflict, nil
+		}
+		return "", fmt.Errorf("failed to rebase '%s' onto '%s': %w", branch, base, err)
+	}
+
+	after, err := m.gitOutput(ctx, path, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD of '%s': %w", branch, err)
+	}
+	if before == after {
+		return SyncUpToDate, nil
+	}
+	return SyncUpdated, nil
+}
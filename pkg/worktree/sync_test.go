@@ -0,0 +1,153 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initTestClone clones a fresh test repository and returns the upstream
+// repository and the clone
+func initTestClone(t *testing.T) (string, string) {
+	t.Helper()
+
+	upstream := initTestRepo(t)
+	clone := filepath.Join(filepath.Dir(upstream), "clone")
+	runTestGit(t, filepath.Dir(upstream), "clone", "-q", upstream, clone)
+	runTestGit(t, clone, "config", "user.name", "Yosegi Test")
+	runTestGit(t, clone, "config", "user.email", "test@example.com")
+	return upstream, clone
+}
+
+// commitFile writes content to name in dir and commits it
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runTestGit(t, dir, "add", name)
+	runTestGit(t, dir, "commit", "-q", "-m", "update "+name)
+}
+
+func TestManagerSyncFastForward(t *testing.T) {
+	upstream, clone := initTestClone(t)
+	m := &manager{repoRoot: clone}
+	ctx := context.Background()
+
+	if branch, err := m.DefaultBranch(ctx, "origin"); err != nil || branch != "origin/main" {
+		t.Errorf("Expected default branch origin/main, got %q, %v", branch, err)
+	}
+	if _, err := m.DefaultBranch(ctx, "missing"); err == nil {
+		t.Error("Expected unknown remote to have no default branch")
+	}
+
+	commitFile(t, upstream, "a.txt", "a\n")
+	commitFile(t, upstream, "b.txt", "b\n")
+	if err := m.Fetch(ctx, "origin"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if err := m.Fetch(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected fetch from unknown remote to fail, got %v", err)
+	}
+
+	result, err := m.Sync(ctx, clone, SyncOptions{})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.Outcome != SyncUpdated || result.Pulled != 2 || result.Rebased {
+		t.Errorf("Expected fast-forward of 2 commits, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(clone, "b.txt")); err != nil {
+		t.Errorf("Expected upstream file after sync: %v", err)
+	}
+
+	if result, err := m.Sync(ctx, clone, SyncOptions{}); err != nil || result.Outcome != SyncUpToDate {
+		t.Errorf("Expected up to date, got %+v, %v", result, err)
+	}
+
+	// Uncommitted changes stop the update even when the branch is behind
+	commitFile(t, upstream, "c.txt", "c\n")
+	if err := m.Fetch(ctx, "origin"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(clone, "README.md"), []byte("local\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	if result, err := m.Sync(ctx, clone, SyncOptions{}); err != nil || result.Outcome != SyncDirty {
+		t.Errorf("Expected dirty, got %+v, %v", result, err)
+	}
+	runTestGit(t, clone, "checkout", "--", "README.md")
+
+	// A local commit on top makes the branch diverge from its upstream
+	commitFile(t, clone, "local.txt", "local\n")
+	if result, err := m.Sync(ctx, clone, SyncOptions{}); err != nil || result.Outcome != SyncDiverged {
+		t.Errorf("Expected diverged, got %+v, %v", result, err)
+	}
+}
+
+func TestManagerSyncRebase(t *testing.T) {
+	upstream, clone := initTestClone(t)
+	m := &manager{repoRoot: clone}
+	ctx := context.Background()
+
+	featurePath := filepath.Join(filepath.Dir(clone), "feature")
+	if err := m.Create(ctx, CreateOptions{Path: featurePath, Branch: "feature", CreateBranch: true}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	commitFile(t, featurePath, "feature.txt", "feature\n")
+
+	detachedPath := filepath.Join(filepath.Dir(clone), "detached")
+	runTestGit(t, clone, "worktree", "add", "-q", "--detach", detachedPath)
+	if result, err := m.Sync(ctx, detachedPath, SyncOptions{}); err != nil || result.Outcome != SyncDetached {
+		t.Errorf("Expected detached, got %+v, %v", result, err)
+	}
+
+	if result, err := m.Sync(ctx, featurePath, SyncOptions{}); err != nil || result.Outcome != SyncNoUpstream {
+		t.Errorf("Expected no upstream, got %+v, %v", result, err)
+	}
+
+	commitFile(t, upstream, "a.txt", "a\n")
+	if err := m.Fetch(ctx, "origin"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	result, err := m.Sync(ctx, featurePath, SyncOptions{RebaseOnto: "origin/main"})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.Outcome != SyncUpdated || !result.Rebased {
+		t.Errorf("Expected rebase, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(featurePath, "a.txt")); err != nil {
+		t.Errorf("Expected upstream file after rebase: %v", err)
+	}
+
+	if result, err := m.Sync(ctx, featurePath, SyncOptions{RebaseOnto: "origin/main"}); err != nil || result.Outcome != SyncUpToDate {
+		t.Errorf("Expected up to date, got %+v, %v", result, err)
+	}
+
+	// Conflicting changes abort the rebase and leave the branch alone
+	commitFile(t, featurePath, "README.md", "feature\n")
+	commitFile(t, upstream, "README.md", "upstream\n")
+	if err := m.Fetch(ctx, "origin"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	before := runTestGit(t, featurePath, "rev-parse", "HEAD")
+
+	result, err = m.Sync(ctx, featurePath, SyncOptions{RebaseOnto: "origin/main"})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.Outcome != SyncConflict {
+		t.Errorf("Expected conflict, got %+v", result)
+	}
+	if after := runTestGit(t, featurePath, "rev-parse", "HEAD"); after != before {
+		t.Errorf("Expected branch to be left at %s, got %s", before, after)
+	}
+	if status := runTestGit(t, featurePath, "status", "--porcelain"); status != "" {
+		t.Errorf("Expected clean worktree after aborted rebase, got %q", status)
+	}
+}
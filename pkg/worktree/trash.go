@@ -0,0 +1,306 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// trashRefPrefix is the ref namespace holding worktree snapshots
+const trashRefPrefix = "refs/yosegi/trash/"
+
+// Trailer keys recorded in the message of each snapshot commit
+const (
+	trashPathTrailer   = "Yosegi-Path"
+	trashBranchTrailer = "Yosegi-Branch"
+	trashHeadTrailer   = "Yosegi-Head"
+)
+
+// validTrashID matches the identifiers generated by Trash
+var validTrashID = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+// TrashEntry represents a snapshot of a removed worktree
+type TrashEntry struct {
+	ID        string
+	Path      string
+	Branch    string // empty for a detached HEAD
+	Head      string // commit the worktree had checked out
+	Snapshot  string // commit holding the full worktree contents
+	CreatedAt time.Time
+}
+
+// Ref returns the git ref that keeps the snapshot reachable
+func (e TrashEntry) Ref() string {
+	return trashRefPrefix + e.ID
+}
+
+// Trash snapshots a worktree's branch tip and uncommitted changes (including
+// untracked files) into a commit stored under refs/yosegi/trash/
+func (m *manager) Trash(ctx context.Context, wt Worktree) (*TrashEntry, error) {
+	// Validate input for security
+	if err := validatePath(wt.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	head, err := m.gitOutput(ctx, wt.Path, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD of worktree: %w", err)
+	}
+	head = strings.TrimSpace(head)
+
+	// Build the snapshot tree in a throwaway index so the worktree's own
+	// index and files are left untouched. It starts from HEAD so that
+	// tracked files matching .gitignore, which add --all skips, are kept.
+	tmpDir, err := os.MkdirTemp("", "yosegi-trash-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir) // Ignore cleanup errors
+	}()
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmpDir, "index")}
+
+	if _, err := m.gitOutput(ctx, wt.Path, env, "read-tree", head); err != nil {
+		return nil, fmt.Errorf("failed to read HEAD into temporary index: %w", err)
+	}
+	if _, err := m.gitOutput(ctx, wt.Path, env, "add", "--all"); err != nil {
+		return nil, fmt.Errorf("failed to stage worktree contents: %w", err)
+	}
+	tree, err := m.gitOutput(ctx, wt.Path, env, "write-tree")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write snapshot tree: %w", err)
+	}
+
+	branch := wt.Branch
+	if branch == "(detached)" || branch == "(bare)" {
+		branch = ""
+	}
+
+	subject := branch
+	if subject == "" {
+		subject = filepath.Base(wt.Path)
+	}
+	message := fmt.Sprintf("yosegi trash: %s\n\n%s: %s\n%s: %s\n%s: %s\n",
+		subject,
+		trashPathTrailer, wt.Path,
+		trashBranchTrailer, branch,
+		trashHeadTrailer, head,
+	)
+
+	// Snapshots are internal objects, so use a fixed identity rather than
+	// failing when user.name/user.email are not configured
+	identity := []string{
+		"GIT_AUTHOR_NAME=yosegi", "GIT_AUTHOR_EMAIL=yosegi@localhost",
+		"GIT_COMMITTER_NAME=yosegi", "GIT_COMMITTER_EMAIL=yosegi@localhost",
+	}
+	snapshot, err := m.gitOutput(ctx, m.repoRoot, identity, "commit-tree", strings.TrimSpace(tree), "-p", head, "-m", message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot commit: %w", err)
+	}
+	snapshot = strings.TrimSpace(snapshot)
+
+	now := time.Now()
+	entry := &TrashEntry{
+		ID:        fmt.Sprintf("%s-%s", now.UTC().Format("20060102-150405"), snapshot[:7]),
+		Path:      wt.Path,
+		Branch:    branch,
+		Head:      head,
+		Snapshot:  snapshot,
+		CreatedAt: now,
+	}
+
+	if _, err := m.gitOutput(ctx, m.repoRoot, nil, "update-ref", entry.Ref(), snapshot); err != nil {
+		return nil, fmt.Errorf("failed to store snapshot ref: %w", err)
+	}
+
+	return entry, nil
+}
+
+// ListTrash returns all trashed worktree snapshots, newest first
+func (m *manager) ListTrash(ctx context.Context) ([]TrashEntry, error) {
+	output, err := m.gitOutput(ctx, m.repoRoot, nil, "for-each-ref", "--sort=-creatordate",
+		"--format=%(refname)%1f%(objectname)%1f%(creatordate:unix)%1f%(contents)%1e",
+		trashRefPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	return parseTrashList(output)
+}
+
+// RestoreTrash recreates a trashed worktree and reapplies its uncommitted
+// changes. The original path is used when path is empty.
+func (m *manager) RestoreTrash(ctx context.Context, id, path string) (*TrashEntry, error) {
+	entry, err := m.findTrash(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		path = entry.Path
+	}
+	if err := validatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("path '%s' already exists", path)
+	}
+
+	args := []string{"worktree", "add"}
+	switch {
+	case entry.Branch == "":
+		args = append(args, "--detach", path, entry.Head)
+	case m.branchExists(ctx, entry.Branch):
+		args = append(args, path, entry.Branch)
+	default:
+		// The branch was deleted along with the worktree, recreate it at the old tip
+		args = append(args, "-b", entry.Branch, path, entry.Head)
+	}
+
+	if _, err := m.gitOutput(ctx, m.repoRoot, nil, args...); err != nil {
+		return nil, fmt.Errorf("failed to recreate worktree (command: git %v): %w", args, err)
+	}
+
+	// Replay the snapshot as a patch on top of whatever the branch points to
+	// now, leaving the changes unstaged like they were before removal
+	patch, err := m.gitOutput(ctx, m.repoRoot, nil, "diff", "--binary", entry.Head, entry.Snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute snapshot changes: %w", err)
+	}
+	if strings.TrimSpace(patch) != "" {
+		if err := m.applyPatch(ctx, path, patch); err != nil {
+			return nil, fmt.Errorf("worktree recreated at '%s' but changes could not be reapplied (snapshot kept as %s): %w", path, entry.Ref(), err)
+		}
+	}
+
+	if err := m.DeleteTrash(ctx, entry.ID); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// DeleteTrash permanently removes a trashed worktree snapshot
+func (m *manager) DeleteTrash(ctx context.Context, id string) error {
+	if err := validateTrashID(id); err != nil {
+		return fmt.Errorf("invalid trash id: %w", err)
+	}
+
+	if _, err := m.gitOutput(ctx, m.repoRoot, nil, "update-ref", "-d", trashRefPrefix+id); err != nil {
+		return fmt.Errorf("failed to delete trash entry '%s': %w", id, err)
+	}
+	return nil
+}
+
+// findTrash looks up a single trash entry by its ID
+func (m *manager) findTrash(ctx context.Context, id string) (*TrashEntry, error) {
+	if err := validateTrashID(id); err != nil {
+		return nil, fmt.Errorf("invalid trash id: %w", err)
+	}
+
+	entries, err := m.ListTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("trash entry '%s' not found", id)
+}
+
+// branchExists reports whether a local branch exists
+func (m *manager) branchExists(ctx context.Context, branch string) bool {
+	_, err := m.gitOutput(ctx, m.repoRoot, nil, "rev-parse", "--verify", "--quiet", fmt.Sprintf("refs/heads/%s", branch))
+	return err == nil
+}
+
+// validateTrashID ensures the trash ID is safe to embed in a ref name
+func validateTrashID(id string) error {
+	if id == "" {
+		return fmt.Errorf("trash id cannot be empty")
+	}
+	if !validTrashID.MatchString(id) || strings.HasPrefix(id, "-") {
+		return fmt.Errorf("trash id contains invalid characters")
+	}
+	return nil
+}
+
+// applyPatch applies a binary diff to the working tree at dir
+func (m *manager) applyPatch(ctx context.Context, dir, patch string) error {
+	patchFile, err := os.CreateTemp("", "yosegi-restore-*.patch")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(patchFile.Name()) // Ignore cleanup errors
+	}()
+
+	if _, err := patchFile.WriteString(patch); err != nil {
+		_ = patchFile.Close()
+		return err
+	}
+	if err := patchFile.Close(); err != nil {
+		return err
+	}
+
+	_, err = m.gitOutput(ctx, dir, nil, "apply", "--whitespace=nowarn", patchFile.Name())
+	return err
+}
+
+// parseTrashList parses the output of 'git for-each-ref' over the trash namespace
+func parseTrashList(output string) ([]TrashEntry, error) {
+	var entries []TrashEntry
+
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, "\x1f", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("malformed trash entry: %q", record)
+		}
+
+		unix, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse trash timestamp: %w", err)
+		}
+
+		entry := TrashEntry{
+			ID:        strings.TrimPrefix(fields[0], trashRefPrefix),
+			Snapshot:  fields[1],
+			CreatedAt: time.Unix(unix, 0),
+		}
+
+		for _, line := range strings.Split(fields[3], "\n") {
+			key, value, found := strings.Cut(line, ": ")
+			if !found {
+				continue
+			}
+			switch key {
+			case trashPathTrailer:
+				entry.Path = value
+			case trashBranchTrailer:
+				entry.Branch = value
+			case trashHeadTrailer:
+				entry.Head = value
+			}
+		}
+
+		if entry.Head == "" {
+			return nil, fmt.Errorf("trash entry '%s' is missing its head commit", entry.ID)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,273 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initTestRepo creates a real git repository with a single commit on main
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "yosegi-test-repo-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(tmpDir) // Ignore cleanup errors
+	})
+
+	// Resolve symlinks (e.g. /tmp on macOS) so paths match git's output
+	repoDir, err := filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to resolve temp dir: %v", err)
+	}
+	repoDir = filepath.Join(repoDir, "repo")
+	if err := os.Mkdir(repoDir, 0755); err != nil {
+		t.Fatalf("Failed to create repo dir: %v", err)
+	}
+
+	runTestGit(t, repoDir, "init", "-q", "-b", "main")
+	runTestGit(t, repoDir, "config", "user.name", "Yosegi Test")
+	runTestGit(t, repoDir, "config", "user.email", "test@example.com")
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runTestGit(t, repoDir, "add", "README.md")
+	runTestGit(t, repoDir, "commit", "-q", "-m", "initial")
+
+	return repoDir
+}
+
+// runTestGit runs a git command for test setup and fails the test on error
+func runTestGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestParseTrashList(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    []TrashEntry
+		expectError bool
+	}{
+		{
+			name:     "Empty output",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name: "Single entry",
+			input: "refs/yosegi/trash/20250101-120000-abc1234\x1fabc1234def\x1f1735732800\x1f" +
+				"yosegi trash: feature/x\n\nYosegi-Path: /tmp/wt\nYosegi-Branch: feature/x\nYosegi-Head: 1111\n\x1e\n",
+			expected: []TrashEntry{
+				{ID: "20250101-120000-abc1234", Path: "/tmp/wt", Branch: "feature/x", Head: "1111", Snapshot: "abc1234def"},
+			},
+		},
+		{
+			name: "Detached entry",
+			input: "refs/yosegi/trash/a\x1fsha\x1f1\x1f" +
+				"yosegi trash: wt\n\nYosegi-Path: /tmp/wt\nYosegi-Branch: \nYosegi-Head: 2222\n\x1e\n",
+			expected: []TrashEntry{
+				{ID: "a", Path: "/tmp/wt", Branch: "", Head: "2222", Snapshot: "sha"},
+			},
+		},
+		{
+			name:        "Malformed record",
+			input:       "refs/yosegi/trash/a\x1fsha\x1e\n",
+			expectError: true,
+		},
+		{
+			name:        "Missing head trailer",
+			input:       "refs/yosegi/trash/a\x1fsha\x1f1\x1fyosegi trash: wt\n\x1e\n",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parseTrashList(tt.input)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseTrashList() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+
+			if len(entries) != len(tt.expected) {
+				t.Fatalf("Expected %d entries, got %d", len(tt.expected), len(entries))
+			}
+			for i, expected := range tt.expected {
+				actual := entries[i]
+				if actual.ID != expected.ID || actual.Path != expected.Path ||
+					actual.Branch != expected.Branch || actual.Head != expected.Head ||
+					actual.Snapshot != expected.Snapshot {
+					t.Errorf("Entry %d: expected %+v, got %+v", i, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTrashID(t *testing.T) {
+	valid := []string{"20250101-120000-abc1234", "abc"}
+	invalid := []string{"", "-abc", "../etc", "a b", "a;b", "refs/heads/main"}
+
+	for _, id := range valid {
+		if err := validateTrashID(id); err != nil {
+			t.Errorf("Expected '%s' to be valid, got: %v", id, err)
+		}
+	}
+	for _, id := range invalid {
+		if err := validateTrashID(id); err == nil {
+			t.Errorf("Expected '%s' to be invalid", id)
+		}
+	}
+}
+
+func TestManagerTrashRoundTrip(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+
+	wtPath := filepath.Join(filepath.Dir(repoDir), "feature")
+	if err := m.Create(context.Background(), CreateOptions{Path: wtPath, Branch: "feature", CreateBranch: true}); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+
+	// Leave a modified tracked file and an untracked file behind
+	if err := os.WriteFile(filepath.Join(wtPath, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(wtPath, "notes.txt"), []byte("untracked\n"), 0644); err != nil {
+		t.Fatalf("Failed to write untracked file: %v", err)
+	}
+
+	entry, err := m.Trash(context.Background(), Worktree{Path: wtPath, Branch: "feature"})
+	if err != nil {
+		t.Fatalf("Trash failed: %v", err)
+	}
+	if entry.Branch != "feature" || entry.Path != wtPath {
+		t.Errorf("Unexpected trash entry: %+v", entry)
+	}
+
+	// Trashing must not touch the worktree itself
+	if status := runTestGit(t, wtPath, "status", "--porcelain"); !strings.Contains(status, "notes.txt") {
+		t.Errorf("Expected worktree to be untouched, got status: %s", status)
+	}
+
+	if err := m.Remove(context.Background(), wtPath, RemoveOptions{Force: true}); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := m.DeleteBranch(context.Background(), "feature", true); err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+
+	entries, err := m.ListTrash(context.Background())
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != entry.ID {
+		t.Fatalf("Expected trash to contain %s, got %+v", entry.ID, entries)
+	}
+
+	if _, err := m.RestoreTrash(context.Background(), entry.ID, ""); err != nil {
+		t.Fatalf("RestoreTrash failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(wtPath, "README.md"))
+	if err != nil || string(content) != "changed\n" {
+		t.Errorf("Expected modified README.md to be restored, got %q (err: %v)", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(wtPath, "notes.txt")); err != nil {
+		t.Errorf("Expected untracked file to be restored: %v", err)
+	}
+	if branch := runTestGit(t, wtPath, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feature" {
+		t.Errorf("Expected restored worktree on branch 'feature', got '%s'", branch)
+	}
+
+	entries, err = m.ListTrash(context.Background())
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected trash entry to be consumed by restore, got %+v", entries)
+	}
+}
+
+func TestManagerTrashKeepsIgnoredTrackedFiles(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+
+	// A file committed with add --force that matches an ignore rule
+	if err := os.WriteFile(filepath.Join(repoDir, ".gitignore"), []byte("build/\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .gitignore: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repoDir, "build"), 0755); err != nil {
+		t.Fatalf("Failed to create build dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "build", "tracked.txt"), []byte("kept\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runTestGit(t, repoDir, "add", ".gitignore")
+	runTestGit(t, repoDir, "add", "--force", "build/tracked.txt")
+	runTestGit(t, repoDir, "commit", "-q", "-m", "force-add build output")
+
+	wtPath := filepath.Join(filepath.Dir(repoDir), "feature")
+	if err := m.Create(context.Background(), CreateOptions{Path: wtPath, Branch: "feature", CreateBranch: true}); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(wtPath, "build", "tracked.txt"), []byte("changed\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+
+	entry, err := m.Trash(context.Background(), Worktree{Path: wtPath, Branch: "feature"})
+	if err != nil {
+		t.Fatalf("Trash failed: %v", err)
+	}
+	if err := m.Remove(context.Background(), wtPath, RemoveOptions{Force: true}); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := m.DeleteBranch(context.Background(), "feature", true); err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+	if _, err := m.RestoreTrash(context.Background(), entry.ID, ""); err != nil {
+		t.Fatalf("RestoreTrash failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(wtPath, "build", "tracked.txt"))
+	if err != nil || string(content) != "changed\n" {
+		t.Errorf("Expected the ignored tracked file to be restored with its change, got %q (err: %v)", content, err)
+	}
+	if status := runTestGit(t, wtPath, "status", "--porcelain"); status != "M build/tracked.txt" {
+		t.Errorf("Expected only the modification in status, got %q", status)
+	}
+}
+
+func TestManagerRestoreTrashErrors(t *testing.T) {
+	repoDir := initTestRepo(t)
+	m := &manager{repoRoot: repoDir}
+
+	if _, err := m.RestoreTrash(context.Background(), "missing", ""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+
+	if _, err := m.RestoreTrash(context.Background(), "../bad", ""); err == nil || !strings.Contains(err.Error(), "invalid trash id") {
+		t.Errorf("Expected invalid trash id error, got: %v", err)
+	}
+}
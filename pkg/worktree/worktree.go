@@ -0,0 +1,625 @@
+package worktree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Worktree represents a git worktree
+type Worktree struct {
+	Path           string
+	Branch         string
+	Commit         string
+	IsCurrent      bool
+	Bare           bool // the repository itself in a bare layout, not a checkout
+	Locked         bool
+	LockReason     string
+	Prunable       bool
+	PrunableReason string
+}
+
+// Manager handles git worktree operations. Methods that run git take a
+// context; cancelling it stops the git process.
+type Manager interface {
+	List(ctx context.Context) ([]Worktree, error)
+	Create(ctx context.Context, opts CreateOptions) error
+	Remove(ctx context.Context, path string, opts RemoveOptions) error
+	Move(ctx context.Context, oldPath, newPath string, opts MoveOptions) error
+	Lock(ctx context.Context, path, reason string) error
+	Unlock(ctx context.Context, path string) error
+	Status(ctx context.Context, path string) (*Status, error)
+	Diff(ctx context.Context, path string, opts DiffOptions) (string, error)
+	Log(ctx context.Context, path string, limit int) ([]Commit, error)
+	MergeBase(ctx context.Context, path, ref string) (string, error)
+	CommitTimes(ctx context.Context, commits []string) (map[string]time.Time, error)
+	DiffStat(ctx context.Context, path, base string) (*DiffStat, error)
+	CommitAll(ctx context.Context, path, message string) (bool, error)
+	Merge(ctx context.Context, path, branch string) error
+	ResolveCommit(ctx context.Context, ref string) (string, error)
+	GitDir(ctx context.Context, path string) (string, error)
+	Fetch(ctx context.Context, remote string) error
+	DefaultBranch(ctx context.Context, remote string) (string, error)
+	Sync(ctx context.Context, path string, opts SyncOptions) (*SyncResult, error)
+	Prune(ctx context.Context, dryRun bool) ([]PruneEntry, error)
+	Repair(ctx context.Context, paths []string) ([]RepairEntry, error)
+	Diagnose(ctx context.Context) ([]WorktreeProblem, error)
+	RenameBranch(ctx context.Context, oldBranch, newBranch string) error
+	Rename(ctx context.Context, opts RenameOptions) (*RenameResult, error)
+	GetCurrentPath() (string, error)
+	MainWorktreePath() string
+	CommonDir() string
+	DeleteBranch(ctx context.Context, branch string, force bool) error
+	DropBranch(ctx context.Context, path, branch string, force bool) error
+	HasUnpushedCommits(ctx context.Context, branch string) (bool, int, error)
+	Trash(ctx context.Context, wt Worktree) (*TrashEntry, error)
+	ListTrash(ctx context.Context) ([]TrashEntry, error)
+	RestoreTrash(ctx context.Context, id, path string) (*TrashEntry, error)
+	DeleteTrash(ctx context.Context, id string) error
+}
+
+// CreateOptions describes a worktree to create
+type CreateOptions struct {
+	Path         string // directory for the new worktree
+	Branch       string // branch to check out
+	CreateBranch bool   // create Branch instead of checking out an existing one
+	Base         string // commit the new branch starts from, HEAD when empty
+}
+
+// RemoveOptions controls how a worktree is removed
+type RemoveOptions struct {
+	Force bool // remove even if the worktree has uncommitted changes
+}
+
+// MoveOptions controls how a worktree is moved
+type MoveOptions struct {
+	Force bool // move even if the worktree is locked
+}
+
+type manager struct {
+	repoRoot  string
+	commonDir string
+	runner    Runner
+	timeout   time.Duration // per git command, disabled when zero
+}
+
+// Security validation functions
+var (
+	// validBranchName matches valid git branch names
+	validBranchName = regexp.MustCompile(`^[a-zA-Z0-9._/-]+$`)
+	// dangerousShellChars contains characters that could be dangerous in shell commands
+	dangerousShellChars = []string{";", "&", "|", "$", "`", "(", ")", "<", ">", "\"", "'", "\\", "\n", "\r"}
+)
+
+// validateBranchName ensures the branch name is safe for use in git commands
+func validateBranchName(branch string) error {
+	if branch == "" {
+		return fmt.Errorf("branch name cannot be empty")
+	}
+
+	// Check for git-specific restrictions
+	if strings.HasPrefix(branch, "-") {
+		return fmt.Errorf("branch name cannot start with a dash")
+	}
+
+	if strings.HasPrefix(branch, ".") || strings.HasSuffix(branch, ".") {
+		return fmt.Errorf("branch name cannot start or end with a dot")
+	}
+
+	if strings.Contains(branch, "..") {
+		return fmt.Errorf("branch name cannot contain consecutive dots")
+	}
+
+	// Check for dangerous characters that could be interpreted as shell commands
+	for _, char := range dangerousShellChars {
+		if strings.Contains(branch, char) {
+			return fmt.Errorf("branch name contains dangerous character: %s", char)
+		}
+	}
+
+	// Ensure it matches the valid pattern
+	if !validBranchName.MatchString(branch) {
+		return fmt.Errorf("branch name contains invalid characters")
+	}
+
+	return nil
+}
+
+// validatePath ensures the path is safe for use in git commands
+func validatePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+
+	// Check for dangerous characters
+	for _, char := range dangerousShellChars {
+		if strings.Contains(path, char) {
+			return fmt.Errorf("path contains dangerous character: %s", char)
+		}
+	}
+
+	// Prevent malicious directory traversal attempts
+	// Check for patterns that could escape beyond the intended directory
+	if strings.Contains(path, "../../../") {
+		return fmt.Errorf("path contains directory traversal sequences")
+	}
+
+	// Convert to absolute path and check for suspicious patterns
+	absPath, err := filepath.Abs(path)
+	if err == nil {
+		// Check if the absolute path tries to access system directories
+		suspiciousPaths := []string{"/etc/", "/usr/bin/", "/bin/", "/sbin/", "/root/", "/home/root/"}
+		for _, suspicious := range suspiciousPaths {
+			if strings.HasPrefix(absPath, suspicious) {
+				return fmt.Errorf("path attempts to access restricted directory: %s", suspicious)
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewManager creates a new git worktree manager for the repository containing
+// the current directory, using the default Runner and timeout
+func NewManager(ctx context.Context) (Manager, error) {
+	return NewManagerAt(ctx, ".")
+}
+
+// NewManagerAt creates a manager for the repository containing dir, using the
+// default Runner and timeout
+func NewManagerAt(ctx context.Context, dir string) (Manager, error) {
+	repo, err := DiscoverRepository(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &manager{repoRoot: repo.Root, commonDir: repo.CommonDir, runner: DefaultRunner(), timeout: DefaultTimeout()}, nil
+}
+
+// NewManagerWithRunner creates a manager for the repository at repoRoot that
+// runs git through runner instead of discovering the repository itself
+func NewManagerWithRunner(repoRoot, commonDir string, runner Runner) Manager {
+	return &manager{repoRoot: repoRoot, commonDir: commonDir, runner: runner}
+}
+
+// FindGitRoot finds the git repository root directory by walking up from
+// startPath. It is the fallback used by DiscoverRepository.
+func FindGitRoot(startPath string) (string, error) {
+	path, err := filepath.Abs(startPath)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		gitDir := filepath.Join(path, ".git")
+		if info, err := os.Stat(gitDir); err == nil {
+			if info.IsDir() {
+				return path, nil // Regular git repository
+			}
+			// Handle worktree case - .git is a file pointing to the actual .git directory
+			content, err := os.ReadFile(gitDir)
+			if err != nil {
+				return "", err
+			}
+			if strings.HasPrefix(string(content), "gitdir:") {
+				// Extract the main repo path from the gitdir reference
+				gitdirPath := strings.TrimSpace(strings.TrimPrefix(string(content), "gitdir:"))
+				// Normalize the path for the current OS
+				gitdirPath = filepath.FromSlash(gitdirPath)
+				if !filepath.IsAbs(gitdirPath) {
+					// Relative gitdir paths are relative to the worktree directory
+					gitdirPath = filepath.Join(path, gitdirPath)
+				}
+				if filepath.IsAbs(gitdirPath) {
+					// Find the main repo from the worktree gitdir path
+					// e.g., /path/to/repo/.git/worktrees/branch -> /path/to/repo
+					// Find .git directory and get parent path
+					gitIndex := strings.Index(gitdirPath, string(filepath.Separator)+".git"+string(filepath.Separator))
+					if gitIndex != -1 {
+						return gitdirPath[:gitIndex], nil
+					}
+					// Handle case where .git is at the end
+					if strings.HasSuffix(gitdirPath, string(filepath.Separator)+".git") {
+						return strings.TrimSuffix(gitdirPath, string(filepath.Separator)+".git"), nil
+					}
+				}
+			}
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			break // Reached root directory
+		}
+		path = parent
+	}
+	return "", errors.New("not a git repository")
+}
+
+// List returns all worktrees in the repository
+func (m *manager) List(ctx context.Context) ([]Worktree, error) {
+	output, err := m.gitOutput(ctx, m.repoRoot, nil, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	return parseWorktreeList(output)
+}
+
+// Create adds a new worktree
+func (m *manager) Create(ctx context.Context, opts CreateOptions) error {
+	path, branch, createBranch := opts.Path, opts.Branch, opts.CreateBranch
+
+	// Validate inputs for security
+	if err := validatePath(path); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	if err := validateBranchName(branch); err != nil {
+		return fmt.Errorf("invalid branch name: %w", err)
+	}
+
+	if opts.Base != "" {
+		if !createBranch {
+			return fmt.Errorf("a base can only be given when creating a branch")
+		}
+		if err := validateBranchName(opts.Base); err != nil {
+			return fmt.Errorf("invalid base: %w", err)
+		}
+	}
+
+	// Check if branch exists
+	branchExists := m.branchExists(ctx, branch)
+
+	args := []string{"worktree", "add"}
+	if createBranch && !branchExists {
+		// Create new branch
+		args = append(args, "-b", branch, path)
+		if opts.Base != "" {
+			args = append(args, opts.Base)
+		}
+	} else if branchExists && !createBranch {
+		// Use existing branch
+		args = append(args, path, branch)
+	} else if !branchExists && !createBranch {
+		// Branch doesn't exist and user doesn't want to create it
+		return &BranchError{Branch: branch, Err: ErrBranchNotFound}
+	} else if branchExists && createBranch {
+		// Branch exists but user wants to create it
+		return &BranchError{Branch: branch, Err: ErrBranchExists}
+	}
+
+	// Get detailed error output for debugging
+	output, err := m.gitCombined(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to add worktree (command: git %v): %w\nOutput: %s", args, err, output)
+	}
+	return nil
+}
+
+// Remove removes a worktree
+func (m *manager) Remove(ctx context.Context, path string, opts RemoveOptions) error {
+	// Validate input for security
+	if err := validatePath(path); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	// First, try to get the absolute path
+	absPath, err := filepath.Abs(path)
+	if err == nil {
+		path = absPath
+	}
+
+	// The bare repository is listed like a worktree but holds every branch
+	lockReason := ""
+	if worktrees, err := m.List(ctx); err == nil {
+		for _, wt := range worktrees {
+			if wt.Path != path {
+				continue
+			}
+			if wt.Bare {
+				return fmt.Errorf("'%s' is the bare repository, not a worktree, and cannot be removed", path)
+			}
+			lockReason = wt.LockReason
+		}
+	}
+
+	args := []string{"worktree", "remove"}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	args = append(args, path)
+
+	// Get detailed error output for debugging
+	output, err := m.gitCombined(ctx, args...)
+	if err != nil {
+		errorMsg := output
+
+		// git runs with LC_ALL=C, so its messages can be matched reliably
+		if strings.Contains(errorMsg, "contains modified or untracked files") || strings.Contains(errorMsg, "is dirty") {
+			return &WorktreeError{Path: path, Err: ErrDirtyWorktree}
+		}
+
+		if strings.Contains(errorMsg, "locked working tree") {
+			return &WorktreeError{Path: path, Err: ErrLocked, Detail: lockReason}
+		}
+
+		if strings.Contains(errorMsg, "does not exist") {
+			// The directory was deleted or moved outside of git; cleaning up the
+			// stale entry is left to an explicit prune or repair
+			return &WorktreeError{Path: path, Err: ErrMissing, Detail: "its directory no longer exists"}
+		}
+
+		return fmt.Errorf("failed to remove worktree: %s", errorMsg)
+	}
+	return nil
+}
+
+// Move relocates a worktree to a new path. A relative newPath is taken from
+// the working directory, like any path given to the os package.
+func (m *manager) Move(ctx context.Context, oldPath, newPath string, opts MoveOptions) error {
+	// Validate inputs for security
+	if err := validatePath(oldPath); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	if err := validatePath(newPath); err != nil {
+		return fmt.Errorf("invalid destination path: %w", err)
+	}
+
+	// git runs in the repository root, so it is given the absolute path
+	destination, err := filepath.Abs(newPath)
+	if err != nil {
+		return fmt.Errorf("invalid destination path: %w", err)
+	}
+
+	// git would move the worktree inside an existing directory, so refuse instead
+	if _, err := os.Stat(destination); err == nil {
+		return fmt.Errorf("destination '%s' already exists", newPath)
+	}
+
+	args := []string{"worktree", "move"}
+	if opts.Force {
+		// git only moves a locked worktree when --force is given twice
+		args = append(args, "--force", "--force")
+	}
+	args = append(args, oldPath, destination)
+	// Get detailed error output for debugging
+	output, err := m.gitCombined(ctx, args...)
+	if err != nil {
+		errorMsg := output
+
+		if strings.Contains(errorMsg, "locked working tree") {
+			return &WorktreeError{Path: oldPath, Err: ErrLocked, Detail: lockReasonFromOutput(errorMsg)}
+		}
+
+		return fmt.Errorf("failed to move worktree: %s", errorMsg)
+	}
+	return nil
+}
+
+// Lock locks a worktree so it cannot be moved, removed or pruned
+func (m *manager) Lock(ctx context.Context, path, reason string) error {
+	// Validate input for security
+	if err := validatePath(path); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	if strings.ContainsAny(reason, "\r\n") {
+		return fmt.Errorf("lock reason must be a single line")
+	}
+
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	args = append(args, path)
+
+	output, err := m.gitCombined(ctx, args...)
+	if err != nil {
+		errorMsg := output
+		if strings.Contains(errorMsg, "already locked") {
+			return &WorktreeError{Path: path, Err: ErrLocked, Detail: "already locked"}
+		}
+		return fmt.Errorf("failed to lock worktree: %s", errorMsg)
+	}
+	return nil
+}
+
+// Unlock unlocks a locked worktree
+func (m *manager) Unlock(ctx context.Context, path string) error {
+	// Validate input for security
+	if err := validatePath(path); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	output, err := m.gitCombined(ctx, "worktree", "unlock", path)
+	if err != nil {
+		errorMsg := output
+		if strings.Contains(errorMsg, "not locked") {
+			return fmt.Errorf("worktree '%s' is not locked", path)
+		}
+		return fmt.Errorf("failed to unlock worktree: %s", errorMsg)
+	}
+	return nil
+}
+
+// lockReasonFromOutput extracts the reason from git's
+// "cannot move a locked working tree, lock reason: <reason>" message
+func lockReasonFromOutput(output string) string {
+	_, reason, found := strings.Cut(gitMessage(output), "lock reason: ")
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(reason)
+}
+
+// GetCurrentPath returns the current working directory
+func (m *manager) GetCurrentPath() (string, error) {
+	return os.Getwd()
+}
+
+// parseWorktreeList parses the output of 'git worktree list --porcelain'
+func parseWorktreeList(output string) ([]Worktree, error) {
+	var worktrees []Worktree
+	var current Worktree
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return worktrees, nil
+	}
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			// End of current worktree entry
+			if current.Path != "" {
+				worktrees = append(worktrees, current)
+				current = Worktree{}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "worktree ") {
+			current.Path = strings.TrimPrefix(line, "worktree ")
+		} else if strings.HasPrefix(line, "HEAD ") {
+			current.Commit = strings.TrimPrefix(line, "HEAD ")
+		} else if strings.HasPrefix(line, "branch ") {
+			current.Branch = strings.TrimPrefix(line, "branch refs/heads/")
+		} else if line == "bare" {
+			current.Branch = "(bare)"
+			current.Bare = true
+		} else if line == "detached" {
+			current.Branch = "(detached)"
+		} else if line == "locked" || strings.HasPrefix(line, "locked ") {
+			current.Locked = true
+			current.LockReason = strings.TrimSpace(strings.TrimPrefix(line, "locked"))
+		} else if line == "prunable" || strings.HasPrefix(line, "prunable ") {
+			current.Prunable = true
+			current.PrunableReason = strings.TrimSpace(strings.TrimPrefix(line, "prunable"))
+		}
+	}
+
+	// Add the last worktree if exists
+	if current.Path != "" {
+		worktrees = append(worktrees, current)
+	}
+
+	// Mark current worktree
+	currentPath, _ := os.Getwd()
+	for i := range worktrees {
+		if worktrees[i].Path == currentPath {
+			worktrees[i].IsCurrent = true
+			break
+		}
+	}
+
+	return worktrees, nil
+}
+
+// WithoutBare returns the worktrees that are real checkouts, dropping the entry
+// git reports for a bare repository
+func WithoutBare(worktrees []Worktree) []Worktree {
+	var result []Worktree
+	for _, wt := range worktrees {
+		if !wt.Bare {
+			result = append(result, wt)
+		}
+	}
+	return result
+}
+
+// DeleteBranch deletes a local branch
+func (m *manager) DeleteBranch(ctx context.Context, branch string, force bool) error {
+	// Validate input for security
+	if err := validateBranchName(branch); err != nil {
+		return fmt.Errorf("invalid branch name: %w", err)
+	}
+
+	args := []string{"branch", "-d"}
+	if force {
+		args[1] = "-D"
+	}
+	args = append(args, branch)
+
+	output, err := m.gitCombined(ctx, args...)
+	if err != nil {
+		errorMsg := output
+		if strings.Contains(errorMsg, "not found") {
+			return &BranchError{Branch: branch, Err: ErrBranchNotFound}
+		}
+		if strings.Contains(errorMsg, "not fully merged") {
+			return &BranchError{Branch: branch, Err: ErrBranchNotMerged}
+		}
+		return fmt.Errorf("failed to delete branch: %s", errorMsg)
+	}
+	return nil
+}
+
+// DropBranch deletes the branch checked out in the worktree at path but keeps
+// the worktree, detaching its HEAD at the same commit. When git refuses to
+// delete the branch, e.g. because it is not fully merged, it is checked out
+// again.
+func (m *manager) DropBranch(ctx context.Context, path, branch string, force bool) error {
+	// Validate inputs for security
+	if err := validatePath(path); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	if err := validateBranchName(branch); err != nil {
+		return fmt.Errorf("invalid branch name: %w", err)
+	}
+
+	if _, err := m.gitOutput(ctx, path, nil, "switch", "--quiet", "--detach"); err != nil {
+		return fmt.Errorf("failed to detach '%s': %w", path, err)
+	}
+
+	if err := m.DeleteBranch(ctx, branch, force); err != nil {
+		// The checkout must be restored even when the failure was a cancellation
+		if _, restoreErr := m.gitOutput(context.WithoutCancel(ctx), path, nil, "switch", "--quiet", branch); restoreErr != nil {
+			return fmt.Errorf("%w (checking it out again failed, HEAD is detached: %v)", err, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// HasUnpushedCommits checks if a branch has unpushed commits
+func (m *manager) HasUnpushedCommits(ctx context.Context, branch string) (bool, int, error) {
+	// Validate input for security
+	if err := validateBranchName(branch); err != nil {
+		return false, 0, fmt.Errorf("invalid branch name: %w", err)
+	}
+
+	// First check if the branch has an upstream
+	upstreamOutput, err := m.gitOutput(ctx, m.repoRoot, nil, "rev-parse", "--abbrev-ref", fmt.Sprintf("%s@{upstream}", branch))
+	if err != nil {
+		// No upstream configured, consider all commits as unpushed
+		countOutput, countErr := m.gitOutput(ctx, m.repoRoot, nil, "rev-list", "--count", branch)
+		if countErr != nil {
+			return false, 0, fmt.Errorf("failed to count commits: %w", countErr)
+		}
+		count := 0
+		if _, err := fmt.Sscanf(strings.TrimSpace(countOutput), "%d", &count); err != nil {
+			return false, 0, fmt.Errorf("failed to parse commit count: %w", err)
+		}
+		return count > 0, count, nil
+	}
+
+	upstream := strings.TrimSpace(upstreamOutput)
+
+	// Count commits ahead of upstream
+	output, err := m.gitOutput(ctx, m.repoRoot, nil, "rev-list", "--count", fmt.Sprintf("%s..%s", upstream, branch))
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to check unpushed commits: %w", err)
+	}
+
+	count := 0
+	if _, err := fmt.Sscanf(strings.TrimSpace(output), "%d", &count); err != nil {
+		return false, 0, fmt.Errorf("failed to parse commit count: %w", err)
+	}
+
+	return count > 0, count, nil
+}
@@ -0,0 +1,152 @@
+// Package worktreetest provides a scripted worktree.Runner, so code using a
+// worktree.Manager can be tested without a repository. It is a test helper and
+// not covered by the worktree package's compatibility promise.
+package worktreetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrExit is returned by Runner for scripted failures, standing in for
+// git exiting with a non-zero status
+var ErrExit = errors.New("exit status 1")
+
+// Call records one invocation made through a Runner
+type Call struct {
+	Dir  string
+	Env  []string
+	Args []string
+}
+
+// Response is the scripted result of git invocations starting with Args
+type Response struct {
+	Args   []string
+	Stdout string
+	Stderr string
+	Err    error
+	once   bool
+	used   bool
+	hang   bool
+}
+
+// Return sets the stdout of a successful invocation
+func (r *Response) Return(stdout string) *Response {
+	r.Stdout = stdout
+	return r
+}
+
+// Fail makes the invocation fail with stderr as git's error output
+func (r *Response) Fail(stderr string) *Response {
+	r.Stderr = stderr
+	r.Err = ErrExit
+	return r
+}
+
+// Hang makes the invocation block until its context ends, simulating a git
+// process that never finishes
+func (r *Response) Hang() *Response {
+	r.hang = true
+	return r
+}
+
+// Once limits the response to a single invocation, so that later calls with
+// the same arguments fall through to the next matching response
+func (r *Response) Once() *Response {
+	r.once = true
+	return r
+}
+
+// Runner is a scripted worktree.Runner for tests. Responses are matched in the order
+// they were registered against the leading arguments of each invocation, and
+// unexpected invocations fail.
+type Runner struct {
+	mu        sync.Mutex
+	responses []*Response
+	calls     []Call
+}
+
+// NewRunner creates a Runner without any scripted responses
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// On registers a response for invocations whose arguments start with args.
+// The response succeeds with empty output unless configured otherwise.
+func (f *Runner) On(args ...string) *Response {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	response := &Response{Args: args}
+	f.responses = append(f.responses, response)
+	return response
+}
+
+// Run returns the first matching scripted response and records the call
+func (f *Runner) Run(ctx context.Context, dir string, env []string, args ...string) (string, string, error) {
+	response, err := f.match(dir, env, args)
+	if err != nil {
+		return "", "", err
+	}
+
+	if response.hang {
+		<-ctx.Done()
+	}
+	if err := ctx.Err(); err != nil {
+		return "", "", err
+	}
+	return response.Stdout, response.Stderr, response.Err
+}
+
+// match records the call and returns the first matching scripted response
+func (f *Runner) match(dir string, env []string, args []string) (*Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{Dir: dir, Env: env, Args: args})
+
+	for _, response := range f.responses {
+		if response.used || !hasPrefix(args, response.Args) {
+			continue
+		}
+		if response.once {
+			response.used = true
+		}
+		return response, nil
+	}
+
+	return nil, fmt.Errorf("unexpected git invocation: git %s", strings.Join(args, " "))
+}
+
+// Calls returns the invocations made so far
+func (f *Runner) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Called reports whether an invocation starting with args was made
+func (f *Runner) Called(args ...string) bool {
+	for _, call := range f.Calls() {
+		if hasPrefix(call.Args, args) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPrefix reports whether args starts with prefix
+func hasPrefix(args, prefix []string) bool {
+	if len(prefix) > len(args) {
+		return false
+	}
+	for i := range prefix {
+		if args[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package worktreetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yagi2/yosegi/pkg/worktree"
+)
+
+var _ worktree.Runner = (*Runner)(nil)
+
+func TestRunnerMatching(t *testing.T) {
+	runner := NewRunner()
+	runner.On("worktree", "list").Return("listed")
+	runner.On("worktree").Return("other worktree command")
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{args: []string{"worktree", "list", "--porcelain"}, expected: "listed"},
+		{args: []string{"worktree", "add", "/tmp/x"}, expected: "other worktree command"},
+	}
+
+	for _, tt := range tests {
+		stdout, _, err := runner.Run(context.Background(), "/repo", nil, tt.args...)
+		if err != nil {
+			t.Fatalf("Run(%v) failed: %v", tt.args, err)
+		}
+		if stdout != tt.expected {
+			t.Errorf("Run(%v) = %q, want %q", tt.args, stdout, tt.expected)
+		}
+	}
+
+	if _, _, err := runner.Run(context.Background(), "/repo", nil, "status"); err == nil || !strings.Contains(err.Error(), "unexpected git invocation: git status") {
+		t.Errorf("Expected unexpected invocation error, got %v", err)
+	}
+}
+
+func TestRunnerOnce(t *testing.T) {
+	runner := NewRunner()
+	runner.On("rev-parse").Fail("fatal: bad revision").Once()
+	runner.On("rev-parse").Return("abc")
+
+	if _, stderr, err := runner.Run(context.Background(), "", nil, "rev-parse", "HEAD"); err != ErrExit || stderr != "fatal: bad revision" {
+		t.Errorf("Expected scripted failure first, got %q, %v", stderr, err)
+	}
+	if stdout, _, err := runner.Run(context.Background(), "", nil, "rev-parse", "HEAD"); err != nil || stdout != "abc" {
+		t.Errorf("Expected fallthrough to second response, got %q, %v", stdout, err)
+	}
+	if stdout, _, _ := runner.Run(context.Background(), "", nil, "rev-parse", "HEAD"); stdout != "abc" {
+		t.Errorf("Expected repeated response, got %q", stdout)
+	}
+}
+
+func TestRunnerRecordsCalls(t *testing.T) {
+	runner := NewRunner()
+	runner.On("add")
+
+	_, _, _ = runner.Run(context.Background(), "/work", []string{"GIT_INDEX_FILE=/tmp/index"}, "add", "--all")
+	_, _, _ = runner.Run(context.Background(), "/repo", nil, "unknown")
+
+	calls := runner.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 calls, got %d", len(calls))
+	}
+	if calls[0].Dir != "/work" || len(calls[0].Env) != 1 || strings.Join(calls[0].Args, " ") != "add --all" {
+		t.Errorf("Unexpected first call: %+v", calls[0])
+	}
+	if !runner.Called("unknown") {
+		t.Error("Unexpected invocations should also be recorded")
+	}
+	if runner.Called("add", "--all", "extra") {
+		t.Error("Called should not match longer argument lists")
+	}
+}